@@ -10,9 +10,9 @@
 
 NOTES_FILE="${VNOTE_FILE:-$HOME/voice-notes.txt}"
 
-text=$(voxclip --language en --no-progress --duration "${VNOTE_DURATION:-15s}" 2>/dev/null) || {
+# --output-to file: appends "<date> <time>  <transcript>" and skips blank notes.
+voxclip --language en --no-progress --duration "${VNOTE_DURATION:-15s}" \
+  --output-to "file:$NOTES_FILE" 2>/dev/null || {
   echo "vnote: recording failed; is voxclip installed?" >&2
   exit 1
 }
-
-[ -n "$text" ] && printf '%s  %s\n' "$(date '+%Y-%m-%d %H:%M')" "$text" >> "$NOTES_FILE"
@@ -3,8 +3,8 @@
 #
 # First invocation: starts voxclip recording, blocks until SIGUSR1 is received.
 # Second invocation: sends SIGUSR1 to the running instance and exits.
-# After recording stops, the first instance transcribes, copies to clipboard,
-# and the script simulates a paste keystroke.
+# After recording stops, the first instance transcribes and types the
+# transcript into the focused window.
 #
 # Usage:
 #   Bind this script to a global hotkey (see examples/README.md).
@@ -25,22 +25,10 @@ fi
 
 # Start recording; blocks until SIGUSR1 stops it.
 # --duration 5m acts as a safety timeout in case the stop hotkey is missed.
-voxclip --pid-file "$PID_FILE" --duration 5m --language en --no-progress 2>/dev/null || {
+# --output-to type types the transcript into the focused window, leaving the
+# clipboard untouched. --notify shows recording state and errors, since
+# stderr is discarded.
+voxclip --pid-file "$PID_FILE" --duration 5m --language en --no-progress --output-to type --notify 2>/dev/null || {
   echo "vpaste-toggle: recording failed; is voxclip installed?" >&2
   exit 1
 }
-
-# Simulate paste keystroke.
-case "$(uname -s)" in
-  Darwin)
-    osascript -e 'delay 0.2' -e 'tell application "System Events" to keystroke "v" using command down'
-    ;;
-  Linux)
-    sleep 0.2
-    if command -v xdotool >/dev/null 2>&1; then
-      xdotool key ctrl+shift+v
-    elif command -v wtype >/dev/null 2>&1; then
-      wtype -M ctrl -M shift -k v
-    fi
-    ;;
-esac
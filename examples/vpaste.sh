@@ -15,7 +15,8 @@ set -e
 
 export PATH="$HOME/.local/bin:/opt/homebrew/bin:/usr/local/bin:$PATH"
 
-voxclip --language en --duration "${VPROMPT_DURATION:-8s}" --no-progress 2>/dev/null || {
+# --restore-clipboard puts the previous clipboard text back once the paste is done.
+voxclip --language en --duration "${VPROMPT_DURATION:-8s}" --no-progress --restore-clipboard 2s 2>/dev/null || {
   echo "vpaste: recording failed; is voxclip installed?" >&2
   exit 1
 }
@@ -0,0 +1,11 @@
+// Package applescript builds AppleScript snippets for osascript.
+package applescript
+
+import "strings"
+
+// Quote returns s as an AppleScript string literal.
+func Quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
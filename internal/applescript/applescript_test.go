@@ -0,0 +1,15 @@
+package applescript
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuote(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `"hello"`, Quote("hello"))
+	require.Equal(t, `"say \"hi\" \\ bye"`, Quote(`say "hi" \ bye`))
+	require.Equal(t, "\"two\nlines\"", Quote("two\nlines"))
+}
@@ -0,0 +1,204 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// analysisBufferSize is roughly how much of the data chunk is read and
+// decoded at a time.
+const analysisBufferSize = 64 * 1024
+
+// FrameLevel is the level of one analysis frame of a recording.
+type FrameLevel struct {
+	// Start is the position of the frame's first sample in the recording.
+	Start time.Duration
+	// Samples counts the samples of all channels in the frame.
+	Samples int64
+	// RMS and Peak are amplitudes relative to full scale, from 0 to 1.
+	RMS  float64
+	Peak float64
+}
+
+// RMSdBFS returns the frame's RMS level in dBFS.
+func (l FrameLevel) RMSdBFS() float64 {
+	return amplitudeToDBFS(l.RMS)
+}
+
+// PeakdBFS returns the frame's peak level in dBFS.
+func (l FrameLevel) PeakdBFS() float64 {
+	return amplitudeToDBFS(l.Peak)
+}
+
+// AnalyzeWAV measures the WAV at path in analysis frames of the given length
+// and calls onFrame, if not nil, with the level of each; the last frame may
+// be shorter. It returns the level of the whole recording. The data chunk
+// is streamed through a fixed buffer, so memory use does not grow with the
+// length of the recording. An error returned by onFrame stops the analysis
+// and is returned as is.
+func AnalyzeWAV(path string, frame time.Duration, onFrame func(FrameLevel) error) (SilenceMetrics, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return SilenceMetrics{}, fmt.Errorf("open wav: %w", err)
+	}
+	defer f.Close()
+
+	info, err := readWAVInfo(f)
+	if err != nil {
+		return SilenceMetrics{}, err
+	}
+	if info.BlockAlign == 0 {
+		return SilenceMetrics{}, ErrInvalidWAV
+	}
+	return analyzeFrames(f, info, max(info.durationToFrames(frame), 1), onFrame)
+}
+
+// analyzeFrames measures the data chunk of the WAV described by info in
+// frames of frameLen sample frames.
+func analyzeFrames(r io.ReadSeeker, info wavInfo, frameLen int64, onFrame func(FrameLevel) error) (SilenceMetrics, error) {
+	if _, err := r.Seek(info.DataOffset, io.SeekStart); err != nil {
+		return SilenceMetrics{}, fmt.Errorf("seek wav data offset: %w", err)
+	}
+
+	frameBytes := int(frameLen) * int(info.BlockAlign)
+	buf := make([]byte, max(analysisBufferSize/frameBytes, 1)*frameBytes)
+	data := io.LimitReader(r, int64(info.DataSize))
+
+	var total, frame levelAccumulator
+	var position int64
+	flush := func() error {
+		if frame.samples == 0 {
+			return nil
+		}
+		total.merge(frame)
+		level := frame.level()
+		level.Start = info.framesToDuration(position)
+		position += frame.samples / int64(max(info.Channels, 1))
+		frame = levelAccumulator{}
+		if onFrame == nil {
+			return nil
+		}
+		return onFrame(level)
+	}
+
+	for {
+		n, readErr := io.ReadFull(data, buf)
+		if readErr != nil && !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return SilenceMetrics{}, fmt.Errorf("read wav data: %w", readErr)
+		}
+		for off := 0; off < n; off += frameBytes {
+			if err := frame.add(buf[off:min(off+frameBytes, n)], info.AudioFormat, info.BitsPerSample); err != nil {
+				return SilenceMetrics{}, err
+			}
+			if off+frameBytes <= n {
+				if err := flush(); err != nil {
+					return SilenceMetrics{}, err
+				}
+			}
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	if err := flush(); err != nil {
+		return SilenceMetrics{}, err
+	}
+	return total.metrics(), nil
+}
+
+// levelAccumulator sums up the level of decoded samples.
+type levelAccumulator struct {
+	peak       float64
+	sumSquares float64
+	samples    int64
+}
+
+func (a *levelAccumulator) merge(other levelAccumulator) {
+	a.peak = max(a.peak, other.peak)
+	a.sumSquares += other.sumSquares
+	a.samples += other.samples
+}
+
+func (a levelAccumulator) level() FrameLevel {
+	level := FrameLevel{Samples: a.samples, Peak: a.peak}
+	if a.samples > 0 {
+		level.RMS = math.Sqrt(a.sumSquares / float64(a.samples))
+	}
+	return level
+}
+
+func (a levelAccumulator) metrics() SilenceMetrics {
+	if a.samples == 0 {
+		return SilenceMetrics{RMSdBFS: math.Inf(-1), PeakdBFS: math.Inf(-1)}
+	}
+	level := a.level()
+	return SilenceMetrics{
+		RMSdBFS:  amplitudeToDBFS(level.RMS),
+		PeakdBFS: amplitudeToDBFS(level.Peak),
+		Samples:  a.samples,
+	}
+}
+
+// add decodes the complete samples in data. Each format has its own loop so
+// the format is not looked at again for every sample.
+func (a *levelAccumulator) add(data []byte, audioFormat, bitsPerSample uint16) error {
+	peak, sumSquares := a.peak, a.sumSquares
+	measure := func(v float64) {
+		sumSquares += v * v
+		if v < 0 {
+			v = -v
+		}
+		if v > peak {
+			peak = v
+		}
+	}
+
+	var size int
+	switch {
+	case audioFormat == 1 && bitsPerSample == 8:
+		size = 1
+		for _, b := range data {
+			measure((float64(b) - 128.0) / 128.0)
+		}
+	case audioFormat == 1 && bitsPerSample == 16:
+		size = 2
+		for i := 0; i+2 <= len(data); i += 2 {
+			measure(float64(int16(binary.LittleEndian.Uint16(data[i:]))) / 32768.0)
+		}
+	case audioFormat == 1 && bitsPerSample == 24:
+		size = 3
+		for i := 0; i+3 <= len(data); i += 3 {
+			v := int32(data[i]) | int32(data[i+1])<<8 | int32(data[i+2])<<16
+			if v&0x800000 != 0 {
+				v |= ^0xFFFFFF
+			}
+			measure(float64(v) / 8388608.0)
+		}
+	case audioFormat == 1 && bitsPerSample == 32:
+		size = 4
+		for i := 0; i+4 <= len(data); i += 4 {
+			measure(float64(int32(binary.LittleEndian.Uint32(data[i:]))) / 2147483648.0)
+		}
+	case audioFormat == 3 && bitsPerSample == 32:
+		size = 4
+		for i := 0; i+4 <= len(data); i += 4 {
+			measure(float64(math.Float32frombits(binary.LittleEndian.Uint32(data[i:]))))
+		}
+	case audioFormat == 3 && bitsPerSample == 64:
+		size = 8
+		for i := 0; i+8 <= len(data); i += 8 {
+			measure(math.Float64frombits(binary.LittleEndian.Uint64(data[i:])))
+		}
+	default:
+		return ErrUnsupportedWAV
+	}
+
+	a.peak, a.sumSquares = peak, sumSquares
+	a.samples += int64(len(data) / size)
+	return nil
+}
@@ -0,0 +1,225 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeWAVReportsEveryFrame(t *testing.T) {
+	t.Parallel()
+
+	// 25ms of silence, 25ms at half scale and a 5ms tail at quarter scale,
+	// in 25ms frames at 8 kHz.
+	samples := make([]int16, 0, 440)
+	for range 200 {
+		samples = append(samples, 0)
+	}
+	for i := range 200 {
+		samples = append(samples, int16(16384*(1-2*(i%2))))
+	}
+	for range 40 {
+		samples = append(samples, 8192)
+	}
+	path := filepath.Join(t.TempDir(), "levels.wav")
+	require.NoError(t, os.WriteFile(path, makePCM16WAV(samples, 8000, 1), 0o644))
+
+	var frames []FrameLevel
+	metrics, err := AnalyzeWAV(path, 25*time.Millisecond, func(level FrameLevel) error {
+		frames = append(frames, level)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, frames, 3)
+	require.Equal(t, FrameLevel{Start: 0, Samples: 200}, frames[0])
+	require.Equal(t, FrameLevel{Start: 25 * time.Millisecond, Samples: 200, RMS: 0.5, Peak: 0.5}, frames[1])
+	require.Equal(t, FrameLevel{Start: 50 * time.Millisecond, Samples: 40, RMS: 0.25, Peak: 0.25}, frames[2])
+	require.InDelta(t, -6.02, frames[1].RMSdBFS(), 0.01)
+	require.True(t, math.IsInf(frames[0].PeakdBFS(), -1))
+
+	_, pcm, err := ReadWAV(path)
+	require.NoError(t, err)
+	whole, err := MeasurePCM(Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}, pcm)
+	require.NoError(t, err)
+	require.Equal(t, whole.Samples, metrics.Samples)
+	require.InDelta(t, whole.RMSdBFS, metrics.RMSdBFS, 1e-9)
+	require.Equal(t, whole.PeakdBFS, metrics.PeakdBFS)
+}
+
+func TestAnalyzeWAVCountsStereoFramesOnce(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "stereo.wav")
+	require.NoError(t, os.WriteFile(path, makePCM16WAV(make([]int16, 2*160), 16000, 2), 0o644))
+
+	var starts []time.Duration
+	_, err := AnalyzeWAV(path, 5*time.Millisecond, func(level FrameLevel) error {
+		require.EqualValues(t, 160, level.Samples, "80 sample frames of two channels")
+		starts = append(starts, level.Start)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []time.Duration{0, 5 * time.Millisecond}, starts)
+}
+
+func TestAnalyzeWAVStopsOnCallbackError(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "long.wav")
+	require.NoError(t, os.WriteFile(path, makePCM16WAV(make([]int16, 16000), 16000, 1), 0o644))
+
+	errFound := errors.New("found")
+	calls := 0
+	_, err := AnalyzeWAV(path, 10*time.Millisecond, func(FrameLevel) error {
+		calls++
+		if calls == 3 {
+			return errFound
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errFound)
+	require.Equal(t, 3, calls)
+}
+
+func TestAnalyzeWAVReadsDataSizePlaceholderToEndOfFile(t *testing.T) {
+	t.Parallel()
+
+	wav := makePCM16WAV([]int16{16384, -16384, 16384, -16384}, 16000, 1)
+	binary.LittleEndian.PutUint32(wav[40:], 0xffffffff)
+	path := filepath.Join(t.TempDir(), "killed.wav")
+	require.NoError(t, os.WriteFile(path, wav, 0o644))
+
+	metrics, err := AnalyzeWAV(path, time.Second, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, metrics.Samples)
+	require.InDelta(t, -6.02, metrics.RMSdBFS, 0.01)
+}
+
+func TestAnalyzeWAVRejectsInvalidFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "invalid.wav")
+	require.NoError(t, os.WriteFile(path, []byte("not a wav"), 0o644))
+	_, err := AnalyzeWAV(path, time.Second, nil)
+	require.ErrorIs(t, err, ErrInvalidWAV)
+}
+
+// benchmarkWAV writes a recording of the given length with speech-like
+// level changes, as voxclip records it.
+func benchmarkWAV(b *testing.B, length time.Duration) (string, int64) {
+	b.Helper()
+
+	format := Format{SampleRate: 16000, Channels: 1, BitsPerSample: 16}
+	path := filepath.Join(b.TempDir(), "long.wav")
+	w, err := CreateWAV(path, format)
+	if err != nil {
+		b.Fatal(err)
+	}
+	second := make([]byte, format.Bytes(time.Second))
+	for i := 0; i < len(second); i += 2 {
+		sample := int16(8000 * math.Sin(float64(i)/20) * math.Sin(float64(i)/9000))
+		binary.LittleEndian.PutUint16(second[i:], uint16(sample))
+	}
+	for range int(length / time.Second) {
+		if _, err := w.Write(second); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		b.Fatal(err)
+	}
+	return path, int64(format.Bytes(length))
+}
+
+func BenchmarkAnalyzeWAV(b *testing.B) {
+	for _, length := range []time.Duration{time.Minute, 10 * time.Minute} {
+		path, size := benchmarkWAV(b, length)
+
+		b.Run(fmt.Sprintf("streaming/%s", length), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(size)
+			for range b.N {
+				if _, err := AnalyzeWAV(path, defaultAnalysisFrame, func(FrameLevel) error { return nil }); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		// The analysis before it streamed: the whole data chunk in memory,
+		// decoded one sample at a time.
+		b.Run(fmt.Sprintf("load-whole/%s", length), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(size)
+			for range b.N {
+				if err := analyzeLoadingWholeFile(path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMeasurePCM(b *testing.B) {
+	format := Format{SampleRate: 16000, Channels: 1, BitsPerSample: 16}
+	pcm := make([]byte, format.Bytes(100*time.Millisecond))
+	for i := 0; i < len(pcm); i += 2 {
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(i)))
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(pcm)))
+	for range b.N {
+		if _, err := MeasurePCM(format, pcm); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func analyzeLoadingWholeFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := readWAVInfo(f)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(info.DataOffset, io.SeekStart); err != nil {
+		return err
+	}
+	data := make([]byte, info.DataSize)
+	if _, err := io.ReadFull(f, data); err != nil {
+		return err
+	}
+
+	var peak, sumSquares float64
+	size := int(info.BitsPerSample / 8)
+	for i := 0; i+size <= len(data); i += size {
+		value, err := decodeSampleOneByOne(data[i:i+size], info.AudioFormat, info.BitsPerSample)
+		if err != nil {
+			return err
+		}
+		peak = max(peak, math.Abs(value))
+		sumSquares += value * value
+	}
+	_ = amplitudeToDBFS(peak) + amplitudeToDBFS(math.Sqrt(sumSquares/float64(len(data)/size)))
+	return nil
+}
+
+func decodeSampleOneByOne(sample []byte, audioFormat, bitsPerSample uint16) (float64, error) {
+	if audioFormat != 1 || bitsPerSample != 16 {
+		return 0, ErrUnsupportedWAV
+	}
+	return float64(int16(binary.LittleEndian.Uint16(sample))) / 32768.0, nil
+}
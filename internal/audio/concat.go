@@ -0,0 +1,86 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ConcatWAV writes the audio of the integer PCM WAV files srcs, in order, to
+// a new WAV file at dst. All sources must share one format. A data size left
+// as a placeholder by a recorder that was stopped is read up to the end of
+// the file, and trailing partial sample frames are dropped.
+func ConcatWAV(dst string, srcs ...string) (err error) {
+	if len(srcs) == 0 {
+		return errors.New("concatenate wav: no input files")
+	}
+
+	var w *WAVWriter
+	var format Format
+	defer func() {
+		if w == nil {
+			return
+		}
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(dst)
+		}
+	}()
+
+	for _, src := range srcs {
+		f, err := os.Open(src)
+		if err != nil {
+			return fmt.Errorf("open wav: %w", err)
+		}
+		srcFormat, data, err := pcmData(f)
+		if err != nil {
+			_ = f.Close()
+			return fmt.Errorf("%s: %w", src, err)
+		}
+
+		if w == nil {
+			format = srcFormat
+			if w, err = CreateWAV(dst, format); err != nil {
+				_ = f.Close()
+				return err
+			}
+		} else if srcFormat != format {
+			_ = f.Close()
+			return fmt.Errorf("%s: format %+v differs from %+v: %w", src, srcFormat, format, ErrUnsupportedWAV)
+		}
+
+		_, err = io.Copy(w, data)
+		_ = f.Close()
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", src, err)
+		}
+	}
+	return nil
+}
+
+// pcmData returns the format of the WAV in f and a reader over its complete
+// sample frames.
+func pcmData(f *os.File) (Format, io.Reader, error) {
+	info, err := readWAVInfo(f)
+	if err != nil {
+		return Format{}, nil, err
+	}
+	if info.AudioFormat != 1 || info.BlockAlign == 0 {
+		return Format{}, nil, ErrUnsupportedWAV
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return Format{}, nil, fmt.Errorf("stat wav: %w", err)
+	}
+
+	size := int64(info.DataSize)
+	if available := stat.Size() - info.DataOffset; size == 0 || size > available {
+		size = available
+	}
+	block := int64(info.BlockAlign)
+	format := Format{SampleRate: int(info.SampleRate), Channels: int(info.Channels), BitsPerSample: int(info.BitsPerSample)}
+	return format, io.NewSectionReader(f, info.DataOffset, size/block*block), nil
+}
@@ -0,0 +1,54 @@
+package audio
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcatWAVJoinsSegments(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.wav")
+	second := filepath.Join(dir, "second.wav")
+	require.NoError(t, os.WriteFile(first, makePCM16WAV([]int16{1, 2, 3}, 16000, 1), 0o644))
+
+	// A segment whose recorder was stopped before it wrote the data size,
+	// with half a sample frame at the end.
+	wav := makePCM16WAV([]int16{4, 5}, 16000, 1)
+	binary.LittleEndian.PutUint32(wav[40:], 0)
+	require.NoError(t, os.WriteFile(second, append(wav, 0x7f), 0o644))
+
+	joined := filepath.Join(dir, "joined.wav")
+	require.NoError(t, ConcatWAV(joined, first, second))
+	require.Equal(t, []int16{1, 2, 3, 4, 5}, readPCM16Samples(t, joined))
+
+	raw, err := os.ReadFile(joined)
+	require.NoError(t, err)
+	require.Equal(t, makePCM16WAV([]int16{1, 2, 3, 4, 5}, 16000, 1), raw)
+}
+
+func TestConcatWAVRejectsMixedFormats(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	mono := filepath.Join(dir, "mono.wav")
+	stereo := filepath.Join(dir, "stereo.wav")
+	require.NoError(t, os.WriteFile(mono, makePCM16WAV([]int16{1, 2}, 16000, 1), 0o644))
+	require.NoError(t, os.WriteFile(stereo, makePCM16WAV([]int16{1, 2}, 16000, 2), 0o644))
+
+	joined := filepath.Join(dir, "joined.wav")
+	err := ConcatWAV(joined, mono, stereo)
+	require.ErrorIs(t, err, ErrUnsupportedWAV)
+	require.NoFileExists(t, joined)
+}
+
+func TestConcatWAVRequiresInput(t *testing.T) {
+	t.Parallel()
+
+	require.Error(t, ConcatWAV(filepath.Join(t.TempDir(), "joined.wav")))
+}
@@ -0,0 +1,113 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// RepairWAV rewrites the RIFF and data chunk sizes of the WAV file at path
+// from its actual length. A recorder killed before it finished the file
+// leaves them at zero, at a placeholder or at the size of an earlier
+// flush. Without a valid data size, the audio is taken to run to the end of
+// the file, and a trailing partial sample frame is cut off. It reports
+// whether the file was changed.
+func RepairWAV(path string) (bool, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return false, fmt.Errorf("open wav: %w", err)
+	}
+	repaired, err := repairWAV(f)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("close wav: %w", closeErr)
+	}
+	return repaired, err
+}
+
+func repairWAV(f *os.File) (bool, error) {
+	info, err := readWAVInfo(f)
+	if err != nil {
+		return false, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return false, fmt.Errorf("stat wav: %w", err)
+	}
+	size := stat.Size()
+
+	dataSize := int64(info.DataSize)
+	end := size
+	if available := size - info.DataOffset; !validDataSize(f, info.DataOffset, dataSize, size) {
+		dataSize = available
+		if info.BlockAlign > 0 {
+			dataSize -= dataSize % int64(info.BlockAlign)
+		}
+		end = info.DataOffset + dataSize
+		// Keep an existing pad byte after odd-sized audio.
+		if dataSize%2 != 0 && end < size {
+			end++
+		}
+	}
+	if end-8 > math.MaxUint32 {
+		return false, fmt.Errorf("wav data exceeds 4 GiB")
+	}
+
+	header := make([]byte, 4)
+	if _, err := f.ReadAt(header, 4); err != nil {
+		return false, fmt.Errorf("read wav header: %w", err)
+	}
+	riffSize := binary.LittleEndian.Uint32(header)
+
+	repaired := false
+	if end < size {
+		if err := f.Truncate(end); err != nil {
+			return false, fmt.Errorf("truncate wav: %w", err)
+		}
+		repaired = true
+	}
+	fields := []struct {
+		offset int64
+		old    uint32
+		value  uint32
+	}{
+		{4, riffSize, uint32(end - 8)},
+		{info.DataOffset - 4, info.DataSize, uint32(dataSize)},
+	}
+	for _, field := range fields {
+		if field.old == field.value {
+			continue
+		}
+		if _, err := f.WriteAt(binary.LittleEndian.AppendUint32(nil, field.value), field.offset); err != nil {
+			return false, fmt.Errorf("write wav header: %w", err)
+		}
+		repaired = true
+	}
+	return repaired, nil
+}
+
+// validDataSize reports whether a data chunk of dataSize bytes at offset
+// fits into the file and is followed by nothing but well-formed chunks, such
+// as the LIST chunk some recorders append when they finish the file.
+func validDataSize(r io.ReaderAt, offset, dataSize, fileSize int64) bool {
+	if dataSize == 0 || offset+dataSize > fileSize {
+		return false
+	}
+	pos := offset + dataSize + dataSize%2
+	chunkHeader := make([]byte, 8)
+	for pos < fileSize {
+		if _, err := r.ReadAt(chunkHeader, pos); err != nil {
+			return false
+		}
+		for _, c := range chunkHeader[:4] {
+			if c < ' ' || c > '~' {
+				return false
+			}
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(chunkHeader[4:]))
+		pos += 8 + chunkSize + chunkSize%2
+	}
+	// A missing pad byte after the last chunk is common enough to accept.
+	return pos <= fileSize+1
+}
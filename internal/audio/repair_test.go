@@ -0,0 +1,113 @@
+package audio
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeRepairTestWAV(t *testing.T, wav []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "recording.wav")
+	require.NoError(t, os.WriteFile(path, wav, 0o644))
+	return path
+}
+
+func TestRepairWAVFillsInSizesOfUnfinishedFile(t *testing.T) {
+	t.Parallel()
+
+	samples := []int16{100, -100, 200, -200}
+	want := makePCM16WAV(samples, 16000, 1)
+	wav := append([]byte(nil), want...)
+	binary.LittleEndian.PutUint32(wav[4:], 0)
+	binary.LittleEndian.PutUint32(wav[40:], 0)
+	path := writeRepairTestWAV(t, wav)
+
+	repaired, err := RepairWAV(path)
+	require.NoError(t, err)
+	require.True(t, repaired)
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, want, raw)
+}
+
+func TestRepairWAVCutsOffPartialFrameAfterPlaceholderSize(t *testing.T) {
+	t.Parallel()
+
+	samples := []int16{1, 2, 3, 4}
+	want := makePCM16WAV(samples, 16000, 2)
+	wav := append([]byte(nil), want...)
+	binary.LittleEndian.PutUint32(wav[4:], 0xffffffff)
+	binary.LittleEndian.PutUint32(wav[40:], 0xffffffff)
+	// Half a stereo frame written before the recorder was killed.
+	path := writeRepairTestWAV(t, append(wav, 5, 0))
+
+	repaired, err := RepairWAV(path)
+	require.NoError(t, err)
+	require.True(t, repaired)
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, want, raw)
+}
+
+func TestRepairWAVExtendsStaleDataSize(t *testing.T) {
+	t.Parallel()
+
+	samples := []int16{1, 2, 3, 4, 5, 6, 7, 8}
+	want := makePCM16WAV(samples, 16000, 1)
+	wav := append([]byte(nil), want...)
+	// Sizes from the last flush before the recorder was killed.
+	binary.LittleEndian.PutUint32(wav[4:], 36+4)
+	binary.LittleEndian.PutUint32(wav[40:], 4)
+	path := writeRepairTestWAV(t, wav)
+
+	repaired, err := RepairWAV(path)
+	require.NoError(t, err)
+	require.True(t, repaired)
+
+	format, pcm, err := ReadWAV(path)
+	require.NoError(t, err)
+	require.Equal(t, len(samples)*2, len(pcm))
+	require.Equal(t, Format{SampleRate: 16000, Channels: 1, BitsPerSample: 16}, format)
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, want, raw)
+}
+
+func TestRepairWAVLeavesValidFileAlone(t *testing.T) {
+	t.Parallel()
+
+	wav := makePCM16WAV([]int16{1, 2, 3}, 16000, 1)
+	// A LIST chunk after the audio, as written by some recorders.
+	wav = append(wav, "LIST"...)
+	wav = binary.LittleEndian.AppendUint32(wav, 4)
+	wav = append(wav, "INFO"...)
+	binary.LittleEndian.PutUint32(wav[4:], uint32(len(wav)-8))
+	path := writeRepairTestWAV(t, wav)
+
+	repaired, err := RepairWAV(path)
+	require.NoError(t, err)
+	require.False(t, repaired)
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, wav, raw)
+}
+
+func TestRepairWAVRejectsInvalidFile(t *testing.T) {
+	t.Parallel()
+
+	path := writeRepairTestWAV(t, []byte("not a wav file"))
+	_, err := RepairWAV(path)
+	require.ErrorIs(t, err, ErrInvalidWAV)
+
+	_, err = RepairWAV(filepath.Join(t.TempDir(), "missing.wav"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,214 @@
+package audio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	DefaultChunkDuration = 2 * time.Minute
+	DefaultChunkOverlap  = time.Second
+
+	defaultAnalysisFrame = 30 * time.Millisecond
+	// pauseWindow is the span over which frame energy is averaged when
+	// looking for a cut point, so a cut lands in a pause rather than in a
+	// single quiet frame inside a word.
+	pauseWindow = 300 * time.Millisecond
+)
+
+// SplitOptions controls how SplitWAV cuts a recording.
+type SplitOptions struct {
+	// ChunkDuration is the target length of each chunk before overlap.
+	ChunkDuration time.Duration
+	// SearchWindow is how far before the target cut point SplitWAV looks
+	// for the quietest pause. Defaults to a quarter of ChunkDuration.
+	SearchWindow time.Duration
+	// Overlap is extra audio added on both sides of every cut so words at
+	// the boundary are heard completely by at least one chunk.
+	Overlap time.Duration
+}
+
+// Chunk is one piece of a split recording.
+//
+// Start is the position of the chunk's first sample in the source. KeepStart
+// and KeepEnd delimit the part of the source this chunk is responsible for,
+// i.e. the span between the cuts, without the overlap.
+type Chunk struct {
+	Path      string
+	Start     time.Duration
+	KeepStart time.Duration
+	KeepEnd   time.Duration
+}
+
+// WAVDuration returns the playback length of a WAV file.
+func WAVDuration(path string) (time.Duration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("open wav: %w", err)
+	}
+	defer f.Close()
+
+	info, err := readWAVInfo(f)
+	if err != nil {
+		return 0, err
+	}
+	return info.framesToDuration(info.frames()), nil
+}
+
+// SplitWAV cuts the WAV at path into chunks of roughly opts.ChunkDuration,
+// placing each cut in the quietest pause of the search window before the
+// target length. Chunk files are written to dir in the source format. A
+// recording short enough for a single chunk is returned as-is without
+// copying.
+func SplitWAV(path, dir string, opts SplitOptions) ([]Chunk, error) {
+	if opts.ChunkDuration <= 0 {
+		opts.ChunkDuration = DefaultChunkDuration
+	}
+	if opts.SearchWindow <= 0 || opts.SearchWindow > opts.ChunkDuration/2 {
+		opts.SearchWindow = opts.ChunkDuration / 4
+	}
+	if opts.Overlap < 0 {
+		return nil, fmt.Errorf("chunk overlap must not be negative, got %s", opts.Overlap)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open wav: %w", err)
+	}
+	defer f.Close()
+
+	info, err := readWAVInfo(f)
+	if err != nil {
+		return nil, err
+	}
+	if info.BlockAlign == 0 || info.SampleRate == 0 {
+		return nil, ErrInvalidWAV
+	}
+	// Recorders that were killed mid-write can leave a data size larger
+	// than what is actually on disk.
+	if stat, err := f.Stat(); err == nil && info.DataOffset+int64(info.DataSize) > stat.Size() {
+		info.DataSize = uint32(stat.Size() - info.DataOffset)
+	}
+
+	total := info.frames()
+	chunkFrames := info.durationToFrames(opts.ChunkDuration)
+	if chunkFrames <= 0 || total <= chunkFrames+chunkFrames/4 {
+		return []Chunk{{Path: path, KeepEnd: info.framesToDuration(total)}}, nil
+	}
+
+	frameLen := max(info.durationToFrames(defaultAnalysisFrame), 1)
+	energies, err := frameEnergies(f, info, frameLen)
+	if err != nil {
+		return nil, err
+	}
+	smoothed := movingAverage(energies, int(pauseWindow/defaultAnalysisFrame))
+
+	searchFrames := info.durationToFrames(opts.SearchWindow)
+	overlapFrames := info.durationToFrames(opts.Overlap)
+
+	var chunks []Chunk
+	var start int64
+	for start < total {
+		end := total
+		if total-start > chunkFrames+chunkFrames/4 {
+			end = quietestCut(smoothed, frameLen, start+chunkFrames-searchFrames, start+chunkFrames)
+		}
+
+		audioStart := max(start-overlapFrames, 0)
+		audioEnd := min(end+overlapFrames, total)
+
+		chunkPath := filepath.Join(dir, fmt.Sprintf("chunk-%03d.wav", len(chunks)))
+		if err := copyWAVFrames(f, info, chunkPath, audioStart, audioEnd); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, Chunk{
+			Path:      chunkPath,
+			Start:     info.framesToDuration(audioStart),
+			KeepStart: info.framesToDuration(start),
+			KeepEnd:   info.framesToDuration(end),
+		})
+		start = end
+	}
+	return chunks, nil
+}
+
+// frameEnergies returns the mean squared amplitude of every analysis frame.
+func frameEnergies(f *os.File, info wavInfo, frameLen int64) ([]float64, error) {
+	var energies []float64
+	_, err := analyzeFrames(f, info, frameLen, func(level FrameLevel) error {
+		energies = append(energies, level.RMS*level.RMS)
+		return nil
+	})
+	return energies, err
+}
+
+// movingAverage smooths values with a centered window of the given width.
+func movingAverage(values []float64, window int) []float64 {
+	if window <= 1 {
+		return values
+	}
+	prefix := make([]float64, len(values)+1)
+	for i, v := range values {
+		prefix[i+1] = prefix[i] + v
+	}
+
+	half := window / 2
+	out := make([]float64, len(values))
+	for i := range values {
+		lo := max(i-half, 0)
+		hi := min(i+half+1, len(values))
+		out[i] = (prefix[hi] - prefix[lo]) / float64(hi-lo)
+	}
+	return out
+}
+
+// quietestCut returns the sample frame in [from, to) at the center of the
+// quietest analysis frame.
+func quietestCut(energies []float64, frameLen, from, to int64) int64 {
+	first := max(from/frameLen, 0)
+	last := min(to/frameLen, int64(len(energies)))
+	if first >= last {
+		return to
+	}
+
+	best := first
+	bestEnergy := math.Inf(1)
+	for i := first; i < last; i++ {
+		if energies[i] < bestEnergy {
+			best, bestEnergy = i, energies[i]
+		}
+	}
+	return best*frameLen + frameLen/2
+}
+
+func copyWAVFrames(src *os.File, info wavInfo, dst string, from, to int64) error {
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("create chunk: %w", err)
+	}
+
+	size := (to - from) * int64(info.BlockAlign)
+	writer := bufio.NewWriter(out)
+	err = writeWAVHeader(writer, info.fmtChunk, uint32(size))
+	if err == nil {
+		section := io.NewSectionReader(src, info.DataOffset+from*int64(info.BlockAlign), size)
+		if _, copyErr := io.Copy(writer, section); copyErr != nil {
+			err = fmt.Errorf("write chunk: %w", copyErr)
+		}
+	}
+	if err == nil && size%2 != 0 {
+		err = writer.WriteByte(0)
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := out.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("close chunk: %w", closeErr)
+	}
+	return err
+}
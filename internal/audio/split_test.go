@@ -0,0 +1,122 @@
+package audio
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const splitTestRate = 8000
+
+// speechWithPauses returns a mono signal of the given length with silence in
+// each of the pauses, given as [start, end) in seconds.
+func speechWithPauses(length time.Duration, pauses ...[2]float64) []int16 {
+	samples := make([]int16, int(length.Seconds()*splitTestRate))
+	for i := range samples {
+		samples[i] = 8000
+		if i%2 == 1 {
+			samples[i] = -8000
+		}
+	}
+	for _, pause := range pauses {
+		for i := int(pause[0] * splitTestRate); i < int(pause[1]*splitTestRate); i++ {
+			samples[i] = 0
+		}
+	}
+	return samples
+}
+
+func writeSplitTestWAV(t *testing.T, samples []int16) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "long.wav")
+	require.NoError(t, os.WriteFile(path, makePCM16WAV(samples, splitTestRate, 1), 0o644))
+	return path
+}
+
+func readPCM16Samples(t *testing.T, path string) []int16 {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	info, err := readWAVInfo(f)
+	require.NoError(t, err)
+	data := make([]byte, info.DataSize)
+	_, err = f.ReadAt(data, info.DataOffset)
+	require.NoError(t, err)
+
+	samples := make([]int16, len(data)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[2*i:]))
+	}
+	return samples
+}
+
+func TestSplitWAVCutsInPauses(t *testing.T) {
+	t.Parallel()
+
+	samples := speechWithPauses(25*time.Second, [2]float64{9.0, 9.6}, [2]float64{18.0, 18.6})
+	path := writeSplitTestWAV(t, samples)
+
+	chunks, err := SplitWAV(path, t.TempDir(), SplitOptions{ChunkDuration: 10 * time.Second, Overlap: 500 * time.Millisecond})
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+
+	require.Zero(t, chunks[0].Start)
+	require.Zero(t, chunks[0].KeepStart)
+	require.Equal(t, 25*time.Second, chunks[2].KeepEnd)
+	for i, cut := range []time.Duration{chunks[0].KeepEnd, chunks[1].KeepEnd} {
+		pauseStart := []time.Duration{9 * time.Second, 18 * time.Second}[i]
+		require.GreaterOrEqual(t, cut, pauseStart, "cut %d", i)
+		require.Less(t, cut, pauseStart+600*time.Millisecond, "cut %d", i)
+		require.Equal(t, cut, chunks[i+1].KeepStart)
+		require.Equal(t, cut-500*time.Millisecond, chunks[i+1].Start)
+	}
+
+	for _, chunk := range chunks {
+		got := readPCM16Samples(t, chunk.Path)
+		from := int(chunk.Start.Seconds() * splitTestRate)
+		to := min(int((chunk.KeepEnd+500*time.Millisecond).Seconds()*splitTestRate), len(samples))
+		require.Equal(t, samples[from:to], got)
+
+		duration, err := WAVDuration(chunk.Path)
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(len(got))*time.Second/splitTestRate, duration)
+	}
+}
+
+func TestSplitWAVKeepsShortRecordingWhole(t *testing.T) {
+	t.Parallel()
+
+	path := writeSplitTestWAV(t, speechWithPauses(12*time.Second))
+	dir := t.TempDir()
+
+	chunks, err := SplitWAV(path, dir, SplitOptions{ChunkDuration: 10 * time.Second})
+	require.NoError(t, err)
+	require.Equal(t, []Chunk{{Path: path, KeepEnd: 12 * time.Second}}, chunks)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestSplitWAVRejectsInvalidFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "bad.wav")
+	require.NoError(t, os.WriteFile(path, []byte("not a wav"), 0o644))
+
+	_, err := SplitWAV(path, t.TempDir(), SplitOptions{})
+	require.ErrorIs(t, err, ErrInvalidWAV)
+}
+
+func TestMovingAverageIsCentered(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []float64{1, 3, 5}, movingAverage([]float64{1, 3, 5}, 1))
+	require.InDeltaSlice(t, []float64{2, 11.0 / 3, 11.0 / 3, 17.0 / 3, 5}, movingAverage([]float64{1, 3, 7, 1, 9}, 3), 1e-9)
+}
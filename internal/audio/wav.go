@@ -0,0 +1,326 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// Format is the sample layout of PCM audio.
+type Format struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+}
+
+// BlockAlign returns the size of one sample frame in bytes.
+func (f Format) BlockAlign() int {
+	return f.Channels * f.BitsPerSample / 8
+}
+
+// Duration returns the playback length of size bytes of audio.
+func (f Format) Duration(size int) time.Duration {
+	if f.BlockAlign() == 0 || f.SampleRate == 0 {
+		return 0
+	}
+	return time.Duration(size/f.BlockAlign()) * time.Second / time.Duration(f.SampleRate)
+}
+
+// Bytes returns the size of d of audio, rounded down to whole sample frames.
+func (f Format) Bytes(d time.Duration) int {
+	return int(int64(d)*int64(f.SampleRate)/int64(time.Second)) * f.BlockAlign()
+}
+
+// ReadFormat reads the header of the WAV in r and returns its integer PCM
+// sample layout and the offset of the audio data.
+func ReadFormat(r io.ReadSeeker) (Format, int64, error) {
+	info, err := readWAVInfo(r)
+	if err != nil {
+		return Format{}, 0, err
+	}
+	if info.AudioFormat != 1 {
+		return Format{}, 0, ErrUnsupportedWAV
+	}
+	return Format{SampleRate: int(info.SampleRate), Channels: int(info.Channels), BitsPerSample: int(info.BitsPerSample)}, info.DataOffset, nil
+}
+
+// ReadWAV reads the integer PCM WAV at path and returns its sample layout
+// and audio data. A zero or placeholder data size, as left by a recorder that
+// did not finish the file, is read up to the end of the file.
+func ReadWAV(path string) (Format, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Format{}, nil, fmt.Errorf("open wav: %w", err)
+	}
+	defer f.Close()
+
+	format, data, err := pcmData(f)
+	if err != nil {
+		return Format{}, nil, err
+	}
+	pcm, err := io.ReadAll(data)
+	if err != nil {
+		return Format{}, nil, fmt.Errorf("read wav data: %w", err)
+	}
+	return format, pcm, nil
+}
+
+// WriteWAV writes pcm as an integer PCM WAV file in the given format.
+func WriteWAV(path string, format Format, pcm []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create wav: %w", err)
+	}
+	err = writeWAVHeader(f, pcmFmtChunk(format), uint32(len(pcm)))
+	if err == nil {
+		if _, writeErr := f.Write(pcm); writeErr != nil {
+			err = fmt.Errorf("write wav data: %w", writeErr)
+		}
+	}
+	if err == nil && len(pcm)%2 != 0 {
+		_, err = f.Write([]byte{0})
+	}
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("close wav: %w", closeErr)
+	}
+	return err
+}
+
+// WAVWriter writes an integer PCM WAV file while its length is still
+// unknown, the way recorders stream to disk. The header is written with a
+// zero data size and completed by Close, so readers following the file see
+// a valid header from the start.
+type WAVWriter struct {
+	f    *os.File
+	size int64
+}
+
+// CreateWAV creates path and writes a WAV header for format.
+func CreateWAV(path string, format Format) (*WAVWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create wav: %w", err)
+	}
+	if err := writeWAVHeader(f, pcmFmtChunk(format), 0); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &WAVWriter{f: f}, nil
+}
+
+// Write appends pcm to the data chunk.
+func (w *WAVWriter) Write(pcm []byte) (int, error) {
+	n, err := w.f.Write(pcm)
+	w.size += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("write wav data: %w", err)
+	}
+	return n, nil
+}
+
+// Close fills in the RIFF and data chunk sizes and closes the file.
+func (w *WAVWriter) Close() error {
+	err := w.finish()
+	if closeErr := w.f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("close wav: %w", closeErr)
+	}
+	return err
+}
+
+func (w *WAVWriter) finish() error {
+	if w.size%2 != 0 {
+		if _, err := w.f.Write([]byte{0}); err != nil {
+			return fmt.Errorf("write wav padding: %w", err)
+		}
+	}
+	// The canonical header written by CreateWAV puts the RIFF size at byte 4
+	// and the data size right before the audio at byte 40.
+	sizes := []struct {
+		offset int64
+		value  int64
+	}{
+		{4, 36 + w.size + w.size%2},
+		{40, w.size},
+	}
+	for _, field := range sizes {
+		if field.value > math.MaxUint32 {
+			return fmt.Errorf("wav data exceeds 4 GiB")
+		}
+		if _, err := w.f.WriteAt(binary.LittleEndian.AppendUint32(nil, uint32(field.value)), field.offset); err != nil {
+			return fmt.Errorf("write wav header: %w", err)
+		}
+	}
+	return nil
+}
+
+// pcmFmtChunk returns the fmt chunk body for integer PCM audio in format.
+func pcmFmtChunk(format Format) []byte {
+	fmtChunk := make([]byte, 0, 16)
+	fmtChunk = binary.LittleEndian.AppendUint16(fmtChunk, 1)
+	fmtChunk = binary.LittleEndian.AppendUint16(fmtChunk, uint16(format.Channels))
+	fmtChunk = binary.LittleEndian.AppendUint32(fmtChunk, uint32(format.SampleRate))
+	fmtChunk = binary.LittleEndian.AppendUint32(fmtChunk, uint32(format.SampleRate*format.BlockAlign()))
+	fmtChunk = binary.LittleEndian.AppendUint16(fmtChunk, uint16(format.BlockAlign()))
+	fmtChunk = binary.LittleEndian.AppendUint16(fmtChunk, uint16(format.BitsPerSample))
+	return fmtChunk
+}
+
+// wavInfo describes the format and data chunk location of a WAV file.
+type wavInfo struct {
+	AudioFormat   uint16
+	Channels      uint16
+	SampleRate    uint32
+	BlockAlign    uint16
+	BitsPerSample uint16
+	// fmtChunk holds the raw fmt chunk body so derived files keep the
+	// source format byte for byte.
+	fmtChunk   []byte
+	DataOffset int64
+	DataSize   uint32
+}
+
+// frames returns the number of complete sample frames in the data chunk.
+func (w wavInfo) frames() int64 {
+	if w.BlockAlign == 0 {
+		return 0
+	}
+	return int64(w.DataSize) / int64(w.BlockAlign)
+}
+
+func (w wavInfo) framesToDuration(frames int64) time.Duration {
+	if w.SampleRate == 0 {
+		return 0
+	}
+	return time.Duration(frames) * time.Second / time.Duration(w.SampleRate)
+}
+
+func (w wavInfo) durationToFrames(d time.Duration) int64 {
+	return int64(d) * int64(w.SampleRate) / int64(time.Second)
+}
+
+// readWAVInfo walks the RIFF chunks of r and records the fmt chunk and the
+// position of the data chunk. It stops at the data chunk once the fmt chunk
+// has been seen, so files that are still being written, whose data size is
+// zero or a placeholder, are read correctly too.
+func readWAVInfo(r io.ReadSeeker) (wavInfo, error) {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return wavInfo{}, fmt.Errorf("%w: %v", ErrInvalidWAV, err)
+		}
+		return wavInfo{}, fmt.Errorf("read wav header: %w", err)
+	}
+
+	if string(header[:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return wavInfo{}, ErrInvalidWAV
+	}
+
+	var (
+		info    wavInfo
+		hasFmt  bool
+		hasData bool
+	)
+
+	for {
+		chunkHeader := make([]byte, 8)
+		if _, err := io.ReadFull(r, chunkHeader); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return wavInfo{}, fmt.Errorf("read wav chunk header: %w", err)
+		}
+
+		chunkID := string(chunkHeader[:4])
+		chunkSize := binary.LittleEndian.Uint32(chunkHeader[4:8])
+
+		chunkStart, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return wavInfo{}, fmt.Errorf("seek wav chunk start: %w", err)
+		}
+
+		skip := int64(chunkSize)
+		if chunkSize%2 != 0 {
+			skip++
+		}
+
+		switch chunkID {
+		case "fmt ":
+			if chunkSize < 16 {
+				return wavInfo{}, ErrInvalidWAV
+			}
+
+			buf := make([]byte, chunkSize)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return wavInfo{}, fmt.Errorf("read wav fmt chunk: %w", err)
+			}
+
+			info.AudioFormat = binary.LittleEndian.Uint16(buf[0:2])
+			info.Channels = binary.LittleEndian.Uint16(buf[2:4])
+			info.SampleRate = binary.LittleEndian.Uint32(buf[4:8])
+			info.BlockAlign = binary.LittleEndian.Uint16(buf[12:14])
+			info.BitsPerSample = binary.LittleEndian.Uint16(buf[14:16])
+			info.fmtChunk = buf
+			hasFmt = true
+
+			if chunkSize%2 != 0 {
+				if _, err := r.Seek(1, io.SeekCurrent); err != nil {
+					return wavInfo{}, fmt.Errorf("seek wav fmt padding: %w", err)
+				}
+			}
+		case "data":
+			info.DataOffset = chunkStart
+			info.DataSize = chunkSize
+			hasData = true
+			if hasFmt {
+				return info, validateFormat(info.AudioFormat, info.BitsPerSample)
+			}
+			if _, err := r.Seek(skip, io.SeekCurrent); err != nil {
+				return wavInfo{}, fmt.Errorf("seek wav data chunk: %w", err)
+			}
+		default:
+			if _, err := r.Seek(skip, io.SeekCurrent); err != nil {
+				return wavInfo{}, fmt.Errorf("seek wav chunk %s: %w", chunkID, err)
+			}
+		}
+	}
+
+	if !hasFmt || !hasData {
+		return wavInfo{}, ErrInvalidWAV
+	}
+
+	if err := validateFormat(info.AudioFormat, info.BitsPerSample); err != nil {
+		return wavInfo{}, err
+	}
+
+	return info, nil
+}
+
+// writeWAVHeader writes a canonical RIFF header with the given fmt chunk body
+// followed by a data chunk header for dataSize bytes.
+func writeWAVHeader(w io.Writer, fmtChunk []byte, dataSize uint32) error {
+	fmtSize := uint32(len(fmtChunk))
+	fmtPadding := fmtSize % 2
+	riffSize := 4 + (8 + fmtSize + fmtPadding) + (8 + dataSize)
+
+	header := make([]byte, 0, 12+8+len(fmtChunk)+int(fmtPadding)+8)
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, riffSize)
+	header = append(header, "WAVE"...)
+	header = append(header, "fmt "...)
+	header = binary.LittleEndian.AppendUint32(header, fmtSize)
+	header = append(header, fmtChunk...)
+	if fmtPadding != 0 {
+		header = append(header, 0)
+	}
+	header = append(header, "data"...)
+	header = binary.LittleEndian.AppendUint32(header, dataSize)
+
+	if _, err := w.Write(header); err != nil {
+		return fmt.Errorf("write wav header: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFormatAcceptsRecordingInProgress(t *testing.T) {
+	t.Parallel()
+
+	// pw-record leaves the data size at zero until it exits.
+	wav := makePCM16WAV([]int16{100, -100, 200, -200}, 16000, 1)
+	binary.LittleEndian.PutUint32(wav[40:], 0)
+
+	format, offset, err := ReadFormat(bytes.NewReader(wav))
+	require.NoError(t, err)
+	require.Equal(t, Format{SampleRate: 16000, Channels: 1, BitsPerSample: 16}, format)
+	require.EqualValues(t, 44, offset)
+}
+
+func TestReadFormatRejectsFloatAudio(t *testing.T) {
+	t.Parallel()
+
+	wav := makeWAV(3, 32, 16000, 1, make([]byte, 16))
+	_, _, err := ReadFormat(bytes.NewReader(wav))
+	require.ErrorIs(t, err, ErrUnsupportedWAV)
+}
+
+func TestWriteWAVRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	format := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+	samples := speechWithPauses(500*time.Millisecond, [2]float64{0.2, 0.3})
+	pcm := make([]byte, 0, 2*len(samples))
+	for _, sample := range samples {
+		pcm = binary.LittleEndian.AppendUint16(pcm, uint16(sample))
+	}
+
+	path := filepath.Join(t.TempDir(), "window.wav")
+	require.NoError(t, WriteWAV(path, format, pcm))
+
+	require.Equal(t, samples, readPCM16Samples(t, path))
+	duration, err := WAVDuration(path)
+	require.NoError(t, err)
+	require.Equal(t, 500*time.Millisecond, duration)
+	require.Equal(t, duration, format.Duration(len(pcm)))
+	require.Equal(t, len(pcm), format.Bytes(duration))
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, makePCM16WAV(samples, 8000, 1), raw)
+}
+
+func TestWAVWriterCompletesHeaderOnClose(t *testing.T) {
+	t.Parallel()
+
+	samples := []int16{100, -100, 200, -200, 300}
+	pcm := make([]byte, 0, 2*len(samples))
+	for _, sample := range samples {
+		pcm = binary.LittleEndian.AppendUint16(pcm, uint16(sample))
+	}
+
+	path := filepath.Join(t.TempDir(), "stream.wav")
+	w, err := CreateWAV(path, Format{SampleRate: 16000, Channels: 1, BitsPerSample: 16})
+	require.NoError(t, err)
+
+	// Readers following the file see a valid header before any audio.
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	_, offset, err := ReadFormat(f)
+	require.NoError(t, f.Close())
+	require.NoError(t, err)
+	require.EqualValues(t, 44, offset)
+
+	_, err = w.Write(pcm[:4])
+	require.NoError(t, err)
+	_, err = w.Write(pcm[4:])
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, makePCM16WAV(samples, 16000, 1), raw)
+}
+
+func TestReadWAVReadsStreamingPlaceholderSize(t *testing.T) {
+	t.Parallel()
+
+	samples := []int16{100, -100, 200, -200}
+	wav := makePCM16WAV(samples, 8000, 1)
+	// A placeholder data size reads up to the end of the file.
+	binary.LittleEndian.PutUint32(wav[40:], math.MaxUint32)
+	path := filepath.Join(t.TempDir(), "placeholder.wav")
+	require.NoError(t, os.WriteFile(path, append(wav, 0x01), 0o644))
+
+	format, pcm, err := ReadWAV(path)
+	require.NoError(t, err)
+	require.Equal(t, Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}, format)
+	require.Equal(t, wav[44:], pcm, "the trailing half sample frame is dropped")
+}
+
+func TestReadWAVReadsZeroDataSizeToEndOfFile(t *testing.T) {
+	t.Parallel()
+
+	// A recorder killed before it finished the file leaves the size at zero.
+	wav := makePCM16WAV([]int16{100, -100, 200, -200}, 16000, 1)
+	binary.LittleEndian.PutUint32(wav[40:], 0)
+	path := filepath.Join(t.TempDir(), "unfinished.wav")
+	require.NoError(t, os.WriteFile(path, wav, 0o644))
+
+	format, pcm, err := ReadWAV(path)
+	require.NoError(t, err)
+	require.Equal(t, Format{SampleRate: 16000, Channels: 1, BitsPerSample: 16}, format)
+	require.Equal(t, wav[44:], pcm)
+}
+
+func TestMeasurePCM(t *testing.T) {
+	t.Parallel()
+
+	format := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+	pcm := binary.LittleEndian.AppendUint16(nil, uint16(16384))
+	pcm = binary.LittleEndian.AppendUint16(pcm, uint16(0xC000)) // -16384
+
+	metrics, err := MeasurePCM(format, pcm)
+	require.NoError(t, err)
+	require.InDelta(t, -6.02, metrics.RMSdBFS, 0.01)
+	require.InDelta(t, -6.02, metrics.PeakdBFS, 0.01)
+	require.EqualValues(t, 2, metrics.Samples)
+
+	metrics, err = MeasurePCM(format, nil)
+	require.NoError(t, err)
+	require.True(t, math.IsInf(metrics.RMSdBFS, -1))
+}
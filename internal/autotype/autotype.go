@@ -0,0 +1,307 @@
+package autotype
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fmueller/voxclip/internal/applescript"
+	"go.uber.org/zap"
+)
+
+var ErrUnavailable = errors.New("no typing command available")
+
+const (
+	ToolAuto      = "auto"
+	ToolWtype     = "wtype"
+	ToolYdotool   = "ydotool"
+	ToolXdotool   = "xdotool"
+	ToolOsascript = "osascript"
+
+	// NewlineEnter presses Enter for each line break.
+	NewlineEnter = "enter"
+	// NewlineShiftEnter presses Shift+Enter, which starts a new line
+	// without sending the message in most chat applications.
+	NewlineShiftEnter = "shift-enter"
+	// NewlineSpace types line breaks as spaces.
+	NewlineSpace = "space"
+
+	// ydotool sends Linux input event codes rather than characters.
+	ydotoolKeyEnter = "28"
+	ydotoolKeyShift = "42"
+)
+
+// Tools returns the valid values for Options.Tool.
+func Tools() []string {
+	return []string{ToolAuto, ToolWtype, ToolYdotool, ToolXdotool, ToolOsascript}
+}
+
+// Newlines returns the valid values for Options.Newline.
+func Newlines() []string {
+	return []string{NewlineEnter, NewlineShiftEnter, NewlineSpace}
+}
+
+// Options selects how text is typed.
+type Options struct {
+	// Tool forces a typing tool; auto picks the first available one for the
+	// session.
+	Tool string
+	// Delay is the pause between typed characters; 0 uses the tool's
+	// default.
+	Delay time.Duration
+	// Newline selects how line breaks are typed. Defaults to enter.
+	Newline string
+	Logger  *zap.Logger
+}
+
+type command struct {
+	name string
+	args []string
+}
+
+// Typer types text with the tool resolved from Options.
+type Typer struct {
+	tool    string
+	delay   time.Duration
+	newline string
+	logger  *zap.Logger
+
+	lookPath func(string) (string, error)
+	getenv   func(string) string
+	goos     string
+}
+
+// New validates opts and returns a Typer.
+func New(opts Options) (*Typer, error) {
+	tool := strings.TrimSpace(opts.Tool)
+	if tool == "" {
+		tool = ToolAuto
+	}
+	if !slices.Contains(Tools(), tool) {
+		return nil, fmt.Errorf("unknown typing tool %q (valid: %s)", tool, strings.Join(Tools(), ", "))
+	}
+
+	newline := strings.TrimSpace(opts.Newline)
+	if newline == "" {
+		newline = NewlineEnter
+	}
+	if !slices.Contains(Newlines(), newline) {
+		return nil, fmt.Errorf("unknown newline mode %q (valid: %s)", newline, strings.Join(Newlines(), ", "))
+	}
+
+	if opts.Delay < 0 {
+		return nil, fmt.Errorf("typing delay must not be negative, got %s", opts.Delay)
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &Typer{
+		tool:     tool,
+		delay:    opts.Delay,
+		newline:  newline,
+		logger:   logger,
+		lookPath: exec.LookPath,
+		getenv:   os.Getenv,
+		goos:     runtime.GOOS,
+	}, nil
+}
+
+// Tool returns the name of the tool Type would use, or ErrUnavailable.
+func (t *Typer) Tool() (string, error) {
+	return t.resolve()
+}
+
+// Type types text into the focused window.
+func (t *Typer) Type(ctx context.Context, text string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tool, err := t.resolve()
+	if err != nil {
+		return err
+	}
+	t.logger.Debug("typing with tool", zap.String("tool", tool), zap.Int("characters", len([]rune(text))))
+
+	commands, err := t.commandsFor(tool, text)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range commands {
+		if err := runCommand(ctx, cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the forced tool, or the first available one for the
+// session: osascript on macOS, wtype then ydotool on Wayland, xdotool then
+// ydotool on X11.
+func (t *Typer) resolve() (string, error) {
+	if t.tool != ToolAuto {
+		if _, err := t.lookPath(t.tool); err != nil {
+			return "", fmt.Errorf("%w: %s not found in PATH", ErrUnavailable, t.tool)
+		}
+		return t.tool, nil
+	}
+
+	var candidates []string
+	switch {
+	case t.goos == "darwin":
+		candidates = []string{ToolOsascript}
+	case t.getenv("WAYLAND_DISPLAY") != "":
+		candidates = []string{ToolWtype, ToolYdotool}
+	default:
+		candidates = []string{ToolXdotool, ToolYdotool}
+	}
+	for _, candidate := range candidates {
+		if _, err := t.lookPath(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w: install one of %s", ErrUnavailable, strings.Join(candidates, ", "))
+}
+
+// lines splits text at line breaks, after normalizing Windows and old Mac
+// line endings. With the space newline mode a single line is returned.
+func (t *Typer) lines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	if t.newline == NewlineSpace {
+		return []string{strings.ReplaceAll(text, "\n", " ")}
+	}
+	return strings.Split(text, "\n")
+}
+
+// commandsFor returns the commands that type text with tool. Each line is
+// typed by its own command, with a key press for the line break in between.
+func (t *Typer) commandsFor(tool, text string) ([]command, error) {
+	if tool == ToolOsascript {
+		return []command{t.osascriptCommand(text)}, nil
+	}
+
+	var commands []command
+	for i, line := range t.lines(text) {
+		if i > 0 {
+			commands = append(commands, t.newlineCommand(tool))
+		}
+		if line == "" {
+			continue
+		}
+		cmd, err := t.lineCommand(tool, line)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
+	return commands, nil
+}
+
+func (t *Typer) lineCommand(tool, line string) (command, error) {
+	ms := strconv.FormatInt(t.delay.Milliseconds(), 10)
+	switch tool {
+	case ToolWtype:
+		args := []string{}
+		if t.delay > 0 {
+			args = append(args, "-d", ms)
+		}
+		return command{name: ToolWtype, args: append(args, "--", line)}, nil
+	case ToolXdotool:
+		args := []string{"type", "--clearmodifiers"}
+		if t.delay > 0 {
+			args = append(args, "--delay", ms)
+		}
+		return command{name: ToolXdotool, args: append(args, "--", line)}, nil
+	case ToolYdotool:
+		// ydotool types through a virtual keyboard with a US layout, so it
+		// cannot produce characters outside ASCII.
+		for _, r := range line {
+			if r > unicode.MaxASCII {
+				return command{}, fmt.Errorf("ydotool cannot type %q; use wtype or xdotool for non-ASCII text", r)
+			}
+		}
+		args := []string{"type"}
+		if t.delay > 0 {
+			args = append(args, "--key-delay", ms)
+		}
+		return command{name: ToolYdotool, args: append(args, "--", line)}, nil
+	default:
+		return command{}, fmt.Errorf("unknown typing tool %q", tool)
+	}
+}
+
+func (t *Typer) newlineCommand(tool string) command {
+	shift := t.newline == NewlineShiftEnter
+	switch tool {
+	case ToolWtype:
+		if shift {
+			return command{name: ToolWtype, args: []string{"-M", "shift", "-k", "Return", "-m", "shift"}}
+		}
+		return command{name: ToolWtype, args: []string{"-k", "Return"}}
+	case ToolXdotool:
+		if shift {
+			return command{name: ToolXdotool, args: []string{"key", "--clearmodifiers", "shift+Return"}}
+		}
+		return command{name: ToolXdotool, args: []string{"key", "--clearmodifiers", "Return"}}
+	default:
+		if shift {
+			return command{name: ToolYdotool, args: []string{"key", ydotoolKeyShift + ":1", ydotoolKeyEnter + ":1", ydotoolKeyEnter + ":0", ydotoolKeyShift + ":0"}}
+		}
+		return command{name: ToolYdotool, args: []string{"key", ydotoolKeyEnter + ":1", ydotoolKeyEnter + ":0"}}
+	}
+}
+
+// osascriptCommand builds one AppleScript that types every line through
+// System Events. With a delay, characters are typed one at a time.
+func (t *Typer) osascriptCommand(text string) command {
+	args := []string{"-e", `tell application "System Events"`}
+	for i, line := range t.lines(text) {
+		if i > 0 {
+			if t.newline == NewlineShiftEnter {
+				args = append(args, "-e", "key code 36 using shift down")
+			} else {
+				args = append(args, "-e", "key code 36")
+			}
+		}
+		if line == "" {
+			continue
+		}
+		if t.delay <= 0 {
+			args = append(args, "-e", "keystroke "+applescript.Quote(line))
+			continue
+		}
+		seconds := strconv.FormatFloat(t.delay.Seconds(), 'f', -1, 64)
+		for _, r := range line {
+			args = append(args, "-e", "keystroke "+applescript.Quote(string(r)), "-e", "delay "+seconds)
+		}
+	}
+	args = append(args, "-e", "end tell")
+	return command{name: ToolOsascript, args: args}
+}
+
+func runCommand(ctx context.Context, spec command) error {
+	cmd := exec.CommandContext(ctx, spec.name, spec.args...)
+	cmd.Stdout = io.Discard
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("type text with %s: %w: %s", spec.name, err, msg)
+		}
+		return fmt.Errorf("type text with %s: %w", spec.name, err)
+	}
+	return nil
+}
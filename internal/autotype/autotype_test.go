@@ -0,0 +1,182 @@
+package autotype
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTyper(t *testing.T, opts Options, env map[string]string, installed ...string) *Typer {
+	t.Helper()
+
+	typer, err := New(opts)
+	require.NoError(t, err)
+
+	typer.goos = "linux"
+	typer.lookPath = func(name string) (string, error) {
+		for _, tool := range installed {
+			if tool == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+	typer.getenv = func(key string) string { return env[key] }
+	return typer
+}
+
+func TestResolvePicksToolForSession(t *testing.T) {
+	t.Parallel()
+
+	wayland := map[string]string{"WAYLAND_DISPLAY": "wayland-0"}
+	tests := []struct {
+		name      string
+		opts      Options
+		env       map[string]string
+		installed []string
+		want      string
+	}{
+		{name: "wayland prefers wtype", env: wayland, installed: []string{ToolXdotool, ToolYdotool, ToolWtype}, want: ToolWtype},
+		{name: "wayland falls back to ydotool", env: wayland, installed: []string{ToolXdotool, ToolYdotool}, want: ToolYdotool},
+		{name: "x11 prefers xdotool", installed: []string{ToolWtype, ToolYdotool, ToolXdotool}, want: ToolXdotool},
+		{name: "x11 falls back to ydotool", installed: []string{ToolWtype, ToolYdotool}, want: ToolYdotool},
+		{name: "forced tool", opts: Options{Tool: ToolYdotool}, env: wayland, installed: []string{ToolWtype, ToolYdotool}, want: ToolYdotool},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			typer := newTestTyper(t, tt.opts, tt.env, tt.installed...)
+			got, err := typer.Tool()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolveReportsMissingTools(t *testing.T) {
+	t.Parallel()
+
+	typer := newTestTyper(t, Options{}, map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, ToolXdotool)
+	_, err := typer.Tool()
+	require.ErrorIs(t, err, ErrUnavailable)
+	require.ErrorContains(t, err, "wtype, ydotool")
+
+	typer = newTestTyper(t, Options{Tool: ToolWtype}, nil)
+	_, err = typer.Tool()
+	require.ErrorContains(t, err, "wtype not found")
+}
+
+func TestCommandsTypeLinesAndNewlines(t *testing.T) {
+	t.Parallel()
+
+	text := "héllo wörld\r\n-dash\n\nend"
+	tests := []struct {
+		name string
+		tool string
+		opts Options
+		want []command
+	}{
+		{
+			name: "wtype",
+			tool: ToolWtype,
+			want: []command{
+				{name: "wtype", args: []string{"--", "héllo wörld"}},
+				{name: "wtype", args: []string{"-k", "Return"}},
+				{name: "wtype", args: []string{"--", "-dash"}},
+				{name: "wtype", args: []string{"-k", "Return"}},
+				{name: "wtype", args: []string{"-k", "Return"}},
+				{name: "wtype", args: []string{"--", "end"}},
+			},
+		},
+		{
+			name: "xdotool with delay and shift-enter",
+			tool: ToolXdotool,
+			opts: Options{Delay: 15 * time.Millisecond, Newline: NewlineShiftEnter},
+			want: []command{
+				{name: "xdotool", args: []string{"type", "--clearmodifiers", "--delay", "15", "--", "héllo wörld"}},
+				{name: "xdotool", args: []string{"key", "--clearmodifiers", "shift+Return"}},
+				{name: "xdotool", args: []string{"type", "--clearmodifiers", "--delay", "15", "--", "-dash"}},
+				{name: "xdotool", args: []string{"key", "--clearmodifiers", "shift+Return"}},
+				{name: "xdotool", args: []string{"key", "--clearmodifiers", "shift+Return"}},
+				{name: "xdotool", args: []string{"type", "--clearmodifiers", "--delay", "15", "--", "end"}},
+			},
+		},
+		{
+			name: "wtype with spaces for newlines",
+			tool: ToolWtype,
+			opts: Options{Delay: 5 * time.Millisecond, Newline: NewlineSpace},
+			want: []command{
+				{name: "wtype", args: []string{"-d", "5", "--", "héllo wörld -dash  end"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			typer := newTestTyper(t, tt.opts, nil)
+			got, err := typer.commandsFor(tt.tool, text)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestYdotoolCommands(t *testing.T) {
+	t.Parallel()
+
+	typer := newTestTyper(t, Options{Delay: 10 * time.Millisecond, Newline: NewlineShiftEnter}, nil)
+	got, err := typer.commandsFor(ToolYdotool, "one\ntwo")
+	require.NoError(t, err)
+	require.Equal(t, []command{
+		{name: "ydotool", args: []string{"type", "--key-delay", "10", "--", "one"}},
+		{name: "ydotool", args: []string{"key", "42:1", "28:1", "28:0", "42:0"}},
+		{name: "ydotool", args: []string{"type", "--key-delay", "10", "--", "two"}},
+	}, got)
+
+	_, err = typer.commandsFor(ToolYdotool, "grüße")
+	require.ErrorContains(t, err, "non-ASCII")
+}
+
+func TestOsascriptCommandEscapesText(t *testing.T) {
+	t.Parallel()
+
+	typer := newTestTyper(t, Options{}, nil)
+	got, err := typer.commandsFor(ToolOsascript, `say "hi" \ ok`+"\nnext")
+	require.NoError(t, err)
+	require.Equal(t, []command{{name: "osascript", args: []string{
+		"-e", `tell application "System Events"`,
+		"-e", `keystroke "say \"hi\" \\ ok"`,
+		"-e", "key code 36",
+		"-e", `keystroke "next"`,
+		"-e", "end tell",
+	}}}, got)
+
+	typer = newTestTyper(t, Options{Delay: 20 * time.Millisecond}, nil)
+	got, err = typer.commandsFor(ToolOsascript, "ab")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"-e", `tell application "System Events"`,
+		"-e", `keystroke "a"`, "-e", "delay 0.02",
+		"-e", `keystroke "b"`, "-e", "delay 0.02",
+		"-e", "end tell",
+	}, got[0].args)
+}
+
+func TestNewValidatesOptions(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(Options{Tool: "dotool"})
+	require.ErrorContains(t, err, "unknown typing tool")
+
+	_, err = New(Options{Newline: "tab"})
+	require.ErrorContains(t, err, "unknown newline mode")
+
+	_, err = New(Options{Delay: -time.Millisecond})
+	require.ErrorContains(t, err, "must not be negative")
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/spf13/cobra"
+)
+
+func newAudioCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audio",
+		Short: "Inspect and fix recorded audio files",
+	}
+	cmd.AddCommand(newAudioRepairCmd())
+	return cmd
+}
+
+func newAudioRepairCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repair <file>...",
+		Short: "Fix the header sizes of WAV files left behind by an interrupted recorder",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			for _, path := range args {
+				repaired, err := audio.RepairWAV(path)
+				if err != nil {
+					return fmt.Errorf("repair %s: %w", path, err)
+				}
+				if repaired {
+					fmt.Fprintf(out, "%s: repaired\n", path)
+				} else {
+					fmt.Fprintf(out, "%s: ok\n", path)
+				}
+			}
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// truncatedWAV returns a WAV whose header sizes were never filled in, as left
+// behind by a killed recorder.
+func truncatedWAV(samples []int16) []byte {
+	wav := makePCM16WAVForTest(samples, 16000, 1)
+	binary.LittleEndian.PutUint32(wav[4:], 0)
+	binary.LittleEndian.PutUint32(wav[40:], 0)
+	return wav
+}
+
+func TestAudioRepairCommand(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.wav")
+	intact := filepath.Join(dir, "intact.wav")
+	require.NoError(t, os.WriteFile(broken, truncatedWAV([]int16{1, 2, 3, 4}), 0o644))
+	require.NoError(t, os.WriteFile(intact, makePCM16WAVForTest([]int16{1, 2}, 16000, 1), 0o644))
+
+	stdout, _, err := runCommand(t, []string{"audio", "repair", broken, intact})
+	require.NoError(t, err)
+	require.Equal(t, broken+": repaired\n"+intact+": ok\n", stdout)
+
+	raw, err := os.ReadFile(broken)
+	require.NoError(t, err)
+	require.Equal(t, makePCM16WAVForTest([]int16{1, 2, 3, 4}, 16000, 1), raw)
+}
+
+func TestAudioRepairCommandRejectsInvalidFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("not audio"), 0o644))
+
+	_, _, err := runCommand(t, []string{"audio", "repair", path})
+	require.ErrorContains(t, err, "repair "+path)
+}
@@ -40,6 +40,26 @@ func TestCLIErrorCases(t *testing.T) {
 			args:        []string{"transcribe", "a.wav", "b.wav"},
 			errContains: "accepts 1 arg(s)",
 		},
+		{
+			name:        "languages with explicit language",
+			args:        []string{"transcribe", "--language", "de", "--languages", "en", "f.wav"},
+			errContains: "requires --language auto",
+		},
+		{
+			name:        "languages containing auto",
+			args:        []string{"transcribe", "--languages", "en,auto", "f.wav"},
+			errContains: "invalid --languages",
+		},
+		{
+			name:        "negative threads",
+			args:        []string{"transcribe", "--threads", "-1", "f.wav"},
+			errContains: "invalid decoding options",
+		},
+		{
+			name:        "reserved whisper arg",
+			args:        []string{"transcribe", "--whisper-arg", "-of", "f.wav"},
+			errContains: "managed by voxclip",
+		},
 		{
 			name:        "transcribe nonexistent file",
 			args:        []string{"transcribe", "/no/such/file.wav"},
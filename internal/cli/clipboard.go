@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/clipboard"
+	"github.com/fmueller/voxclip/internal/platform"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// savedClipboardFile holds the clipboard contents replaced by the last copy
+// made with --restore-clipboard, in the state directory.
+const savedClipboardFile = "clipboard-restore.json"
+
+type savedClipboard struct {
+	// ID identifies the copy that saved the contents, so a delayed restore
+	// started for an earlier copy leaves a newer one alone.
+	ID   string `json:"id"`
+	Tool string `json:"tool"`
+	// Selections maps each copied selection to its previous text.
+	Selections map[string]string `json:"selections"`
+	Copied     string            `json:"copied"`
+}
+
+func bindClipboardFlags(cmd *cobra.Command, app *appState) {
+	o := &app.clipboard
+	cmd.Flags().StringVar(&o.Target, "clipboard-target", o.Target, fmt.Sprintf("Selection to copy to (%s)", strings.Join(clipboard.Targets(), "|")))
+	cmd.Flags().StringVar(&o.Tool, "clipboard-tool", o.Tool, fmt.Sprintf("Clipboard tool (%s); auto uses --clipboard-command, then wl-copy, xclip, xsel or pbcopy, then OSC 52", strings.Join(clipboard.Tools(), "|")))
+	cmd.Flags().StringVar(&o.Command, "clipboard-command", o.Command, "Shell command that receives the transcript on stdin; {target} is replaced with clipboard or primary")
+}
+
+func (a *appState) newClipboard() (*clipboard.Copier, error) {
+	opts := a.clipboard
+	opts.Logger = a.log()
+	return clipboard.New(opts)
+}
+
+func (a *appState) copyToClipboard(ctx context.Context, value string) error {
+	copier, err := a.newClipboard()
+	if err != nil {
+		return err
+	}
+	if a.restoreAfter <= 0 {
+		return copier.Copy(ctx, value)
+	}
+
+	saved := a.saveClipboard(ctx, copier, value)
+	if err := copier.Copy(ctx, value); err != nil {
+		if saved != nil {
+			a.removeSavedClipboard()
+		}
+		return err
+	}
+	if saved == nil {
+		return nil
+	}
+
+	restoreFn := a.restoreFn
+	if restoreFn == nil {
+		restoreFn = a.startClipboardRestore
+	}
+	if err := restoreFn(saved.ID, a.restoreAfter); err != nil {
+		a.log().Warn("failed to schedule clipboard restore; run voxclip clipboard restore", zap.Error(err))
+	}
+	return nil
+}
+
+// saveClipboard stores the text value is about to replace. Selections that
+// cannot be read as text are skipped with a warning. It returns nil when
+// nothing was saved.
+func (a *appState) saveClipboard(ctx context.Context, copier *clipboard.Copier, value string) *savedClipboard {
+	tool, err := copier.Tool()
+	if err != nil {
+		return nil
+	}
+
+	// A copy made while an earlier restore is still pending would otherwise
+	// save the earlier transcript instead of what the user had copied.
+	pending, _ := loadSavedClipboard()
+
+	saved := &savedClipboard{
+		ID:         strconv.FormatInt(time.Now().UnixNano(), 10),
+		Tool:       tool,
+		Selections: map[string]string{},
+		Copied:     value,
+	}
+	for _, target := range copier.Targets() {
+		text, err := copier.Read(ctx, target)
+		if err != nil {
+			a.log().Warn("previous clipboard contents will not be restored", zap.String("selection", target), zap.Error(err))
+			continue
+		}
+		if previous, ok := pending.Selections[target]; ok && pending.Copied == text {
+			text = previous
+		}
+		saved.Selections[target] = text
+	}
+	if len(saved.Selections) == 0 {
+		return nil
+	}
+
+	if err := writeSavedClipboard(saved); err != nil {
+		a.log().Warn("failed to save clipboard contents", zap.Error(err))
+		return nil
+	}
+	return saved
+}
+
+// startClipboardRestore runs voxclip clipboard restore in the background, so
+// the previous contents come back after voxclip itself has exited.
+func (a *appState) startClipboardRestore(id string, delay time.Duration) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolve voxclip executable path: %w", err)
+	}
+	cmd := exec.Command(exe, "clipboard", "restore", "--after", delay.String(), "--saved-id", id)
+	platform.DetachProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start clipboard restore: %w", err)
+	}
+	return cmd.Process.Release()
+}
+
+// restoreSavedClipboard puts the saved contents back. With an id, as used by the
+// delayed restore, it only does so while that copy is the latest one and the
+// selection still holds the copied text.
+func (a *appState) restoreSavedClipboard(ctx context.Context, id string) error {
+	saved, err := loadSavedClipboard()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			if id != "" {
+				return nil
+			}
+			return errors.New("no saved clipboard contents to restore")
+		}
+		return err
+	}
+	if id != "" && saved.ID != id {
+		a.log().Debug("clipboard was copied again; leaving restore to the newer copy")
+		return nil
+	}
+
+	skipped := false
+	for target, text := range saved.Selections {
+		copier, err := clipboard.New(clipboard.Options{Target: target, Tool: saved.Tool, Logger: a.log()})
+		if err != nil {
+			return err
+		}
+		if id != "" {
+			if current, err := copier.Read(ctx, target); err != nil || current != saved.Copied {
+				a.log().Info("clipboard changed since the transcript was copied; not restoring", zap.String("selection", target))
+				skipped = true
+				continue
+			}
+		}
+		if err := copier.Copy(ctx, text); err != nil {
+			return fmt.Errorf("restore %s selection: %w", target, err)
+		}
+	}
+
+	// Skipped contents stay saved for an explicit voxclip clipboard restore.
+	if skipped {
+		return nil
+	}
+	a.removeSavedClipboard()
+	a.log().Info("previous clipboard contents restored")
+	return nil
+}
+
+func savedClipboardPath() (string, error) {
+	stateDir, err := platform.ResolveStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, savedClipboardFile), nil
+}
+
+func loadSavedClipboard() (savedClipboard, error) {
+	path, err := savedClipboardPath()
+	if err != nil {
+		return savedClipboard{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return savedClipboard{}, err
+	}
+	var saved savedClipboard
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return savedClipboard{}, fmt.Errorf("parse saved clipboard %s: %w", path, err)
+	}
+	return saved, nil
+}
+
+func writeSavedClipboard(saved *savedClipboard) error {
+	path, err := savedClipboardPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	// The clipboard may hold passwords, so the file is private.
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("write saved clipboard: %w", err)
+	}
+	return nil
+}
+
+func (a *appState) removeSavedClipboard() {
+	path, err := savedClipboardPath()
+	if err != nil {
+		return
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		a.log().Warn("failed to remove saved clipboard", zap.String("path", path), zap.Error(err))
+	}
+}
+
+func newClipboardCmd(app *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clipboard",
+		Short: "Manage clipboard contents saved by --restore-clipboard",
+	}
+	cmd.AddCommand(newClipboardRestoreCmd(app))
+	return cmd
+}
+
+func newClipboardRestoreCmd(app *appState) *cobra.Command {
+	var (
+		after time.Duration
+		id    string
+	)
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Put back the clipboard contents saved by --restore-clipboard",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if after > 0 {
+				select {
+				case <-time.After(after):
+				case <-cmd.Context().Done():
+					return cmd.Context().Err()
+				}
+			}
+			return app.restoreSavedClipboard(cmd.Context(), id)
+		},
+	}
+
+	bindLoggingFlags(cmd, app)
+	// Used by the background restore started after a copy.
+	cmd.Flags().DurationVar(&after, "after", 0, "Wait this long before restoring")
+	cmd.Flags().StringVar(&id, "saved-id", "", "Only restore the contents saved by this copy, if still unchanged")
+	_ = cmd.Flags().MarkHidden("after")
+	_ = cmd.Flags().MarkHidden("saved-id")
+	return cmd
+}
+
+// clipboardStatus reports the tool a copy would use and the selections it
+// writes.
+func (a *appState) clipboardStatus() (string, string) {
+	copier, err := a.newClipboard()
+	if err != nil {
+		return "unavailable", err.Error()
+	}
+	tool, err := copier.Tool()
+	if err != nil {
+		return "unavailable", err.Error()
+	}
+	if tool == clipboard.ToolCommand {
+		tool = fmt.Sprintf("%s %q", tool, a.clipboard.Command)
+	}
+	return "ready", fmt.Sprintf("%s (%s)", tool, strings.Join(copier.Targets(), ", "))
+}
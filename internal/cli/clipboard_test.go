@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// installClipboardStubs puts wl-copy and wl-paste stubs backed by files in dir
+// on PATH and returns the file holding the clipboard text.
+func installClipboardStubs(t *testing.T, types string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	clip := filepath.Join(dir, "clipboard")
+	typesFile := filepath.Join(dir, "types")
+	paste := "#!/bin/sh\ncase \"$1\" in --list-types) cat " + typesFile + " ;; *) cat " + clip + " ;; esac\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "wl-paste"), []byte(paste), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "wl-copy"), []byte("#!/bin/sh\ncat > "+clip+"\n"), 0o755))
+	require.NoError(t, os.WriteFile(typesFile, []byte(types), 0o644))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	return clip
+}
+
+func TestRestoreClipboardPutsPreviousTextBack(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("uses the Wayland clipboard tools")
+	}
+
+	clip := installClipboardStubs(t, "text/plain\n")
+	require.NoError(t, os.WriteFile(clip, []byte("previous text"), 0o644))
+
+	var scheduled []string
+	app := &appState{
+		restoreAfter: 2 * time.Second,
+		restoreFn: func(id string, delay time.Duration) error {
+			require.Equal(t, 2*time.Second, delay)
+			scheduled = append(scheduled, id)
+			return nil
+		},
+	}
+	require.NoError(t, app.copyToClipboard(context.Background(), "first transcript"))
+	require.NoError(t, app.copyToClipboard(context.Background(), "second transcript"))
+	require.Len(t, scheduled, 2)
+
+	// The restore scheduled by the first copy leaves the second one alone.
+	require.NoError(t, app.restoreSavedClipboard(context.Background(), scheduled[0]))
+	got, err := os.ReadFile(clip)
+	require.NoError(t, err)
+	require.Equal(t, "second transcript", string(got))
+
+	require.NoError(t, app.restoreSavedClipboard(context.Background(), scheduled[1]))
+	got, err = os.ReadFile(clip)
+	require.NoError(t, err)
+	require.Equal(t, "previous text", string(got), "the text from before the first copy is restored")
+
+	_, _, err = runCommand(t, []string{"clipboard", "restore"})
+	require.ErrorContains(t, err, "no saved clipboard contents")
+}
+
+func TestRestoreClipboardSkipsChangedClipboard(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("uses the Wayland clipboard tools")
+	}
+
+	clip := installClipboardStubs(t, "text/plain\n")
+	require.NoError(t, os.WriteFile(clip, []byte("previous text"), 0o644))
+
+	var id string
+	app := &appState{
+		restoreAfter: time.Second,
+		restoreFn: func(savedID string, _ time.Duration) error {
+			id = savedID
+			return nil
+		},
+	}
+	require.NoError(t, app.copyToClipboard(context.Background(), "transcript"))
+	require.NoError(t, os.WriteFile(clip, []byte("copied by the user"), 0o644))
+
+	require.NoError(t, app.restoreSavedClipboard(context.Background(), id))
+	got, err := os.ReadFile(clip)
+	require.NoError(t, err)
+	require.Equal(t, "copied by the user", string(got))
+
+	// An explicit restore does not check the current contents.
+	_, _, err = runCommand(t, []string{"clipboard", "restore"})
+	require.NoError(t, err)
+	got, err = os.ReadFile(clip)
+	require.NoError(t, err)
+	require.Equal(t, "previous text", string(got))
+}
+
+func TestRestoreClipboardSkipsImages(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("uses the Wayland clipboard tools")
+	}
+
+	clip := installClipboardStubs(t, "image/png\n")
+	app := &appState{
+		restoreAfter: time.Second,
+		restoreFn: func(string, time.Duration) error {
+			t.Fatal("nothing was saved, so no restore should be scheduled")
+			return nil
+		},
+	}
+	require.NoError(t, app.copyToClipboard(context.Background(), "transcript"))
+
+	got, err := os.ReadFile(clip)
+	require.NoError(t, err)
+	require.Equal(t, "transcript", string(got))
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fmueller/voxclip/internal/config"
+	"github.com/fmueller/voxclip/internal/platform"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	configPathEnv    = "VOXCLIP_CONFIG"
+	configProfileEnv = "VOXCLIP_PROFILE"
+
+	// configAnnotation marks the commands that read the config file. The
+	// others, such as version, never load it, so a broken config file or an
+	// unknown profile cannot stop them.
+	configAnnotation = "voxclip.config"
+)
+
+// withConfig marks cmd as reading the config file.
+func withConfig(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[configAnnotation] = "true"
+	return cmd
+}
+
+func usesConfig(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations[configAnnotation]
+	return ok
+}
+
+// commandConfig loads the config file for cmd and applies its flag values.
+// Commands that do not read the config file get an empty one.
+func commandConfig(cmd *cobra.Command) (config.File, error) {
+	if !usesConfig(cmd) {
+		return config.File{}, nil
+	}
+	file, err := loadConfig()
+	if err != nil {
+		return config.File{}, err
+	}
+	if err := applyConfig(cmd, file); err != nil {
+		return config.File{}, err
+	}
+	return file, nil
+}
+
+// loadConfig reads the config file named by VOXCLIP_CONFIG, or the default
+// platform location. Only an explicitly named file is required to exist.
+func loadConfig() (config.File, error) {
+	path := strings.TrimSpace(os.Getenv(configPathEnv))
+	explicit := path != ""
+	if !explicit {
+		resolved, err := platform.ResolveConfigPath()
+		if err != nil {
+			return config.File{}, nil
+		}
+		path = resolved
+	}
+
+	file, err := config.Load(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return config.File{}, nil
+		}
+		return config.File{}, err
+	}
+	return file, nil
+}
+
+// applyConfig uses config file values as defaults for the flags of cmd that
+// were not set on the command line. Names must match a flag of some voxclip
+// command; values for flags the running command does not have are ignored.
+func applyConfig(cmd *cobra.Command, file config.File) error {
+	values, err := file.FlagValues(strings.TrimSpace(os.Getenv(configProfileEnv)))
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	known := knownFlagNames(cmd.Root())
+	var errs []error
+	for name, items := range values {
+		if _, ok := known[name]; !ok {
+			errs = append(errs, fmt.Errorf("unknown flag %q", name))
+			continue
+		}
+
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		for _, item := range items {
+			if err := flag.Value.Set(item); err != nil {
+				errs = append(errs, fmt.Errorf("flag %q: invalid value %q: %w", name, item, err))
+				break
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	return nil
+}
+
+func knownFlagNames(root *cobra.Command) map[string]struct{} {
+	names := make(map[string]struct{})
+	var walk func(*cobra.Command)
+	walk = func(c *cobra.Command) {
+		c.Flags().VisitAll(func(f *pflag.Flag) {
+			names[f.Name] = struct{}{}
+		})
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+	return names
+}
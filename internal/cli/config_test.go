@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fmueller/voxclip/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func findSubcommand(t *testing.T, root *cobra.Command, name string) *cobra.Command {
+	t.Helper()
+	sub, _, err := root.Find([]string{name})
+	require.NoError(t, err)
+	return sub
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestApplyConfigSetsUnchangedFlags(t *testing.T) {
+	t.Setenv(configProfileEnv, "")
+
+	cmd := findSubcommand(t, NewRootCmd(), "transcribe")
+	require.NoError(t, cmd.Flags().Parse([]string{"--beam-size", "8"}))
+
+	file := config.File{Flags: map[string]any{
+		"threads":     "4",
+		"beam-size":   "2",
+		"whisper-arg": []any{"--prompt", "a, b"},
+		"pid-file":    "/tmp/ignored.pid",
+	}}
+	require.NoError(t, applyConfig(cmd, file))
+
+	require.Equal(t, "4", cmd.Flags().Lookup("threads").Value.String())
+	require.Equal(t, "8", cmd.Flags().Lookup("beam-size").Value.String())
+	extra, err := cmd.Flags().GetStringArray("whisper-arg")
+	require.NoError(t, err)
+	require.Equal(t, []string{"--prompt", "a, b"}, extra)
+}
+
+func TestApplyConfigUsesSelectedProfile(t *testing.T) {
+	t.Setenv(configProfileEnv, "meeting")
+
+	cmd := findSubcommand(t, NewRootCmd(), "transcribe")
+	file := config.File{
+		Flags:    map[string]any{"model": "tiny", "threads": "2"},
+		Profiles: map[string]config.Profile{"meeting": {Flags: map[string]any{"model": "medium"}}},
+	}
+	require.NoError(t, applyConfig(cmd, file))
+
+	require.Equal(t, "medium", cmd.Flags().Lookup("model").Value.String())
+	require.Equal(t, "2", cmd.Flags().Lookup("threads").Value.String())
+}
+
+func TestApplyConfigRejectsUnknownFlag(t *testing.T) {
+	t.Setenv(configProfileEnv, "")
+
+	cmd := findSubcommand(t, NewRootCmd(), "transcribe")
+	err := applyConfig(cmd, config.File{Flags: map[string]any{"thread": "4"}})
+	require.ErrorContains(t, err, `unknown flag "thread"`)
+}
+
+func TestApplyConfigRejectsInvalidValue(t *testing.T) {
+	t.Setenv(configProfileEnv, "")
+
+	cmd := findSubcommand(t, NewRootCmd(), "transcribe")
+	err := applyConfig(cmd, config.File{Flags: map[string]any{"threads": "many"}})
+	require.ErrorContains(t, err, `flag "threads"`)
+}
+
+func TestConfigDecodingOptionsAreValidated(t *testing.T) {
+	t.Setenv(configPathEnv, writeConfigFile(t, `{"flags": {"threads": -1}}`))
+	t.Setenv(configProfileEnv, "")
+
+	_, _, err := runCommand(t, []string{"transcribe", "/no/such/file.wav"})
+	require.ErrorContains(t, err, "invalid decoding options")
+}
+
+func TestExplicitConfigPathMustExist(t *testing.T) {
+	t.Setenv(configPathEnv, filepath.Join(t.TempDir(), "missing.json"))
+
+	_, _, err := runCommand(t, []string{"transcribe", "/no/such/file.wav"})
+	require.ErrorContains(t, err, "read config")
+}
+
+func TestCommandsWithoutConfigIgnoreBrokenConfigFile(t *testing.T) {
+	t.Setenv(configPathEnv, writeConfigFile(t, `{"flags": `))
+	t.Setenv(configProfileEnv, "missing")
+
+	stdout, _, err := runCommand(t, []string{"version"})
+	require.NoError(t, err)
+	require.NotEmpty(t, stdout)
+
+	_, _, err = runCommand(t, []string{"help"})
+	require.NoError(t, err)
+
+	_, _, err = runCommand(t, []string{"transcribe", "/no/such/file.wav"})
+	require.ErrorContains(t, err, "config")
+}
+
+func TestUnknownProfileOnlyFailsCommandsWithConfig(t *testing.T) {
+	t.Setenv(configPathEnv, writeConfigFile(t, `{"flags": {"model": "tiny"}}`))
+	t.Setenv(configProfileEnv, "missing")
+
+	path := filepath.Join(t.TempDir(), "cut.wav")
+	require.NoError(t, os.WriteFile(path, truncatedWAV([]int16{1, 2, 3}), 0o644))
+	stdout, _, err := runCommand(t, []string{"audio", "repair", path})
+	require.NoError(t, err)
+	require.Contains(t, stdout, "repaired")
+
+	_, _, err = runCommand(t, []string{"transcribe", "/no/such/file.wav"})
+	require.ErrorContains(t, err, `unknown profile "missing"`)
+}
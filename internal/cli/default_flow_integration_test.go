@@ -218,6 +218,37 @@ func TestRunDefaultCopiesWithoutNewlineByDefault(t *testing.T) {
 	require.Equal(t, "hello world", copiedValue)
 }
 
+func TestRunDefaultTypesTranscriptWithOutputToType(t *testing.T) {
+	var typedValue string
+	out := new(bytes.Buffer)
+	audioFile := filepath.Join(t.TempDir(), "audio.wav")
+	require.NoError(t, os.WriteFile(audioFile, []byte("fake"), 0o644))
+
+	app := &appState{
+		out:         out,
+		outputs:     []string{outputType},
+		preflightFn: noopPreflight,
+		recordFn: func(_ context.Context, _ recordOptions) (string, error) {
+			return audioFile, nil
+		},
+		transcribeFn: func(_ context.Context, _ string) (string, error) {
+			return "hello world", nil
+		},
+		copyFn: func(_ context.Context, _ string) error {
+			t.Fatal("the transcript should be typed, not copied")
+			return nil
+		},
+		typeFn: func(_ context.Context, value string) error {
+			typedValue = value
+			return nil
+		},
+	}
+
+	err := app.runDefault(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "hello world", typedValue)
+}
+
 func TestRunDefaultSkipsTranscribeWhenRecordingIsSilent(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "silent.wav")
 	require.NoError(t, os.WriteFile(path, makePCM16WAVForTest(make([]int16, 16000), 16000, 1), 0o644))
@@ -338,3 +369,38 @@ func TestRunDefaultPreflightErrorAbortsBeforeRecording(t *testing.T) {
 	require.Error(t, err)
 	require.False(t, recorded, "recording should not happen when preflight fails")
 }
+
+func TestRunDefaultRecordsWithFileBackend(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+
+	fixture, err := filepath.Abs(filepath.Join("..", "..", "testdata", "audio", "fsdd", "1_jackson_0.wav"))
+	require.NoError(t, err)
+	want, err := os.ReadFile(fixture)
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	app := &appState{
+		out:         out,
+		noProgress:  true,
+		immediate:   true,
+		backend:     "file:" + fixture,
+		now:         time.Now,
+		preflightFn: noopPreflight,
+		transcribeFn: func(_ context.Context, audioPath string) (string, error) {
+			recorded, err := os.ReadFile(audioPath)
+			if err != nil {
+				return "", err
+			}
+			if !bytes.Equal(recorded[44:], want[44:]) {
+				return "", errors.New("recording does not match the replayed file")
+			}
+			return "one", nil
+		},
+		copyFn: func(context.Context, string) error { return nil },
+	}
+
+	require.NoError(t, app.runDefault(context.Background()))
+	require.Equal(t, "one\n", out.String())
+}
@@ -1,48 +1,127 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"runtime"
+	"text/tabwriter"
 
 	"github.com/fmueller/voxclip/internal/record"
 	"github.com/spf13/cobra"
 )
 
 func newDevicesCmd(app *appState) *cobra.Command {
-	return &cobra.Command{
+	var asJSON bool
+
+	cmd := &cobra.Command{
 		Use:   "devices",
 		Short: "List recording devices and backend diagnostics",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			backends := record.DefaultBackends(runtime.GOOS)
+			backends := record.DefaultBackends(runtime.GOOS, app.backends...)
 			if len(backends) == 0 {
 				return fmt.Errorf("unsupported OS: %s", runtime.GOOS)
 			}
 
+			if asJSON {
+				return writeDevicesJSON(cmd, backends)
+			}
+
+			out := cmd.OutOrStdout()
 			for _, backend := range backends {
-				fmt.Fprintf(cmd.OutOrStdout(), "== %s ==\n", backend.Name())
+				fmt.Fprintf(out, "== %s ==\n", backend.Name())
 				if !backend.Available() {
-					fmt.Fprintln(cmd.OutOrStdout(), "not available on PATH")
-					fmt.Fprintln(cmd.OutOrStdout())
+					fmt.Fprintln(out, record.UnavailableReason(backend))
+					fmt.Fprintln(out)
 					continue
 				}
 
-				out, err := backend.ListDevices(cmd.Context())
+				devices, err := record.Devices(cmd.Context(), backend)
+				if errors.Is(err, record.ErrNoDeviceList) {
+					writeRawDeviceListing(cmd, backend)
+					continue
+				}
 				if err != nil {
-					fmt.Fprintf(cmd.OutOrStdout(), "failed to list devices: %v\n\n", err)
+					fmt.Fprintf(out, "failed to list devices: %v\n\n", err)
 					continue
 				}
-
-				if out == "" {
-					fmt.Fprintln(cmd.OutOrStdout(), "no output")
-					fmt.Fprintln(cmd.OutOrStdout())
+				if len(devices) == 0 {
+					fmt.Fprintln(out, "no devices found")
+					fmt.Fprintln(out)
 					continue
 				}
 
-				fmt.Fprintln(cmd.OutOrStdout(), out)
-				fmt.Fprintln(cmd.OutOrStdout())
+				if err := writeDeviceTable(out, devices); err != nil {
+					return err
+				}
+				fmt.Fprintln(out)
 			}
 
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the devices of all available backends as a JSON array")
+
+	return cmd
+}
+
+// writeRawDeviceListing prints the listing of a backend that cannot be parsed
+// into devices, such as a custom backend's device-list command.
+func writeRawDeviceListing(cmd *cobra.Command, backend record.Backend) {
+	out := cmd.OutOrStdout()
+	listing, err := backend.ListDevices(cmd.Context())
+	switch {
+	case err != nil:
+		fmt.Fprintf(out, "failed to list devices: %v\n\n", err)
+	case listing == "":
+		fmt.Fprintln(out, "no output")
+		fmt.Fprintln(out)
+	default:
+		fmt.Fprintln(out, listing)
+		fmt.Fprintln(out)
+	}
+}
+
+func writeDeviceTable(out io.Writer, devices []record.Device) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tKIND\tDEFAULT\tCHANNELS")
+	for _, device := range devices {
+		isDefault := ""
+		if device.Default {
+			isDefault = "yes"
+		}
+		channels := "-"
+		if device.Channels > 0 {
+			channels = fmt.Sprint(device.Channels)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", device.ID, device.Name, device.Kind, isDefault, channels)
+	}
+	return w.Flush()
+}
+
+// writeDevicesJSON prints the devices of every available backend that can
+// enumerate them. Listing failures are reported on stderr so stdout stays
+// valid JSON.
+func writeDevicesJSON(cmd *cobra.Command, backends []record.Backend) error {
+	all := []record.Device{}
+	for _, backend := range backends {
+		if !backend.Available() {
+			continue
+		}
+		devices, err := record.Devices(cmd.Context(), backend)
+		if errors.Is(err, record.ErrNoDeviceList) {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s: failed to list devices: %v\n", backend.Name(), err)
+			continue
+		}
+		all = append(all, devices...)
+	}
+
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(all)
 }
@@ -3,17 +3,20 @@
 package cli
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/fmueller/voxclip/internal/record"
 	"github.com/stretchr/testify/require"
 )
 
 func TestDevicesEndToEndAllBackends(t *testing.T) {
 	stubDir := t.TempDir()
 	writeStub(t, stubDir, "pw-record", "#!/bin/sh\nexit 0\n")
-	writeStub(t, stubDir, "pw-cli", "#!/bin/sh\necho 'id 42, type PipeWire:Interface:Node'\necho 'node.name = \"alsa_input.usb\"'\n")
+	writeStub(t, stubDir, "pw-cli", "#!/bin/sh\necho 'id 42, type PipeWire:Interface:Node'\necho 'node.name = \"alsa_input.usb\"'\necho 'media.class = \"Audio/Source\"'\n")
 	writeStub(t, stubDir, "arecord", stubScript("arecord", "default\nhw:0,0\n"))
 	writeStub(t, stubDir, "ffmpeg", "#!/bin/sh\nexit 0\n")
 	writeStub(t, stubDir, "pactl", "#!/bin/sh\necho '1\talsa_output.pci monitor'\n")
@@ -32,6 +35,28 @@ func TestDevicesEndToEndAllBackends(t *testing.T) {
 	require.Contains(t, stdout, "alsa_output.pci monitor")
 }
 
+func TestDevicesJSON(t *testing.T) {
+	stubDir := t.TempDir()
+	writeStub(t, stubDir, "arecord", stubScript("arecord", "default\n    Default ALSA Output\nhw:CARD=PCH,DEV=0\n    HDA Intel PCH, ALC257 Analog\n"))
+	writeStub(t, stubDir, "pw-record", "#!/bin/sh\nexit 1\n")
+	t.Setenv("PATH", stubDir)
+
+	stdout, stderr, err := runCommand(t, []string{"devices", "--json"})
+	require.NoError(t, err)
+	require.Contains(t, stderr, "pw-record: failed to list devices")
+
+	var devices []record.Device
+	require.NoError(t, json.Unmarshal([]byte(stdout), &devices))
+	require.Equal(t, []record.Device{
+		{Backend: "arecord", ID: "default", Name: "Default ALSA Output", Kind: record.DeviceSource, Default: true},
+		{Backend: "arecord", ID: "hw:CARD=PCH,DEV=0", Name: "HDA Intel PCH, ALC257 Analog", Kind: record.DeviceSource},
+	}, devices)
+
+	stdout, _, err = runCommand(t, []string{"devices"})
+	require.NoError(t, err)
+	require.Regexp(t, `(?m)^hw:CARD=PCH,DEV=0\s+HDA Intel PCH, ALC257 Analog\s+source\s+-$`, stdout)
+}
+
 func TestDevicesEndToEndPartialAvailability(t *testing.T) {
 	stubDir := t.TempDir()
 	writeStub(t, stubDir, "arecord", stubScript("arecord", "default\nhw:0,0\n"))
@@ -64,6 +89,29 @@ func TestDevicesEndToEndCommandFailure(t *testing.T) {
 	require.Contains(t, stdout, "failed to list devices")
 }
 
+func TestDevicesListsConfiguredBackends(t *testing.T) {
+	stubDir := t.TempDir()
+	writeStub(t, stubDir, "jack_capture", "#!/bin/sh\nexit 0\n")
+	writeStub(t, stubDir, "jack_lsp", stubScript("jack_lsp", "system:capture_1\n"))
+	t.Setenv("PATH", stubDir)
+
+	config := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(config, []byte(`{"backends": [
+		{"name": "jack", "record": ["jack_capture", "{output}"], "devices": ["jack_lsp"]}
+	]}`), 0o644))
+	t.Setenv(configPathEnv, config)
+	t.Setenv(configProfileEnv, "")
+
+	stdout, _, err := runCommand(t, []string{"devices"})
+	require.NoError(t, err)
+	require.Contains(t, stdout, "== jack ==\nsystem:capture_1")
+	require.Less(t, strings.Index(stdout, "== sox =="), strings.Index(stdout, "== jack =="))
+
+	require.NoError(t, os.WriteFile(config, []byte(`{"backends": [{"name": "arecord", "record": ["jack_capture", "{output}"]}]}`), 0o644))
+	_, _, err = runCommand(t, []string{"devices"})
+	require.ErrorContains(t, err, `config: backend "arecord": name is already taken`)
+}
+
 func writeStub(t *testing.T, dir, name, content string) {
 	t.Helper()
 	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o755))
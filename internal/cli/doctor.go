@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/fmueller/voxclip/internal/record"
+	"github.com/spf13/cobra"
+)
+
+func newDoctorCmd(app *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check which recording backend, engine, clipboard, typing and notification tools voxclip would use",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CHECK\tSTATUS\tDETAILS")
+
+			if backend, err := record.NewBackend(app.backend, app.backends...); err != nil {
+				fmt.Fprintf(w, "recording\tunavailable\t%v\n", err)
+			} else {
+				fmt.Fprintf(w, "recording\tready\t%s\n", backend.Name())
+			}
+
+			if spec, err := lookupEngine(app.engine); err != nil {
+				fmt.Fprintf(w, "engine\tunavailable\t%v\n", err)
+			} else {
+				status, details := app.engineStatus(cmd.Context(), spec)
+				fmt.Fprintf(w, "engine\t%s\t%s: %s\n", status, spec.name, details)
+			}
+
+			status, details := app.clipboardStatus()
+			fmt.Fprintf(w, "clipboard\t%s\t%s\n", status, details)
+
+			status, details = app.typingStatus()
+			fmt.Fprintf(w, "typing\t%s\t%s\n", status, details)
+
+			status, details = app.notifyStatus()
+			fmt.Fprintf(w, "notifications\t%s\t%s\n", status, details)
+			return w.Flush()
+		},
+	}
+
+	bindLoggingFlags(cmd, app)
+	bindModelFlags(cmd, app)
+	bindEngineFlags(cmd, app)
+	bindRecordingBackendFlags(cmd, app)
+	bindClipboardFlags(cmd, app)
+	bindTypingFlags(cmd, app)
+	bindNotifyToolFlag(cmd, app)
+
+	return cmd
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctorReportsSelectedTools(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("uses the Linux recording backends")
+	}
+
+	binDir := t.TempDir()
+	for _, name := range []string{"arecord", "xsel", "xdotool", "gdbus"} {
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, name), []byte("#!/bin/sh\nexit 0\n"), 0o755))
+	}
+	t.Setenv("PATH", binDir)
+	t.Setenv(remoteAPIKeyEnv, "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data": []}`))
+	}))
+	defer server.Close()
+
+	stdout, _, err := runCommand(t, []string{
+		"doctor",
+		"--engine", "remote", "--remote-url", server.URL + "/v1",
+		"--clipboard-target", "both",
+	})
+	require.NoError(t, err)
+	require.Regexp(t, `recording\s+ready\s+arecord`, stdout)
+	require.Regexp(t, `engine\s+ready\s+remote: uploads audio`, stdout)
+	require.Regexp(t, `clipboard\s+ready\s+xsel \(clipboard, primary\)`, stdout)
+	require.Regexp(t, `typing\s+ready\s+xdotool`, stdout)
+	require.Regexp(t, `notifications\s+ready\s+gdbus`, stdout)
+
+	stdout, _, err = runCommand(t, []string{
+		"doctor",
+		"--engine", "remote", "--remote-url", server.URL + "/v1",
+		"--clipboard-command", "tmux load-buffer -",
+	})
+	require.NoError(t, err)
+	require.Regexp(t, `clipboard\s+ready\s+command "tmux load-buffer -" \(clipboard\)`, stdout)
+}
+
+func TestInvalidClipboardTargetIsRejected(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := runCommand(t, []string{"doctor", "--clipboard-target", "secondary"})
+	require.ErrorContains(t, err, "invalid clipboard options")
+}
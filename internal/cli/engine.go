@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fmueller/voxclip/internal/platform"
+	"github.com/fmueller/voxclip/internal/whisper"
+	"github.com/spf13/cobra"
+)
+
+const (
+	engineBundled = "bundled"
+	engineServer  = "server"
+	engineRemote  = "remote"
+
+	remoteAPIKeyEnv = "VOXCLIP_REMOTE_API_KEY"
+)
+
+// engineSpec describes a transcription engine selectable with --engine.
+// Engines that also implement whisper.Preflighter are checked before
+// recording starts and by "voxclip engines".
+type engineSpec struct {
+	name        string
+	description string
+	// localModel is set for engines that load a ggml model resolved from
+	// --model; voxclip downloads it before transcription if needed.
+	localModel bool
+	// serialized is set for engines that handle one transcription at a
+	// time, so --long-audio does not start parallel chunk workers for them.
+	serialized bool
+	build      func(a *appState) (whisper.Engine, error)
+}
+
+// engineRegistry lists the engines in the order "voxclip engines" shows them.
+// Additional local runtimes plug in by adding an entry here.
+var engineRegistry = []engineSpec{
+	{
+		name:        engineBundled,
+		description: "runs the bundled whisper-cli for each transcription",
+		localModel:  true,
+		build: func(a *appState) (whisper.Engine, error) {
+			return whisper.NewBundledEngine(a.log())
+		},
+	},
+	{
+		name:        engineServer,
+		description: "keeps a whisper-server with the model loaded running in the background",
+		localModel:  true,
+		serialized:  true,
+		build: func(a *appState) (whisper.Engine, error) {
+			stateDir, err := platform.ResolveStateDir()
+			if err != nil {
+				return nil, err
+			}
+			if err := os.MkdirAll(stateDir, 0o755); err != nil {
+				return nil, fmt.Errorf("create state directory %s: %w", stateDir, err)
+			}
+			return whisper.NewServerEngine(a.log(), a.serverAddr, stateDir)
+		},
+	},
+	{
+		name:        engineRemote,
+		description: "uploads audio to an OpenAI-compatible transcription API",
+		build: func(a *appState) (whisper.Engine, error) {
+			opts := a.remote
+			opts.APIKey = a.remoteAPIKey()
+			return whisper.NewRemoteEngine(a.log(), opts)
+		},
+	},
+}
+
+func engineNames() []string {
+	names := make([]string, 0, len(engineRegistry))
+	for _, spec := range engineRegistry {
+		names = append(names, spec.name)
+	}
+	return names
+}
+
+func lookupEngine(name string) (engineSpec, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = engineBundled
+	}
+	for _, spec := range engineRegistry {
+		if spec.name == name {
+			return spec, nil
+		}
+	}
+	return engineSpec{}, fmt.Errorf("unknown engine %q (known engines: %s)", name, strings.Join(engineNames(), ", "))
+}
+
+func bindEngineFlags(cmd *cobra.Command, app *appState) {
+	cmd.Flags().StringVar(&app.engine, "engine", app.engine, fmt.Sprintf("Transcription engine (%s); run \"voxclip engines\" to check them", strings.Join(engineNames(), "|")))
+	cmd.Flags().StringVar(&app.serverAddr, "server-addr", app.serverAddr, "Listen address of the whisper-server used by --engine server")
+	cmd.Flags().StringVar(&app.remote.BaseURL, "remote-url", app.remote.BaseURL, "Base URL of the OpenAI-compatible API used by --engine remote, e.g. https://gpu-box.example/v1")
+	cmd.Flags().StringVar(&app.remote.Model, "remote-model", app.remote.Model, "Model name sent to the remote transcription API")
+	cmd.Flags().DurationVar(&app.remote.Timeout, "remote-timeout", app.remote.Timeout, "Timeout for each remote transcription attempt")
+	cmd.Flags().IntVar(&app.remote.Retries, "remote-retries", app.remote.Retries, "Retries after network errors, 429 and 5xx responses from the remote API")
+}
+
+func (a *appState) newEngine() (whisper.Engine, error) {
+	spec, err := lookupEngine(a.engine)
+	if err != nil {
+		return nil, err
+	}
+	return spec.build(a)
+}
+
+// usesLocalModel reports whether the selected engine needs a local ggml model;
+// the remote engine transcribes with a model on the server side.
+func (a *appState) usesLocalModel() bool {
+	spec, err := lookupEngine(a.engine)
+	return err != nil || spec.localModel
+}
+
+// preflightEngine builds the engine and runs its preflight check against
+// modelPath, which is empty for engines without a local model.
+func (a *appState) preflightEngine(ctx context.Context, spec engineSpec, modelPath string) error {
+	engine, err := spec.build(a)
+	if err != nil {
+		return err
+	}
+	if checker, ok := engine.(whisper.Preflighter); ok {
+		return checker.Preflight(ctx, modelPath)
+	}
+	return nil
+}
+
+// remoteAPIKey prefers VOXCLIP_REMOTE_API_KEY over the config file so keys
+// never have to appear on the command line.
+func (a *appState) remoteAPIKey() string {
+	if key := strings.TrimSpace(os.Getenv(remoteAPIKeyEnv)); key != "" {
+		return key
+	}
+	return a.config.Remote.APIKey
+}
+
+func newEnginesCmd(app *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "engines",
+		Short: "List transcription engines and whether they are ready to use",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ENGINE\tSTATUS\tDETAILS")
+			for _, spec := range engineRegistry {
+				name := "  " + spec.name
+				if selected, err := lookupEngine(app.engine); err == nil && selected.name == spec.name {
+					name = "* " + spec.name
+				}
+				status, details := app.engineStatus(cmd.Context(), spec)
+				fmt.Fprintf(w, "%s\t%s\t%s\n", name, status, details)
+			}
+			return w.Flush()
+		},
+	}
+
+	bindLoggingFlags(cmd, app)
+	bindModelFlags(cmd, app)
+	bindEngineFlags(cmd, app)
+
+	return cmd
+}
+
+// engineStatus checks an engine without downloading anything. A local model
+// that is not downloaded yet does not make an engine unavailable, since
+// transcription fetches it on demand.
+func (a *appState) engineStatus(ctx context.Context, spec engineSpec) (string, string) {
+	var modelPath string
+	if spec.localModel {
+		resolved, err := a.resolveModel()
+		if err != nil {
+			return "unavailable", err.Error()
+		}
+		if resolved.NeedsDownload {
+			if _, err := spec.build(a); err != nil {
+				return "unavailable", err.Error()
+			}
+			return "ready", fmt.Sprintf("%s; model %s is downloaded on first use", spec.description, resolved.Name)
+		}
+		modelPath = resolved.Path
+	}
+
+	if err := a.preflightEngine(ctx, spec, modelPath); err != nil {
+		return "unavailable", err.Error()
+	}
+	return "ready", spec.description
+}
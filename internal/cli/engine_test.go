@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fmueller/voxclip/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEngineRejectsUnknownEngine(t *testing.T) {
+	t.Parallel()
+
+	app := &appState{engine: "cloud"}
+	_, err := app.newEngine()
+	require.ErrorContains(t, err, `unknown engine "cloud"`)
+}
+
+func TestTranscribeWithRemoteEngine(t *testing.T) {
+	t.Setenv(remoteAPIKeyEnv, "sk-test")
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"text": "hello from the gpu box", "language": "english"}`))
+	}))
+	defer server.Close()
+
+	samples := make([]int16, 16000)
+	for i := range samples {
+		samples[i] = 8000
+	}
+	audioPath := filepath.Join(t.TempDir(), "audio.wav")
+	require.NoError(t, os.WriteFile(audioPath, makePCM16WAVForTest(samples, 16000, 1), 0o644))
+	modelDir := t.TempDir()
+
+	stdout, _, err := runCommand(t, []string{
+		"transcribe", "--no-progress",
+		"--engine", "remote", "--remote-url", server.URL + "/v1",
+		"--model-dir", modelDir,
+		audioPath,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "hello from the gpu box\n", stdout)
+	require.Equal(t, "Bearer sk-test", authorization)
+
+	entries, err := os.ReadDir(modelDir)
+	require.NoError(t, err)
+	require.Empty(t, entries, "remote engine must not download a local model")
+}
+
+func TestRemoteAPIKeyFallsBackToConfig(t *testing.T) {
+	t.Setenv(remoteAPIKeyEnv, "")
+
+	app := &appState{config: config.File{Remote: config.Remote{APIKey: "from-config"}}}
+	require.Equal(t, "from-config", app.remoteAPIKey())
+
+	t.Setenv(remoteAPIKeyEnv, "from-env")
+	require.Equal(t, "from-env", app.remoteAPIKey())
+}
+
+func TestEnginesCommandReportsStatus(t *testing.T) {
+	stubDir := t.TempDir()
+	for _, name := range []string{"whisper-cli", "whisper-server"} {
+		require.NoError(t, os.WriteFile(filepath.Join(stubDir, name), []byte("#!/bin/sh\nexit 0\n"), 0o755))
+	}
+	t.Setenv("VOXCLIP_WHISPER_PATH", filepath.Join(stubDir, "whisper-cli"))
+	t.Setenv("VOXCLIP_WHISPER_SERVER_PATH", filepath.Join(stubDir, "whisper-server"))
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv(remoteAPIKeyEnv, "")
+
+	modelPath := filepath.Join(t.TempDir(), "ggml-custom.bin")
+	require.NoError(t, os.WriteFile(modelPath, []byte("lmgg\x00\x00\x00\x00"), 0o644))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	stdout, _, err := runCommand(t, []string{
+		"engines", "--model", modelPath,
+		"--server-addr", "127.0.0.1:1",
+		"--remote-url", server.URL + "/v1",
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 4)
+	require.Regexp(t, `^ENGINE\s+STATUS\s+DETAILS$`, lines[0])
+	require.Regexp(t, `^\* bundled\s+ready\s+`, lines[1])
+	require.Regexp(t, `^  server\s+ready\s+`, lines[2])
+	require.Regexp(t, `^  remote\s+unavailable\s+.*rejected the API key`, lines[3])
+}
+
+func TestEnsureTranscriptionReadyRejectsIncompatibleModel(t *testing.T) {
+	stubDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(stubDir, "whisper-cli"), []byte("#!/bin/sh\nexit 0\n"), 0o755))
+	t.Setenv("VOXCLIP_WHISPER_PATH", filepath.Join(stubDir, "whisper-cli"))
+
+	modelPath := filepath.Join(t.TempDir(), "model.gguf")
+	require.NoError(t, os.WriteFile(modelPath, []byte("GGUF\x03\x00\x00\x00"), 0o644))
+
+	app := &appState{engine: engineBundled, model: modelPath}
+	err := app.ensureTranscriptionReady(context.Background())
+	require.ErrorContains(t, err, "not a whisper.cpp ggml model")
+}
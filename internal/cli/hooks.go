@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/fmueller/voxclip/internal/hooks"
+	"go.uber.org/zap"
+)
+
+func (a *appState) hookVars() hooks.Vars {
+	return hooks.Vars{Model: a.model, Engine: a.engine}
+}
+
+func (a *appState) runRecordStartHook(ctx context.Context, audioPath string) {
+	vars := a.hookVars()
+	vars.AudioPath = audioPath
+	a.hooks.Run(ctx, hooks.RecordStart, vars)
+}
+
+func (a *appState) runRecordStopHook(ctx context.Context, audioPath string) {
+	if a.hooks == nil {
+		return
+	}
+	vars := a.hookVars()
+	vars.AudioPath = audioPath
+	if d, err := audio.WAVDuration(audioPath); err == nil {
+		vars.AudioDuration = d
+	}
+	a.hooks.Run(ctx, hooks.RecordStop, vars)
+}
+
+// runTranscribeDoneHook hands the transcript to the hook in a temporary file,
+// which is removed once the hook has finished.
+func (a *appState) runTranscribeDoneHook(ctx context.Context, audioPath, transcript string, elapsed time.Duration) {
+	if a.hooks == nil {
+		return
+	}
+
+	vars := a.hookVars()
+	vars.TranscribeDuration = elapsed
+	if audioPath != "" {
+		vars.AudioPath = audioPath
+		if d, err := audio.WAVDuration(audioPath); err == nil {
+			vars.AudioDuration = d
+		}
+	}
+
+	f, err := os.CreateTemp("", "voxclip-transcript-*.txt")
+	if err != nil {
+		a.log().Warn("failed to write transcript for hook", zap.Error(err))
+	} else {
+		defer os.Remove(f.Name())
+		_, err = f.WriteString(transcript + "\n")
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			a.log().Warn("failed to write transcript for hook", zap.Error(err))
+		} else {
+			vars.TranscriptFile = f.Name()
+		}
+	}
+
+	a.hooks.Run(ctx, hooks.TranscribeDone, vars)
+}
+
+func (a *appState) runErrorHook(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	vars := a.hookVars()
+	vars.Error = err.Error()
+	a.hooks.Run(ctx, hooks.Error, vars)
+}
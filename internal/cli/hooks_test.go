@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/fmueller/voxclip/internal/config"
+	"github.com/fmueller/voxclip/internal/hooks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRunDefaultRunsHooksWithoutAbortingOnFailure(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks run through sh")
+	}
+
+	dir := t.TempDir()
+	runner, err := hooks.New(config.Hooks{
+		OnTranscribeDone: `cat "$VOXCLIP_TRANSCRIPT_FILE" > ` + filepath.Join(dir, "done.txt") + `; echo "$VOXCLIP_MODEL $VOXCLIP_AUDIO_PATH" >> ` + filepath.Join(dir, "done.txt") + `; exit 1`,
+		OnError:          `echo "$VOXCLIP_ERROR" > ` + filepath.Join(dir, "error.txt"),
+	}, zap.NewNop())
+	require.NoError(t, err)
+
+	audioFile := filepath.Join(dir, "audio.wav")
+	var copied string
+	app := &appState{
+		out:         new(bytes.Buffer),
+		model:       "small",
+		hooks:       runner,
+		preflightFn: noopLivePreflight,
+		recordFn: func(context.Context, recordOptions) (string, error) {
+			return audioFile, os.WriteFile(audioFile, []byte("fake"), 0o644)
+		},
+		transcribeFn: func(context.Context, string) (string, error) {
+			return "hello world", nil
+		},
+		copyFn: func(_ context.Context, value string) error {
+			copied = value
+			return nil
+		},
+	}
+
+	require.NoError(t, app.runDefault(context.Background()), "a failing hook must not fail the run")
+	require.Equal(t, "hello world", copied)
+	done, err := os.ReadFile(filepath.Join(dir, "done.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "hello world\nsmall "+audioFile+"\n", string(done))
+	require.NoFileExists(t, filepath.Join(dir, "error.txt"))
+
+	app.transcribeFn = func(context.Context, string) (string, error) {
+		return "", errors.New("engine crashed")
+	}
+	require.ErrorContains(t, app.runDefault(context.Background()), "engine crashed")
+	reported, err := os.ReadFile(filepath.Join(dir, "error.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "engine crashed\n", string(reported))
+}
@@ -0,0 +1,341 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+)
+
+const (
+	defaultLiveWindow     = 3 * time.Second
+	defaultLiveMaxSegment = 20 * time.Second
+	defaultLivePauseDBFS  = -40
+	// livePause is how much quiet audio ends a segment early, so segments
+	// are finalized between sentences rather than in the middle of a word.
+	livePause = 600 * time.Millisecond
+)
+
+type liveOptions struct {
+	window     time.Duration
+	maxSegment time.Duration
+	pauseDBFS  float64
+}
+
+func newLiveCmd(app *appState) *cobra.Command {
+	opts := liveOptions{
+		window:     defaultLiveWindow,
+		maxSegment: defaultLiveMaxSegment,
+		pauseDBFS:  defaultLivePauseDBFS,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "live",
+		Short: "Record and transcribe continuously, printing text while you speak",
+		Long: `Record and transcribe continuously, printing text while you speak.
+
+Every --window of new audio, the segment spoken so far is transcribed again
+and shown as partial text on a terminal. A segment is finalized and printed
+as a line when you pause or when it reaches --max-segment. When recording
+stops, the finalized text is copied to the clipboard, or sent to the
+--output-to outputs.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return app.runLive(cmd.Context(), opts)
+		},
+	}
+
+	bindLoggingFlags(cmd, app)
+	bindModelFlags(cmd, app)
+	bindLanguageAndModelDownloadFlags(cmd, app)
+	bindEngineFlags(cmd, app)
+	bindDecodingFlags(cmd, app)
+	bindRecordingBackendFlags(cmd, app)
+	bindCopyAndSilenceFlags(cmd, app)
+	bindClipboardFlags(cmd, app)
+	bindOutputFlags(cmd, app, "clipboard")
+	bindNotifyFlags(cmd, app)
+	cmd.Flags().DurationVar(&app.duration, "duration", 0, "Record duration, e.g. 5m; 0 means interactive start/stop")
+	cmd.Flags().BoolVar(&app.immediate, "immediate", false, "Start recording immediately without waiting for Enter")
+	cmd.Flags().StringVar(&app.pidFile, "pid-file", "", "Write PID to file and wait for SIGUSR1 to stop recording; SIGUSR2 pauses and resumes")
+	cmd.Flags().DurationVar(&opts.window, "window", opts.window, "New audio collected before the current segment is transcribed again")
+	cmd.Flags().DurationVar(&opts.maxSegment, "max-segment", opts.maxSegment, "Longest segment before it is finalized without a pause")
+	cmd.Flags().Float64Var(&opts.pauseDBFS, "pause-threshold-dbfs", opts.pauseDBFS, "Level in dBFS below which audio counts as a pause that ends a segment")
+	return cmd
+}
+
+type liveAudio struct {
+	format audio.Format
+	pcm    []byte
+}
+
+func (a *appState) runLive(ctx context.Context, opts liveOptions) (err error) {
+	defer func() { a.reportFailure(ctx, err) }()
+
+	if opts.window <= 0 {
+		return fmt.Errorf("--window must be positive, got %s", opts.window)
+	}
+	if opts.maxSegment < opts.window {
+		return fmt.Errorf("--max-segment must be at least --window (%s), got %s", opts.window, opts.maxSegment)
+	}
+
+	preflightFn := a.preflightFn
+	if preflightFn == nil {
+		preflightFn = a.ensureTranscriptionReady
+	}
+
+	recordFn := a.recordFn
+	if recordFn == nil {
+		recordFn = a.recordAudio
+	}
+
+	transcribeFn := a.transcribeFn
+	if transcribeFn == nil {
+		transcribeFn = a.transcribeAudio
+	}
+
+	// Finalized text is printed as it is recognized, so a stdout output
+	// would print the transcript twice.
+	sinks, err := a.outputSinks(outputClipboard)
+	if err != nil {
+		return err
+	}
+	sinks = withoutStdout(sinks)
+
+	// Live text owns the terminal; per-window progress bars would garble it.
+	a.noProgress = true
+
+	if err := preflightFn(ctx); err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "voxclip-live-*")
+	if err != nil {
+		return fmt.Errorf("create live directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	recordCtx, stopRecording := context.WithCancel(ctx)
+	defer stopRecording()
+
+	session := &liveSession{
+		opts:       opts,
+		dir:        dir,
+		transcribe: transcribeFn,
+		printer:    newLivePrinter(a.outWriter()),
+		logger:     a.log(),
+	}
+	audioCh := make(chan liveAudio, 16)
+	sessionDone := make(chan error, 1)
+	go func() {
+		sessionDone <- session.run(ctx, audioCh, stopRecording)
+	}()
+
+	audioPath, recordErr := recordFn(recordCtx, recordOptions{
+		duration: a.duration,
+		input:    a.input,
+		format:   a.inputFormat,
+		onAudio: func(format audio.Format, pcm []byte) {
+			audioCh <- liveAudio{format: format, pcm: pcm}
+		},
+	})
+	close(audioCh)
+	sessionErr := <-sessionDone
+
+	if audioPath != "" {
+		if err := os.Remove(audioPath); err != nil && !os.IsNotExist(err) {
+			a.log().Warn("failed to remove recording", zap.String("path", audioPath), zap.Error(err))
+		}
+	}
+	if sessionErr != nil {
+		return sessionErr
+	}
+	if recordErr != nil {
+		return recordErr
+	}
+
+	a.runTranscribeDoneHook(ctx, "", session.transcript(), 0)
+	_ = a.deliverTranscript(ctx, sinks, session.transcript())
+	return nil
+}
+
+// liveSession turns a stream of recorded audio into partial and finalized
+// text. The audio of the segment in progress is kept in memory and written
+// to a WAV file whenever it is transcribed.
+type liveSession struct {
+	opts       liveOptions
+	dir        string
+	transcribe func(ctx context.Context, audioPath string) (string, error)
+	printer    *livePrinter
+	logger     *zap.Logger
+
+	format audio.Format
+	// pending is the audio of the segment that has not been finalized yet.
+	pending []byte
+	// fresh counts the bytes of pending received since it was last
+	// transcribed.
+	fresh  int
+	finals []string
+	runs   int
+}
+
+// run consumes audio until audioCh is closed. After a transcription error it
+// stops the recording through stop but keeps draining audioCh so the
+// recorder is never blocked.
+func (s *liveSession) run(ctx context.Context, audioCh <-chan liveAudio, stop func()) error {
+	var err error
+	for chunk := range audioCh {
+		s.append(chunk)
+		// Catch up with audio that arrived while the last window was being
+		// transcribed, so a slow engine skips stale partials instead of
+		// falling further behind.
+	drain:
+		for {
+			select {
+			case more, ok := <-audioCh:
+				if !ok {
+					break drain
+				}
+				s.append(more)
+			default:
+				break drain
+			}
+		}
+
+		if err == nil {
+			if err = s.update(ctx); err != nil {
+				stop()
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return s.finish(ctx)
+}
+
+func (s *liveSession) append(chunk liveAudio) {
+	s.format = chunk.format
+	s.pending = append(s.pending, chunk.pcm...)
+	s.fresh += len(chunk.pcm)
+}
+
+func (s *liveSession) update(ctx context.Context) error {
+	if s.format.Duration(s.fresh) < s.opts.window {
+		return nil
+	}
+	s.fresh = 0
+
+	if s.quiet(s.pending) {
+		// Nothing has been said since the last segment ended.
+		s.pending = s.pending[:0]
+		return nil
+	}
+	tail := s.pending[max(len(s.pending)-s.format.Bytes(livePause), 0):]
+	if s.format.Duration(len(s.pending)) >= s.opts.maxSegment || s.quiet(tail) {
+		return s.finalize(ctx)
+	}
+
+	text, err := s.transcribeAudio(ctx, s.pending)
+	if err != nil {
+		return err
+	}
+	s.printer.partial(text)
+	return nil
+}
+
+// finish finalizes the segment in progress once recording has stopped.
+func (s *liveSession) finish(ctx context.Context) error {
+	if len(s.pending) == 0 || s.quiet(s.pending) {
+		s.printer.partial("")
+		return nil
+	}
+	return s.finalize(ctx)
+}
+
+func (s *liveSession) finalize(ctx context.Context) error {
+	text, err := s.transcribeAudio(ctx, s.pending)
+	if err != nil {
+		return err
+	}
+	s.pending = s.pending[:0]
+	s.fresh = 0
+
+	if text == "" {
+		s.printer.partial("")
+		return nil
+	}
+	s.finals = append(s.finals, text)
+	s.printer.final(text)
+	return nil
+}
+
+func (s *liveSession) quiet(pcm []byte) bool {
+	metrics, err := audio.MeasurePCM(s.format, pcm)
+	if err != nil {
+		s.logger.Debug("measure live audio level", zap.Error(err))
+		return false
+	}
+	return metrics.RMSdBFS <= s.opts.pauseDBFS
+}
+
+// transcribeAudio transcribes pcm and returns its text, or "" when nothing
+// was recognized.
+func (s *liveSession) transcribeAudio(ctx context.Context, pcm []byte) (string, error) {
+	s.runs++
+	path := filepath.Join(s.dir, fmt.Sprintf("window-%04d.wav", s.runs))
+	if err := audio.WriteWAV(path, s.format, pcm); err != nil {
+		return "", err
+	}
+	defer os.Remove(path)
+
+	text, err := s.transcribe(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if isBlankTranscript(text) {
+		return "", nil
+	}
+	return strings.TrimSpace(text), nil
+}
+
+func (s *liveSession) transcript() string {
+	return strings.Join(s.finals, " ")
+}
+
+// livePrinter writes finalized segments as lines. On a terminal, partial text
+// is shown on the current line and replaced as it changes; elsewhere partials
+// are left out so piped output only contains final text.
+type livePrinter struct {
+	w       io.Writer
+	inPlace bool
+	shown   bool
+}
+
+func newLivePrinter(w io.Writer) *livePrinter {
+	f, ok := w.(*os.File)
+	return &livePrinter{w: w, inPlace: ok && term.IsTerminal(int(f.Fd()))}
+}
+
+func (p *livePrinter) partial(text string) {
+	if !p.inPlace || (text == "" && !p.shown) {
+		return
+	}
+	fmt.Fprint(p.w, "\r\033[K"+text)
+	p.shown = text != ""
+}
+
+func (p *livePrinter) final(text string) {
+	if p.inPlace && p.shown {
+		fmt.Fprint(p.w, "\r\033[K")
+	}
+	fmt.Fprintln(p.w, text)
+	p.shown = false
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var liveTestFormat = audio.Format{SampleRate: 16000, Channels: 1, BitsPerSample: 16}
+
+// liveTestAudio returns one second of silence, three seconds of "alpha", a
+// one-second pause and two seconds of "beta". The words are told apart by
+// their amplitude.
+func liveTestAudio() []byte {
+	var pcm []byte
+	appendTone := func(d time.Duration, amplitude int16) {
+		for i := range liveTestFormat.Bytes(d) / 2 {
+			sample := amplitude
+			if i%2 == 1 {
+				sample = -amplitude
+			}
+			pcm = binary.LittleEndian.AppendUint16(pcm, uint16(sample))
+		}
+	}
+	appendTone(time.Second, 0)
+	appendTone(3*time.Second, 8000)
+	appendTone(time.Second, 0)
+	appendTone(2*time.Second, 12000)
+	return pcm
+}
+
+// fakeLiveTranscribe names the words whose amplitude occurs in the WAV.
+func fakeLiveTranscribe(_ context.Context, audioPath string) (string, error) {
+	f, err := os.Open(audioPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	_, offset, err := audio.ReadFormat(f)
+	if err != nil {
+		return "", err
+	}
+	raw, err := os.ReadFile(audioPath)
+	if err != nil {
+		return "", err
+	}
+
+	var alpha, beta bool
+	for off := int(offset); off+2 <= len(raw); off += 2 {
+		switch int16(binary.LittleEndian.Uint16(raw[off:])) {
+		case 8000:
+			alpha = true
+		case 12000:
+			beta = true
+		}
+	}
+	var words []string
+	if alpha {
+		words = append(words, "alpha")
+	}
+	if beta {
+		words = append(words, "beta")
+	}
+	if len(words) == 0 {
+		return blankAudioToken, nil
+	}
+	return strings.Join(words, " "), nil
+}
+
+func TestLiveSessionPrintsPartialsAndFinalizesAtPauses(t *testing.T) {
+	t.Parallel()
+
+	out := new(bytes.Buffer)
+	var calls int
+	session := &liveSession{
+		opts: liveOptions{window: time.Second, maxSegment: 10 * time.Second, pauseDBFS: defaultLivePauseDBFS},
+		dir:  t.TempDir(),
+		transcribe: func(ctx context.Context, audioPath string) (string, error) {
+			calls++
+			return fakeLiveTranscribe(ctx, audioPath)
+		},
+		printer: &livePrinter{w: out, inPlace: true},
+		logger:  zap.NewNop(),
+	}
+
+	pcm := liveTestAudio()
+	chunk := liveTestFormat.Bytes(250 * time.Millisecond)
+	for off := 0; off < len(pcm); off += chunk {
+		session.append(liveAudio{format: liveTestFormat, pcm: pcm[off:min(off+chunk, len(pcm))]})
+		require.NoError(t, session.update(context.Background()))
+	}
+	require.NoError(t, session.finish(context.Background()))
+
+	erase := "\r\033[K"
+	require.Equal(t,
+		erase+"alpha"+erase+"alpha"+erase+"alpha"+erase+"alpha\n"+
+			erase+"beta"+erase+"beta"+erase+"beta\n",
+		out.String())
+	require.Equal(t, "alpha beta", session.transcript())
+	require.Equal(t, 7, calls, "leading silence must not be transcribed")
+}
+
+func TestLiveSessionFinalizesLongSegmentsWithoutPause(t *testing.T) {
+	t.Parallel()
+
+	out := new(bytes.Buffer)
+	session := &liveSession{
+		opts:       liveOptions{window: time.Second, maxSegment: 2 * time.Second, pauseDBFS: defaultLivePauseDBFS},
+		dir:        t.TempDir(),
+		transcribe: fakeLiveTranscribe,
+		printer:    &livePrinter{w: out},
+		logger:     zap.NewNop(),
+	}
+
+	pcm := liveTestAudio()
+	pcm = pcm[liveTestFormat.Bytes(time.Second):liveTestFormat.Bytes(4*time.Second)]
+	for off := 0; off < len(pcm); off += liveTestFormat.Bytes(time.Second) {
+		session.append(liveAudio{format: liveTestFormat, pcm: pcm[off : off+liveTestFormat.Bytes(time.Second)]})
+		require.NoError(t, session.update(context.Background()))
+	}
+	require.NoError(t, session.finish(context.Background()))
+
+	require.Equal(t, "alpha\nalpha\n", out.String(), "partials are only shown on a terminal")
+}
+
+func TestRunLiveCopiesFinalTranscript(t *testing.T) {
+	t.Parallel()
+
+	out := new(bytes.Buffer)
+	var copied string
+	app := &appState{
+		out:         out,
+		preflightFn: noopLivePreflight,
+		recordFn: func(_ context.Context, opts recordOptions) (string, error) {
+			pcm := liveTestAudio()
+			chunk := liveTestFormat.Bytes(100 * time.Millisecond)
+			for off := 0; off < len(pcm); off += chunk {
+				opts.onAudio(liveTestFormat, pcm[off:min(off+chunk, len(pcm))])
+			}
+			return "", nil
+		},
+		transcribeFn: fakeLiveTranscribe,
+		copyFn: func(_ context.Context, value string) error {
+			copied = value
+			return nil
+		},
+	}
+
+	err := app.runLive(context.Background(), liveOptions{window: time.Second, maxSegment: 10 * time.Second, pauseDBFS: defaultLivePauseDBFS})
+	require.NoError(t, err)
+	require.Equal(t, "alpha beta", copied)
+	require.Equal(t, "alpha beta", strings.Join(strings.Fields(out.String()), " "))
+}
+
+func TestRunLiveStopsRecordingWhenTranscriptionFails(t *testing.T) {
+	t.Parallel()
+
+	app := &appState{
+		out:         new(bytes.Buffer),
+		preflightFn: noopLivePreflight,
+		recordFn: func(ctx context.Context, opts recordOptions) (string, error) {
+			pcm := liveTestAudio()
+			chunk := liveTestFormat.Bytes(100 * time.Millisecond)
+			for off := 0; off < len(pcm); off += chunk {
+				opts.onAudio(liveTestFormat, pcm[off:min(off+chunk, len(pcm))])
+			}
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+		transcribeFn: func(context.Context, string) (string, error) {
+			return "", errors.New("engine crashed")
+		},
+		copyFn: func(context.Context, string) error {
+			t.Fatal("nothing should be copied after a failure")
+			return nil
+		},
+	}
+
+	err := app.runLive(context.Background(), liveOptions{window: time.Second, maxSegment: 10 * time.Second, pauseDBFS: defaultLivePauseDBFS})
+	require.ErrorContains(t, err, "engine crashed")
+}
+
+func TestRunLiveValidatesWindows(t *testing.T) {
+	t.Parallel()
+
+	app := &appState{preflightFn: noopLivePreflight}
+	require.ErrorContains(t, app.runLive(context.Background(), liveOptions{}), "--window")
+	require.ErrorContains(t, app.runLive(context.Background(), liveOptions{window: 5 * time.Second, maxSegment: time.Second}), "--max-segment")
+}
+
+func noopLivePreflight(context.Context) error { return nil }
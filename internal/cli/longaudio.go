@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/fmueller/voxclip/internal/whisper"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// defaultThreadsPerWorker mirrors whisper-cli's default of at most four
+// threads and is used to size the worker pool when --threads is unset.
+const defaultThreadsPerWorker = 4
+
+type longAudioOptions struct {
+	enabled      bool
+	chunkLength  time.Duration
+	chunkOverlap time.Duration
+	workers      int
+}
+
+func bindLongAudioFlags(cmd *cobra.Command, app *appState) {
+	o := &app.longAudio
+	cmd.Flags().BoolVar(&o.enabled, "long-audio", o.enabled, "Split long recordings at pauses and transcribe the chunks in parallel")
+	cmd.Flags().DurationVar(&o.chunkLength, "chunk-length", o.chunkLength, "Target chunk length for --long-audio")
+	cmd.Flags().DurationVar(&o.chunkOverlap, "chunk-overlap", o.chunkOverlap, "Audio shared by neighbouring chunks for --long-audio")
+	cmd.Flags().IntVar(&o.workers, "chunk-workers", o.workers, "Chunks transcribed at the same time for --long-audio; 0 picks a value from the CPU count")
+}
+
+// chunkWorkers returns how many chunks are transcribed concurrently. Local
+// engines share the CPU, so the automatic budget divides the cores by the
+// threads each whisper run uses.
+func (a *appState) chunkWorkers(spec engineSpec) int {
+	if a.longAudio.workers > 0 {
+		return a.longAudio.workers
+	}
+	if spec.serialized {
+		return 1
+	}
+	if !spec.localModel {
+		return runtime.NumCPU()
+	}
+	threads := a.decoding.Threads
+	if threads <= 0 {
+		threads = defaultThreadsPerWorker
+	}
+	return max(runtime.NumCPU()/threads, 1)
+}
+
+// transcribeLongAudio splits audioPath into chunks and transcribes them in
+// parallel. ok is false when the recording fits into a single chunk, in which
+// case the caller transcribes it as usual.
+func (a *appState) transcribeLongAudio(ctx context.Context, engine whisper.Engine, req whisper.TranscriptionRequest) (whisper.Transcription, bool, error) {
+	if a.longAudio.workers < 0 {
+		return whisper.Transcription{}, false, fmt.Errorf("--chunk-workers must not be negative, got %d", a.longAudio.workers)
+	}
+	spec, err := lookupEngine(a.engine)
+	if err != nil {
+		return whisper.Transcription{}, false, err
+	}
+
+	dir, err := os.MkdirTemp("", "voxclip-chunks-*")
+	if err != nil {
+		return whisper.Transcription{}, false, fmt.Errorf("create chunk directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	chunks, err := audio.SplitWAV(req.AudioPath, dir, audio.SplitOptions{
+		ChunkDuration: a.longAudio.chunkLength,
+		Overlap:       a.longAudio.chunkOverlap,
+	})
+	if err != nil {
+		return whisper.Transcription{}, false, fmt.Errorf("split audio: %w", err)
+	}
+	if len(chunks) < 2 {
+		return whisper.Transcription{}, false, nil
+	}
+
+	workers := a.chunkWorkers(spec)
+	a.log().Info("transcribing in chunks", zap.Int("chunks", len(chunks)), zap.Int("workers", workers))
+
+	reportProgress, stopProgress := startPercentProgress(os.Stderr, a.progressEnabled(), "Transcribing")
+	defer stopProgress()
+	if a.progressEnabled() {
+		req.Progress = reportProgress
+	}
+
+	chunkEngine := whisper.EngineFunc(func(ctx context.Context, chunkReq whisper.TranscriptionRequest) (whisper.Transcription, error) {
+		if reporter, ok := engine.(whisper.ProgressReporter); !ok || !reporter.ReportsProgress() {
+			chunkReq.Progress = nil
+		}
+		return whisper.TranscribeWithAllowedLanguages(ctx, engine, chunkReq, a.languages, a.log())
+	})
+	result, err := whisper.TranscribeChunks(ctx, chunkEngine, req, chunks, workers)
+	return result, true, err
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fmueller/voxclip/internal/whisper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscribeLongAudioStitchesChunksInOrder(t *testing.T) {
+	t.Setenv(remoteAPIKeyEnv, "")
+
+	// Three ten-second sections at different levels separated by pauses, so
+	// the uploaded chunk can be recognised by its loudest sample.
+	const rate = 8000
+	var samples []int16
+	for part := 1; part <= 3; part++ {
+		for i := 0; i < 9*rate; i++ {
+			samples = append(samples, int16(part*1000))
+		}
+		samples = append(samples, make([]int16, rate)...)
+	}
+	audioPath := filepath.Join(t.TempDir(), "meeting.wav")
+	require.NoError(t, os.WriteFile(audioPath, makePCM16WAVForTest(samples, rate, 1), 0o644))
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		raw, err := io.ReadAll(file)
+		if err != nil || len(raw) < 44 {
+			http.Error(w, "short upload", http.StatusBadRequest)
+			return
+		}
+
+		var loudest int16
+		for off := 44; off+2 <= len(raw); off += 2 {
+			loudest = max(loudest, int16(binary.LittleEndian.Uint16(raw[off:])))
+		}
+		fmt.Fprintf(w, `{"text": "part %d", "language": "english"}`, loudest/1000)
+	}))
+	defer server.Close()
+
+	stdout, _, err := runCommand(t, []string{
+		"transcribe", "--no-progress",
+		"--engine", "remote", "--remote-url", server.URL + "/v1",
+		"--long-audio", "--chunk-length", "10s", "--chunk-overlap", "200ms", "--chunk-workers", "3",
+		audioPath,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "part 1 part 2 part 3\n", stdout)
+	require.EqualValues(t, 3, requests.Load())
+}
+
+func TestChunkWorkers(t *testing.T) {
+	t.Parallel()
+
+	bundled, err := lookupEngine(engineBundled)
+	require.NoError(t, err)
+	server, err := lookupEngine(engineServer)
+	require.NoError(t, err)
+	remote, err := lookupEngine(engineRemote)
+	require.NoError(t, err)
+
+	app := &appState{}
+	require.Equal(t, max(runtime.NumCPU()/defaultThreadsPerWorker, 1), app.chunkWorkers(bundled))
+	require.Equal(t, 1, app.chunkWorkers(server))
+	require.Equal(t, runtime.NumCPU(), app.chunkWorkers(remote))
+
+	app.decoding = whisper.DecodingOptions{Threads: 1}
+	require.Equal(t, runtime.NumCPU(), app.chunkWorkers(bundled))
+
+	app.longAudio.workers = 3
+	require.Equal(t, 3, app.chunkWorkers(server))
+}
@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+)
+
+const (
+	// meterFloorDBFS is the level shown as an empty meter.
+	meterFloorDBFS = -60.0
+	meterWidth     = 20
+	// meterSilenceDBFS is the peak below which the input counts as digital
+	// silence, as from a muted microphone.
+	meterSilenceDBFS = -90.0
+	// meterClipDBFS is the peak at which samples reach full scale.
+	meterClipDBFS = -0.1
+	// meterWarnAfter is how long the input has to stay silent before the
+	// meter warns, and how long a clipping warning is shown.
+	meterWarnAfter = 2 * time.Second
+	meterRefresh   = 100 * time.Millisecond
+)
+
+// levelMeter shows the input level of a recording in progress on one
+// terminal line and warns when the input is silent or clipping, so a muted
+// or wrong microphone is noticed before the transcript comes back blank.
+type levelMeter struct {
+	description string
+	now         func() time.Time
+
+	mu          sync.Mutex
+	rmsDBFS     float64
+	peakDBFS    float64
+	heard       bool
+	silentSince time.Time
+	lastClip    time.Time
+	// paused, when set, is shown instead of the meter.
+	paused string
+}
+
+// startLevelMeter draws a level meter on w until the returned stop function
+// is called. The meter's onAudio receives the recorded audio, e.g. as
+// record.Config.OnAudio. When disabled, the meter is nil.
+func startLevelMeter(w io.Writer, enabled bool, description string) (*levelMeter, stopFunc) {
+	if !enabled {
+		return nil, func() {}
+	}
+
+	m := newLevelMeter(description, time.Now)
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+
+	go func() {
+		defer close(doneCh)
+		ticker := time.NewTicker(meterRefresh)
+		defer ticker.Stop()
+
+		fmt.Fprint(w, "\r\033[K"+m.line())
+		for {
+			select {
+			case <-stopCh:
+				fmt.Fprint(w, "\r\033[K")
+				return
+			case <-ticker.C:
+				fmt.Fprint(w, "\r\033[K"+m.line())
+			}
+		}
+	}()
+
+	var once sync.Once
+	return m, func() {
+		once.Do(func() {
+			close(stopCh)
+			<-doneCh
+		})
+	}
+}
+
+func newLevelMeter(description string, now func() time.Time) *levelMeter {
+	return &levelMeter{
+		description: description,
+		now:         now,
+		rmsDBFS:     math.Inf(-1),
+		peakDBFS:    math.Inf(-1),
+	}
+}
+
+// onAudio measures a piece of recorded audio.
+func (m *levelMeter) onAudio(format audio.Format, pcm []byte) {
+	metrics, err := audio.MeasurePCM(format, pcm)
+	if err != nil || metrics.Samples == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	m.rmsDBFS, m.peakDBFS = metrics.RMSdBFS, metrics.PeakdBFS
+	if !m.heard {
+		m.heard = true
+		m.silentSince = now
+	}
+	switch {
+	case metrics.PeakdBFS > meterSilenceDBFS:
+		m.silentSince = time.Time{}
+	case m.silentSince.IsZero():
+		m.silentSince = now
+	}
+	if metrics.PeakdBFS >= meterClipDBFS {
+		m.lastClip = now
+	}
+}
+
+// pause shows message instead of the meter until resume is called. It is a
+// no-op on a nil meter.
+func (m *levelMeter) pause(message string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = message
+}
+
+// resume shows the meter again, starting over so the pause does not count
+// as silence.
+func (m *levelMeter) resume() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = ""
+	m.rmsDBFS, m.peakDBFS = math.Inf(-1), math.Inf(-1)
+	m.heard = false
+	m.silentSince, m.lastClip = time.Time{}, time.Time{}
+}
+
+// line renders the meter, e.g.
+// "Recording  [██████░░░░░░░░░░░░░░]  -42 dBFS  peak -30 dBFS".
+func (m *levelMeter) line() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.paused != "" {
+		return m.paused
+	}
+
+	filled := 0
+	if m.rmsDBFS > meterFloorDBFS {
+		filled = int(math.Round(min(1, 1-m.rmsDBFS/meterFloorDBFS) * meterWidth))
+	}
+	line := fmt.Sprintf("%s  [%s%s]  %s  peak %s",
+		m.description,
+		strings.Repeat("█", filled),
+		strings.Repeat("░", meterWidth-filled),
+		formatDBFS(m.rmsDBFS),
+		formatDBFS(m.peakDBFS),
+	)
+
+	now := m.now()
+	switch {
+	case m.heard && !m.silentSince.IsZero() && now.Sub(m.silentSince) >= meterWarnAfter:
+		line += "  no signal, check mute and --input"
+	case !m.lastClip.IsZero() && now.Sub(m.lastClip) < meterWarnAfter:
+		line += "  clipping, lower the input gain"
+	}
+	return line
+}
+
+func formatDBFS(dbfs float64) string {
+	if math.IsInf(dbfs, -1) {
+		return "-inf dBFS"
+	}
+	return fmt.Sprintf("%4.0f dBFS", dbfs)
+}
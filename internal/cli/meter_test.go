@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/fmueller/voxclip/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var meterFormat = audio.Format{SampleRate: 16000, Channels: 1, BitsPerSample: 16}
+
+// meterPCM returns 100ms of a square wave with the given amplitude.
+func meterPCM(amplitude int16) []byte {
+	pcm := make([]byte, meterFormat.Bytes(100*time.Millisecond))
+	for i := 0; i < len(pcm); i += 2 {
+		sample := amplitude
+		if i/2%2 == 1 {
+			sample = -amplitude
+		}
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(sample))
+	}
+	return pcm
+}
+
+func TestLevelMeterShowsLevel(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	m := newLevelMeter("Recording", func() time.Time { return now })
+	require.Equal(t, "Recording  ["+strings.Repeat("░", 20)+"]  -inf dBFS  peak -inf dBFS", m.line())
+
+	m.onAudio(meterFormat, meterPCM(3277)) // -20 dBFS
+	require.Equal(t, "Recording  ["+strings.Repeat("█", 13)+strings.Repeat("░", 7)+"]   -20 dBFS  peak  -20 dBFS", m.line())
+}
+
+func TestLevelMeterWarnsAboutSilence(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	m := newLevelMeter("Recording", func() time.Time { return now })
+	for range 15 {
+		m.onAudio(meterFormat, meterPCM(0))
+		now = now.Add(100 * time.Millisecond)
+	}
+	require.NotContains(t, m.line(), "no signal", "silence is only reported after two seconds")
+
+	for range 6 {
+		m.onAudio(meterFormat, meterPCM(0))
+		now = now.Add(100 * time.Millisecond)
+	}
+	require.Contains(t, m.line(), "no signal, check mute and --input")
+
+	m.onAudio(meterFormat, meterPCM(1000))
+	require.NotContains(t, m.line(), "no signal")
+}
+
+func TestLevelMeterWarnsAboutClipping(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	m := newLevelMeter("Recording", func() time.Time { return now })
+	m.onAudio(meterFormat, meterPCM(32767))
+	require.Contains(t, m.line(), "clipping, lower the input gain")
+
+	now = now.Add(time.Second)
+	m.onAudio(meterFormat, meterPCM(3277))
+	require.Contains(t, m.line(), "clipping", "the warning stays visible for a while")
+
+	now = now.Add(2 * time.Second)
+	require.NotContains(t, m.line(), "clipping")
+}
+
+func TestLevelMeterShowsPause(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	m := newLevelMeter("Recording", func() time.Time { return now })
+	for range 25 {
+		m.onAudio(meterFormat, meterPCM(0))
+		now = now.Add(100 * time.Millisecond)
+	}
+	require.Contains(t, m.line(), "no signal")
+
+	m.pause("Paused")
+	require.Equal(t, "Paused", m.line())
+
+	now = now.Add(time.Minute)
+	m.resume()
+	require.Equal(t, "Recording  ["+strings.Repeat("░", 20)+"]  -inf dBFS  peak -inf dBFS", m.line(), "the pause does not count as silence")
+}
+
+func TestStartLevelMeter(t *testing.T) {
+	t.Parallel()
+
+	m, stop := startLevelMeter(&testutil.SafeBuffer{}, false, "Recording")
+	require.Nil(t, m)
+	m.pause("Paused")
+	m.resume()
+	stop()
+
+	var buf testutil.SafeBuffer
+	m, stop = startLevelMeter(&buf, true, "Recording")
+	m.onAudio(meterFormat, meterPCM(3277))
+	time.Sleep(250 * time.Millisecond)
+	stop()
+	output := string(buf.Bytes())
+	require.Contains(t, output, "-20 dBFS")
+	require.True(t, strings.HasSuffix(output, "\r\033[K"), "the meter line is cleared when recording stops")
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fmueller/voxclip/internal/notify"
+	"github.com/fmueller/voxclip/internal/platform"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	notificationIDFile = "notification-id"
+	// notifyPreviewRunes bounds the transcript preview; notification servers
+	// truncate long bodies anyway.
+	notifyPreviewRunes = 120
+)
+
+func bindNotifyFlags(cmd *cobra.Command, app *appState) {
+	cmd.Flags().BoolVar(&app.notify, "notify", app.notify, "Show desktop notifications for recording, the transcript and errors")
+	bindNotifyToolFlag(cmd, app)
+}
+
+func bindNotifyToolFlag(cmd *cobra.Command, app *appState) {
+	cmd.Flags().StringVar(&app.notifyTool, "notify-tool", app.notifyTool, fmt.Sprintf("Notification tool for --notify (%s); auto uses notify-send or gdbus on Linux, osascript on macOS", strings.Join(notify.Tools(), "|")))
+}
+
+// newNotifier returns a Notifier that replaces the notification of the
+// previous voxclip run.
+func (a *appState) newNotifier() (*notify.Notifier, error) {
+	opts := notify.Options{Tool: a.notifyTool, Logger: a.log()}
+	if stateDir, err := platform.ResolveStateDir(); err == nil {
+		opts.IDFile = filepath.Join(stateDir, notificationIDFile)
+	}
+	return notify.New(opts)
+}
+
+// notifyUser shows a desktop notification when --notify is set. Failures are
+// logged: a missing notification tool must not fail the run.
+func (a *appState) notifyUser(ctx context.Context, urgency, title, body string) {
+	if a.notifyFn == nil {
+		return
+	}
+	msg := notify.Message{Title: title, Body: body, Urgency: urgency}
+	if err := a.notifyFn(ctx, msg); err != nil {
+		a.log().Warn("failed to show notification", zap.Error(err))
+	}
+}
+
+// notifyTranscript reports the outcome of deliverTranscript.
+func (a *appState) notifyTranscript(ctx context.Context, transcript string, delivered []string, err error) {
+	switch {
+	case err != nil:
+		a.notifyUser(ctx, notify.UrgencyCritical, "Transcript not delivered", err.Error())
+	case isBlankTranscript(transcript):
+		a.notifyUser(ctx, notify.UrgencyNormal, "No speech detected", "Check mic mute and selected input device, then try again.")
+	default:
+		title := "Transcript"
+		if len(delivered) > 0 {
+			title = "Transcript sent to " + strings.Join(delivered, ", ")
+		}
+		a.notifyUser(ctx, notify.UrgencyNormal, title, previewText(transcript))
+	}
+}
+
+// reportFailure runs the on_error hook and shows a notification for err.
+// Interrupting voxclip with Ctrl+C is not worth a notification.
+func (a *appState) reportFailure(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	a.runErrorHook(ctx, err)
+	if !errors.Is(err, context.Canceled) {
+		a.notifyUser(context.WithoutCancel(ctx), notify.UrgencyCritical, "voxclip failed", err.Error())
+	}
+}
+
+func previewText(transcript string) string {
+	text := strings.Join(strings.Fields(transcript), " ")
+	runes := []rune(text)
+	if len(runes) <= notifyPreviewRunes {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:notifyPreviewRunes-1])) + "…"
+}
+
+// notifyStatus reports the tool --notify would use.
+func (a *appState) notifyStatus() (string, string) {
+	notifier, err := a.newNotifier()
+	if err != nil {
+		return "unavailable", err.Error()
+	}
+	tool, err := notifier.Tool()
+	if err != nil {
+		return "unavailable", err.Error()
+	}
+	return "ready", tool
+}
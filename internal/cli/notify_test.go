@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fmueller/voxclip/internal/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDefaultNotifiesTranscriptAndErrors(t *testing.T) {
+	t.Parallel()
+
+	audioFile := filepath.Join(t.TempDir(), "audio.wav")
+	transcript := "hello world"
+	var transcribeErr error
+	var messages []notify.Message
+	app := &appState{
+		out:         new(bytes.Buffer),
+		preflightFn: noopLivePreflight,
+		recordFn: func(context.Context, recordOptions) (string, error) {
+			return audioFile, os.WriteFile(audioFile, []byte("fake"), 0o644)
+		},
+		transcribeFn: func(context.Context, string) (string, error) {
+			return transcript, transcribeErr
+		},
+		copyFn: func(context.Context, string) error { return nil },
+		notifyFn: func(_ context.Context, msg notify.Message) error {
+			messages = append(messages, msg)
+			return nil
+		},
+	}
+
+	require.NoError(t, app.runDefault(context.Background()))
+	require.Equal(t, []notify.Message{{Title: "Transcript sent to clipboard", Body: "hello world", Urgency: notify.UrgencyNormal}}, messages)
+
+	messages = nil
+	transcript = blankAudioToken
+	require.NoError(t, app.runDefault(context.Background()))
+	require.Len(t, messages, 1)
+	require.Equal(t, "No speech detected", messages[0].Title)
+
+	messages = nil
+	transcript = "hello world"
+	app.copyFn = func(context.Context, string) error { return errors.New("clipboard tool unavailable") }
+	require.NoError(t, app.runDefault(context.Background()))
+	require.Len(t, messages, 1)
+	require.Equal(t, notify.UrgencyCritical, messages[0].Urgency)
+	require.Contains(t, messages[0].Body, "clipboard tool unavailable")
+
+	messages = nil
+	transcribeErr = errors.New("engine crashed")
+	require.Error(t, app.runDefault(context.Background()))
+	require.Equal(t, []notify.Message{{Title: "voxclip failed", Body: "engine crashed", Urgency: notify.UrgencyCritical}}, messages)
+
+	messages = nil
+	transcribeErr = context.Canceled
+	require.Error(t, app.runDefault(context.Background()))
+	require.Empty(t, messages, "Ctrl+C is not reported")
+}
+
+func TestNotifyFailuresDoNotFailTheRun(t *testing.T) {
+	t.Parallel()
+
+	app := &appState{
+		out:         new(bytes.Buffer),
+		preflightFn: noopLivePreflight,
+		recordFn: func(context.Context, recordOptions) (string, error) {
+			audioFile := filepath.Join(t.TempDir(), "audio.wav")
+			return audioFile, os.WriteFile(audioFile, []byte("fake"), 0o644)
+		},
+		transcribeFn: func(context.Context, string) (string, error) { return "hello", nil },
+		copyFn:       func(context.Context, string) error { return nil },
+		notifyFn: func(context.Context, notify.Message) error {
+			return notify.ErrUnavailable
+		},
+	}
+	require.NoError(t, app.runDefault(context.Background()))
+}
+
+func TestPreviewText(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "hello world", previewText("  hello\n world "))
+
+	long := previewText(strings.Repeat("ä", 200))
+	require.Equal(t, notifyPreviewRunes, utf8.RuneCountInString(long))
+	require.True(t, strings.HasSuffix(long, "…"))
+}
@@ -0,0 +1,267 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/autotype"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	outputStdout    = "stdout"
+	outputClipboard = "clipboard"
+	// outputType types the transcript into the focused window instead of
+	// copying it, so hotkey scripts do not need to simulate a paste.
+	outputType = "type"
+	// outputFile appends an entry to the file named after the colon.
+	outputFile = "file"
+	// outputExec runs the shell command after the colon with the transcript
+	// on stdin.
+	outputExec = "exec"
+
+	defaultOutputFileTemplate = "{time}  {text}"
+	// execOutputTimeout bounds an exec output so a hanging command does not
+	// keep voxclip running.
+	execOutputTimeout = 30 * time.Second
+)
+
+func outputNames() []string {
+	return []string{outputStdout, outputClipboard, outputType, outputFile + ":<path>", outputExec + ":<cmd>"}
+}
+
+// outputSink is one destination for a finished transcript.
+type outputSink struct {
+	name string
+	// raw sinks format the transcript themselves and receive it without
+	// --copy-newline.
+	raw   bool
+	write func(ctx context.Context, text string) error
+}
+
+func bindOutputFlags(cmd *cobra.Command, app *appState, defaults string) {
+	cmd.Flags().StringArrayVar(&app.outputs, "output-to", app.outputs, fmt.Sprintf("Send the transcript to %s; repeat for several (default %s)", strings.Join(outputNames(), ", "), defaults))
+	cmd.Flags().StringVar(&app.fileTemplate, "output-file-template", app.fileTemplate, "Entry appended by file outputs; {text}, {time} (2006-01-02 15:04) and {date} are replaced")
+	bindTypingFlags(cmd, app)
+}
+
+func bindTypingFlags(cmd *cobra.Command, app *appState) {
+	o := &app.typing
+	cmd.Flags().StringVar(&o.Tool, "type-tool", o.Tool, fmt.Sprintf("Typing tool for --output-to type (%s); auto uses wtype or ydotool on Wayland, xdotool or ydotool on X11, osascript on macOS", strings.Join(autotype.Tools(), "|")))
+	cmd.Flags().DurationVar(&o.Delay, "type-delay", o.Delay, "Pause between typed characters, e.g. 10ms; 0 uses the typing tool's default")
+	cmd.Flags().StringVar(&o.Newline, "type-newline", o.Newline, fmt.Sprintf("How line breaks are typed (%s)", strings.Join(autotype.Newlines(), "|")))
+}
+
+// outputSinks returns the sinks named by --output-to, or defaults when the
+// flag was not given.
+func (a *appState) outputSinks(defaults ...string) ([]outputSink, error) {
+	specs := a.outputs
+	if len(specs) == 0 {
+		specs = defaults
+	}
+
+	sinks := make([]outputSink, 0, len(specs))
+	for _, spec := range specs {
+		sink, err := a.outputSink(spec)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+func (a *appState) outputSink(spec string) (outputSink, error) {
+	kind, arg, hasArg := strings.Cut(strings.TrimSpace(spec), ":")
+	arg = strings.TrimSpace(arg)
+	if hasArg && arg == "" {
+		return outputSink{}, fmt.Errorf("--output-to %s needs a value after the colon", spec)
+	}
+
+	switch {
+	case kind == outputStdout && !hasArg:
+		return outputSink{name: kind, raw: true, write: a.writeStdout}, nil
+	case kind == outputClipboard && !hasArg:
+		copyFn := a.copyFn
+		if copyFn == nil {
+			copyFn = a.copyToClipboard
+		}
+		return outputSink{name: kind, write: copyFn}, nil
+	case kind == outputType && !hasArg:
+		typeFn := a.typeFn
+		if typeFn == nil {
+			typeFn = a.typeText
+		}
+		return outputSink{name: kind, write: typeFn}, nil
+	case kind == outputFile && hasArg:
+		return outputSink{name: spec, raw: true, write: func(_ context.Context, text string) error {
+			return a.appendToFile(arg, text)
+		}}, nil
+	case kind == outputExec && hasArg:
+		return outputSink{name: spec, write: func(ctx context.Context, text string) error {
+			return runOutputCommand(ctx, arg, text)
+		}}, nil
+	default:
+		return outputSink{}, fmt.Errorf("unknown --output-to %q (valid: %s)", spec, strings.Join(outputNames(), ", "))
+	}
+}
+
+// deliverTranscript sends transcript to every sink. Blank transcripts only
+// go to stdout unless --copy-empty is set. A failing sink is logged and does
+// not stop the others; the failures are returned joined. When no sink
+// succeeded and stdout was not one of them, the transcript is printed so it
+// is not lost. With --notify the outcome is also shown as a notification.
+func (a *appState) deliverTranscript(ctx context.Context, sinks []outputSink, transcript string) error {
+	blank := isBlankTranscript(transcript)
+	if blank {
+		a.log().Warn(noSpeechHint())
+	}
+
+	text := transcript
+	if a.copyNewline {
+		text += "\n"
+	}
+
+	var errs []error
+	var delivered []string
+	printed := false
+	for _, sink := range sinks {
+		if sink.name == outputStdout {
+			printed = true
+		} else if blank && !a.copyEmpty {
+			continue
+		}
+		value := text
+		if sink.raw {
+			value = transcript
+		}
+
+		if err := sink.write(ctx, value); err != nil {
+			a.log().Warn("failed to write transcript", zap.String("output", sink.name), zap.Error(err))
+			errs = append(errs, fmt.Errorf("output %s: %w", sink.name, err))
+			continue
+		}
+		if sink.name != outputStdout {
+			delivered = append(delivered, sink.name)
+			a.log().Info("transcript written", zap.String("output", sink.name))
+		}
+	}
+
+	if len(errs) > 0 && len(delivered) == 0 && !printed {
+		a.log().Warn("no output succeeded; transcript left on stdout")
+		_ = a.writeStdout(ctx, transcript)
+	}
+	err := errors.Join(errs...)
+	a.notifyTranscript(ctx, transcript, delivered, err)
+	return err
+}
+
+func (a *appState) writeStdout(_ context.Context, text string) error {
+	_, err := fmt.Fprintln(a.outWriter(), text)
+	return err
+}
+
+// appendToFile appends one --output-file-template entry to path.
+func (a *appState) appendToFile(path, text string) error {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("resolve user home: %w", err)
+		}
+		path = filepath.Join(home, rest)
+	}
+
+	now := time.Now()
+	if a.now != nil {
+		now = a.now()
+	}
+	template := a.fileTemplate
+	if template == "" {
+		template = defaultOutputFileTemplate
+	}
+	entry := strings.NewReplacer(
+		"{text}", text,
+		"{time}", now.Format("2006-01-02 15:04"),
+		"{date}", now.Format("2006-01-02"),
+	).Replace(template)
+	if !strings.HasSuffix(entry, "\n") {
+		entry += "\n"
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create directory for %s: %w", path, err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, entry); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// runOutputCommand runs script through sh with text on stdin.
+func runOutputCommand(ctx context.Context, script, text string) error {
+	ctx, cancel := context.WithTimeout(ctx, execOutputTimeout)
+	defer cancel()
+
+	var stderr strings.Builder
+	cmd := exec.CommandContext(ctx, "sh", "-c", script)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = io.Discard
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s", execOutputTimeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+func (a *appState) newTyper() (*autotype.Typer, error) {
+	opts := a.typing
+	opts.Logger = a.log()
+	return autotype.New(opts)
+}
+
+func (a *appState) typeText(ctx context.Context, value string) error {
+	typer, err := a.newTyper()
+	if err != nil {
+		return err
+	}
+	return typer.Type(ctx, value)
+}
+
+// typingStatus reports the tool --output-to type would use.
+func (a *appState) typingStatus() (string, string) {
+	typer, err := a.newTyper()
+	if err != nil {
+		return "unavailable", err.Error()
+	}
+	tool, err := typer.Tool()
+	if err != nil {
+		return "unavailable", err.Error()
+	}
+	return "ready", tool
+}
+
+// withoutStdout drops stdout from sinks for commands that print the
+// transcript as they go.
+func withoutStdout(sinks []outputSink) []outputSink {
+	return slices.DeleteFunc(sinks, func(sink outputSink) bool { return sink.name == outputStdout })
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputSinksParseSpecs(t *testing.T) {
+	t.Parallel()
+
+	app := &appState{outputs: []string{"stdout", "file:/tmp/notes.txt", "exec:notify-send voxclip"}}
+	sinks, err := app.outputSinks(outputClipboard)
+	require.NoError(t, err)
+	var names []string
+	for _, sink := range sinks {
+		names = append(names, sink.name)
+	}
+	require.Equal(t, []string{"stdout", "file:/tmp/notes.txt", "exec:notify-send voxclip"}, names)
+
+	sinks, err = (&appState{}).outputSinks(outputStdout, outputClipboard)
+	require.NoError(t, err)
+	require.Len(t, sinks, 2, "defaults apply without --output-to")
+
+	for _, spec := range []string{"paste", "file:", "file", "clipboard:primary"} {
+		_, err := (&appState{outputs: []string{spec}}).outputSinks()
+		require.Error(t, err, spec)
+	}
+}
+
+func TestDeliverTranscriptKeepsGoingAfterFailures(t *testing.T) {
+	t.Parallel()
+
+	out := new(bytes.Buffer)
+	var typed string
+	app := &appState{
+		out:         out,
+		copyNewline: true,
+		outputs:     []string{"clipboard", "stdout", "type"},
+		copyFn: func(context.Context, string) error {
+			return errors.New("no display")
+		},
+		typeFn: func(_ context.Context, value string) error {
+			typed = value
+			return nil
+		},
+	}
+	sinks, err := app.outputSinks()
+	require.NoError(t, err)
+
+	err = app.deliverTranscript(context.Background(), sinks, "hello world")
+	require.ErrorContains(t, err, "output clipboard: no display")
+	require.Equal(t, "hello world\n", out.String(), "stdout prints the transcript once, without --copy-newline")
+	require.Equal(t, "hello world\n", typed)
+}
+
+func TestDeliverTranscriptPrintsWhenEveryOutputFails(t *testing.T) {
+	t.Parallel()
+
+	out := new(bytes.Buffer)
+	app := &appState{
+		out:     out,
+		outputs: []string{"clipboard"},
+		copyFn: func(context.Context, string) error {
+			return errors.New("no display")
+		},
+	}
+	sinks, err := app.outputSinks()
+	require.NoError(t, err)
+
+	require.Error(t, app.deliverTranscript(context.Background(), sinks, "hello world"))
+	require.Equal(t, "hello world\n", out.String())
+}
+
+func TestDeliverTranscriptSendsBlankOnlyToStdout(t *testing.T) {
+	t.Parallel()
+
+	out := new(bytes.Buffer)
+	app := &appState{
+		out:     out,
+		outputs: []string{"stdout", "clipboard"},
+		copyFn: func(context.Context, string) error {
+			t.Fatal("blank transcripts are not copied without --copy-empty")
+			return nil
+		},
+	}
+	sinks, err := app.outputSinks()
+	require.NoError(t, err)
+
+	require.NoError(t, app.deliverTranscript(context.Background(), sinks, blankAudioToken))
+	require.Equal(t, blankAudioToken+"\n", out.String())
+}
+
+func TestFileOutputAppendsTemplatedEntries(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "notes", "voice.txt")
+	clock := time.Date(2026, 2, 25, 14, 30, 0, 0, time.UTC)
+	app := &appState{
+		now:     func() time.Time { return clock },
+		outputs: []string{"file:" + path},
+	}
+	sinks, err := app.outputSinks()
+	require.NoError(t, err)
+	require.NoError(t, app.deliverTranscript(context.Background(), sinks, "first note"))
+
+	clock = clock.Add(42 * time.Minute)
+	app.fileTemplate = "## {date}\n{text}\n"
+	require.NoError(t, app.deliverTranscript(context.Background(), sinks, "second note"))
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "2026-02-25 14:30  first note\n## 2026-02-25\nsecond note\n", string(got))
+}
+
+func TestExecOutputReceivesTranscriptOnStdin(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("exec outputs run through sh")
+	}
+
+	dir := t.TempDir()
+	app := &appState{outputs: []string{
+		"exec:cat > " + filepath.Join(dir, "out.txt"),
+		"exec:echo broken >&2; exit 3",
+	}}
+	sinks, err := app.outputSinks()
+	require.NoError(t, err)
+
+	err = app.deliverTranscript(context.Background(), sinks, "hello world")
+	require.ErrorContains(t, err, "exit status 3: broken")
+
+	got, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(got))
+}
@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/fmueller/voxclip/internal/record"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// pausedMarkerSuffix names the file that exists next to the pid file while a
+// recording started with --pid-file is paused.
+const pausedMarkerSuffix = ".paused"
+
+// recordingControls steer a recording while it runs. stop ends it, also
+// while it is paused, and toggle pauses or resumes it.
+type recordingControls struct {
+	stop   <-chan struct{}
+	toggle <-chan struct{}
+	// onPause is called when the recording is paused and when it resumes.
+	onPause func(paused bool)
+}
+
+func newPauseCmd(app *appState) *cobra.Command {
+	return newPauseToggleCmd(app, true)
+}
+
+func newResumeCmd(app *appState) *cobra.Command {
+	return newPauseToggleCmd(app, false)
+}
+
+func newPauseToggleCmd(app *appState, pause bool) *cobra.Command {
+	use, short := "resume", "Resume a recording started with --pid-file"
+	if pause {
+		use, short = "pause", "Pause a recording started with --pid-file"
+	}
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return setRecordingPaused(cmd.OutOrStdout(), app.pidFile, pause)
+		},
+	}
+
+	cmd.Flags().StringVar(&app.pidFile, "pid-file", "", "PID file of the recording, as passed to --pid-file")
+	_ = cmd.MarkFlagRequired("pid-file")
+
+	return cmd
+}
+
+// setRecordingPaused pauses or resumes the recording that wrote pidFile by
+// sending it SIGUSR2, unless it already is in that state.
+func setRecordingPaused(out io.Writer, pidFile string, pause bool) error {
+	data, err := os.ReadFile(pidFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no recording is running: %s does not exist", pidFile)
+	}
+	if err != nil {
+		return fmt.Errorf("read pid file: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return fmt.Errorf("pid file %s does not hold a process ID", pidFile)
+	}
+
+	_, err = os.Stat(pausedMarker(pidFile))
+	switch paused := err == nil; {
+	case paused && pause:
+		fmt.Fprintln(out, "recording is already paused")
+		return nil
+	case !paused && !pause:
+		fmt.Fprintln(out, "recording is not paused")
+		return nil
+	}
+
+	if err := signalPauseToggle(pid); err != nil {
+		return fmt.Errorf("signal recording (pid %d): %w", pid, err)
+	}
+	if pause {
+		fmt.Fprintln(out, "recording paused")
+	} else {
+		fmt.Fprintln(out, "recording resumed")
+	}
+	return nil
+}
+
+func pausedMarker(pidFile string) string {
+	return pidFile + pausedMarkerSuffix
+}
+
+func writePausedMarker(pidFile string, logger *zap.Logger) {
+	if err := os.WriteFile(pausedMarker(pidFile), nil, 0o644); err != nil {
+		logger.Warn("failed to write pause marker", zap.String("path", pausedMarker(pidFile)), zap.Error(err))
+	}
+}
+
+func removePausedMarker(pidFile string, logger *zap.Logger) {
+	if err := os.Remove(pausedMarker(pidFile)); err != nil && !os.IsNotExist(err) {
+		logger.Warn("failed to remove pause marker", zap.String("path", pausedMarker(pidFile)), zap.Error(err))
+	}
+}
+
+// watchStdinControls reads the controls of an interactive recording from in:
+// p and Enter pauses or resumes, Enter alone or the end of the input stops.
+func watchStdinControls(in io.Reader) (stop, toggle <-chan struct{}) {
+	stopCh := make(chan struct{})
+	toggleCh := make(chan struct{}, 1)
+
+	go func() {
+		defer close(stopCh)
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			if !strings.EqualFold(strings.TrimSpace(scanner.Text()), "p") {
+				return
+			}
+			select {
+			case toggleCh <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return stopCh, toggleCh
+}
+
+// recordSegments records cfg.OutputPath in segments: pausing stops the
+// backend, and resuming starts it again into a new segment file next to
+// cfg.OutputPath. Once the recording stops, the segments are joined into
+// cfg.OutputPath. With cfg.Duration set, only recorded time counts towards
+// it. It returns the name of the backend that recorded.
+func (a *appState) recordSegments(ctx context.Context, cfg record.Config, controls recordingControls) (backendName string, err error) {
+	preferred := a.backend
+	var segments []string
+	defer func() {
+		for i, segment := range segments {
+			if i == 0 && err == nil {
+				continue
+			}
+			if removeErr := os.Remove(segment); removeErr != nil && !os.IsNotExist(removeErr) {
+				a.log().Warn("failed to remove recording segment", zap.String("path", segment), zap.Error(removeErr))
+			}
+		}
+	}()
+
+	remaining := cfg.Duration
+	for {
+		segment := cfg
+		segment.Duration = remaining
+		if len(segments) > 0 {
+			segment.OutputPath = segmentPath(cfg.OutputPath, len(segments))
+		}
+
+		started := time.Now()
+		name, paused, err := a.recordSegment(ctx, preferred, segment, controls)
+		if err != nil {
+			if len(segments) == 0 || ctx.Err() != nil {
+				return "", err
+			}
+			a.log().Warn("failed to resume recording; keeping the audio recorded before the pause", zap.Error(err))
+			break
+		}
+		segments = append(segments, segment.OutputPath)
+		if backendName == "" {
+			backendName = name
+			// Resume with the same backend, so every segment has the
+			// same format.
+			if preferred == "" || preferred == "auto" {
+				preferred = name
+			}
+		}
+
+		if remaining > 0 {
+			if remaining -= time.Since(started); remaining <= 0 {
+				break
+			}
+		}
+		if !paused {
+			break
+		}
+
+		resumed, err := waitForResume(ctx, controls)
+		if err != nil {
+			return "", err
+		}
+		if !resumed {
+			break
+		}
+	}
+
+	if len(segments) > 1 {
+		joined := segmentPath(cfg.OutputPath, -1)
+		if err := audio.ConcatWAV(joined, segments...); err != nil {
+			return "", fmt.Errorf("join recording segments: %w", err)
+		}
+		if err := os.Rename(joined, cfg.OutputPath); err != nil {
+			_ = os.Remove(joined)
+			return "", fmt.Errorf("join recording segments: %w", err)
+		}
+		a.log().Debug("joined recording segments", zap.Int("segments", len(segments)), zap.String("path", cfg.OutputPath))
+	}
+	return backendName, nil
+}
+
+// recordSegment records until the backend finishes, controls.stop fires or
+// controls.toggle pauses the recording.
+func (a *appState) recordSegment(ctx context.Context, preferred string, cfg record.Config, controls recordingControls) (name string, paused bool, err error) {
+	stopCh := make(chan struct{})
+	cfg.StopCh = stopCh
+
+	type result struct {
+		name string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		name, err := record.RecordWithFallback(ctx, preferred, cfg, a.backends...)
+		done <- result{name: name, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.name, false, r.err
+	case <-controls.stop:
+	case <-controls.toggle:
+		paused = true
+	}
+	close(stopCh)
+	r := <-done
+	return r.name, paused, r.err
+}
+
+// waitForResume waits while the recording is paused. It reports false when
+// the recording was stopped instead.
+func waitForResume(ctx context.Context, controls recordingControls) (bool, error) {
+	controls.onPause(true)
+	select {
+	case <-controls.toggle:
+		controls.onPause(false)
+		return true, nil
+	case <-controls.stop:
+		return false, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// segmentPath names segment n of the recording at path as a hidden file next
+// to it; n < 0 names the file the segments are joined into.
+func segmentPath(path string, n int) string {
+	dir, base := filepath.Split(path)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+	if n < 0 {
+		return filepath.Join(dir, fmt.Sprintf(".%s-joined%s", name, ext))
+	}
+	return filepath.Join(dir, fmt.Sprintf(".%s-segment-%d%s", name, n, ext))
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/fmueller/voxclip/internal/record"
+	"github.com/stretchr/testify/require"
+)
+
+// writeToneWAV writes 2s of a constant non-zero signal, so silence in a
+// recording made from it can only come from a gap between segments.
+func writeToneWAV(t *testing.T) string {
+	t.Helper()
+
+	samples := make([]int16, 32000)
+	for i := range samples {
+		samples[i] = 1000
+	}
+	path := filepath.Join(t.TempDir(), "tone.wav")
+	require.NoError(t, os.WriteFile(path, makePCM16WAVForTest(samples, 16000, 1), 0o644))
+	return path
+}
+
+func TestRecordSegmentsJoinsPausedSegments(t *testing.T) {
+	t.Parallel()
+
+	app := &appState{backend: "file:" + writeToneWAV(t)}
+	dir := t.TempDir()
+	outPath := filepath.Join(dir, "recording.wav")
+
+	stopCh := make(chan struct{})
+	toggleCh := make(chan struct{}, 1)
+	pausedCh := make(chan bool, 2)
+	controls := recordingControls{
+		stop:    stopCh,
+		toggle:  toggleCh,
+		onPause: func(paused bool) { pausedCh <- paused },
+	}
+
+	type result struct {
+		backend string
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		backend, err := app.recordSegments(context.Background(), record.Config{OutputPath: outPath}, controls)
+		done <- result{backend: backend, err: err}
+	}()
+
+	time.Sleep(300 * time.Millisecond)
+	toggleCh <- struct{}{}
+	require.True(t, <-pausedCh)
+	time.Sleep(300 * time.Millisecond)
+	toggleCh <- struct{}{}
+	require.False(t, <-pausedCh)
+	time.Sleep(300 * time.Millisecond)
+	close(stopCh)
+
+	r := <-done
+	require.NoError(t, r.err)
+	require.Equal(t, "file", r.backend)
+
+	format, pcm, err := audio.ReadWAV(outPath)
+	require.NoError(t, err)
+	duration := format.Duration(len(pcm))
+	require.GreaterOrEqual(t, duration, 500*time.Millisecond)
+	require.Less(t, duration, 900*time.Millisecond, "the pause is not recorded")
+	metrics, err := audio.MeasurePCM(format, pcm[len(pcm)/2:])
+	require.NoError(t, err)
+	require.Greater(t, metrics.PeakdBFS, -40.0, "the second segment follows the first")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "segments are removed after joining")
+}
+
+func TestRecordSegmentsCountsOnlyRecordedTimeTowardsDuration(t *testing.T) {
+	t.Parallel()
+
+	app := &appState{backend: "file:" + writeToneWAV(t)}
+	outPath := filepath.Join(t.TempDir(), "recording.wav")
+
+	toggleCh := make(chan struct{}, 1)
+	pausedCh := make(chan bool, 2)
+	controls := recordingControls{
+		toggle:  toggleCh,
+		onPause: func(paused bool) { pausedCh <- paused },
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := app.recordSegments(context.Background(), record.Config{OutputPath: outPath, Duration: 600 * time.Millisecond}, controls)
+		done <- err
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	toggleCh <- struct{}{}
+	require.True(t, <-pausedCh)
+	time.Sleep(600 * time.Millisecond)
+	toggleCh <- struct{}{}
+	require.False(t, <-pausedCh)
+	require.NoError(t, <-done)
+
+	duration, err := audio.WAVDuration(outPath)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, duration, 500*time.Millisecond)
+	require.LessOrEqual(t, duration, 800*time.Millisecond)
+}
+
+func TestRecordSegmentsWithoutPauseRecordsOnce(t *testing.T) {
+	t.Parallel()
+
+	app := &appState{backend: "file:" + writeToneWAV(t)}
+	dir := t.TempDir()
+	outPath := filepath.Join(dir, "recording.wav")
+
+	stopCh := make(chan struct{})
+	close(stopCh)
+	controls := recordingControls{
+		stop:    stopCh,
+		onPause: func(bool) { t.Error("recording was paused") },
+	}
+	_, err := app.recordSegments(context.Background(), record.Config{OutputPath: outPath}, controls)
+	require.NoError(t, err)
+	require.FileExists(t, outPath)
+}
+
+func TestWatchStdinControls(t *testing.T) {
+	t.Parallel()
+
+	r, w := io.Pipe()
+	stop, toggle := watchStdinControls(r)
+
+	_, err := io.WriteString(w, "p\n")
+	require.NoError(t, err)
+	<-toggle
+	_, err = io.WriteString(w, " P \n")
+	require.NoError(t, err)
+	<-toggle
+
+	select {
+	case <-stop:
+		t.Fatal("stopped before Enter")
+	default:
+	}
+	_, err = io.WriteString(w, "\n")
+	require.NoError(t, err)
+	<-stop
+}
+
+func TestWatchStdinControlsStopsAtEndOfInput(t *testing.T) {
+	t.Parallel()
+
+	r, w := io.Pipe()
+	stop, _ := watchStdinControls(r)
+	require.NoError(t, w.Close())
+	<-stop
+}
+
+func TestSetRecordingPausedWithoutRecording(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := runCommand(t, []string{"pause", "--pid-file", filepath.Join(t.TempDir(), "missing.pid")})
+	require.ErrorContains(t, err, "no recording is running")
+}
+
+func TestSetRecordingPausedKeepsState(t *testing.T) {
+	t.Parallel()
+
+	pidFile := filepath.Join(t.TempDir(), "voxclip.pid")
+	require.NoError(t, os.WriteFile(pidFile, []byte("999999"), 0o644))
+
+	stdout, _, err := runCommand(t, []string{"resume", "--pid-file", pidFile})
+	require.NoError(t, err)
+	require.Equal(t, "recording is not paused\n", stdout)
+
+	require.NoError(t, os.WriteFile(pausedMarker(pidFile), nil, 0o644))
+	stdout, _, err = runCommand(t, []string{"pause", "--pid-file", pidFile})
+	require.NoError(t, err)
+	require.Equal(t, "recording is already paused\n", stdout)
+}
+
+func TestSegmentPath(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, filepath.Join("rec", ".take-segment-2.wav"), segmentPath(filepath.Join("rec", "take.wav"), 2))
+	require.Equal(t, filepath.Join("rec", ".take-joined.wav"), segmentPath(filepath.Join("rec", "take.wav"), -1))
+}
@@ -101,3 +101,69 @@ func startDurationProgress(w io.Writer, enabled bool, description string, durati
 		})
 	}
 }
+
+// startPercentProgress shows a determinate 0-100% bar fed through the
+// returned report function, with an ETA extrapolated from the elapsed rate.
+func startPercentProgress(w io.Writer, enabled bool, description string) (func(percent int), stopFunc) {
+	if !enabled {
+		return func(int) {}, func() {}
+	}
+
+	bar := progressbar.NewOptions(
+		100,
+		progressbar.OptionSetDescription(description),
+		progressbar.OptionSetWriter(w),
+		progressbar.OptionShowCount(),
+		progressbar.OptionSetWidth(20),
+		progressbar.OptionSetPredictTime(false),
+		progressbar.OptionThrottle(65*time.Millisecond),
+		progressbar.OptionOnCompletion(func() { fmt.Fprint(w, "\n") }),
+	)
+
+	updates := make(chan int, 16)
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	started := time.Now()
+
+	go func() {
+		defer close(doneCh)
+
+		for {
+			select {
+			case <-stopCh:
+				_ = bar.Finish()
+				return
+			case percent := <-updates:
+				if eta, ok := estimateRemaining(time.Since(started), percent); ok {
+					bar.Describe(fmt.Sprintf("%s (ETA %s)", description, eta))
+				}
+				_ = bar.Set(percent)
+			}
+		}
+	}()
+
+	report := func(percent int) {
+		select {
+		case updates <- percent:
+		default:
+		}
+	}
+
+	var once sync.Once
+	return report, func() {
+		once.Do(func() {
+			close(stopCh)
+			<-doneCh
+		})
+	}
+}
+
+// estimateRemaining extrapolates the time left from the rate so far. It
+// reports false until there is enough progress to extrapolate from.
+func estimateRemaining(elapsed time.Duration, percent int) (time.Duration, bool) {
+	if percent <= 0 || percent >= 100 || elapsed <= 0 {
+		return 0, false
+	}
+	remaining := elapsed * time.Duration(100-percent) / time.Duration(percent)
+	return remaining.Round(time.Second), true
+}
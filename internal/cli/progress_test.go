@@ -2,11 +2,14 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"io"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/fmueller/voxclip/internal/testutil"
+	"github.com/fmueller/voxclip/internal/whisper"
 	"github.com/stretchr/testify/require"
 )
 
@@ -87,3 +90,76 @@ func TestSpinnerDoubleStopIsSafe(t *testing.T) {
 	stop()
 	stop() // second call must not panic
 }
+
+func TestStartPercentProgressDisabled(t *testing.T) {
+	t.Parallel()
+	report, stop := startPercentProgress(io.Discard, false, "testing")
+	report(50)
+	stop()
+}
+
+func TestPercentProgressOutputEndsWithNewline(t *testing.T) {
+	t.Parallel()
+	var buf testutil.SafeBuffer
+	report, stop := startPercentProgress(&buf, true, "testing")
+	report(10)
+	time.Sleep(100 * time.Millisecond)
+	report(60)
+	time.Sleep(100 * time.Millisecond)
+	stop()
+	stop()     // second call must not panic
+	report(90) // reports after stop must not block
+
+	output := buf.Bytes()
+	require.Contains(t, string(output), "ETA")
+	require.True(t, bytes.HasSuffix(output, []byte("\n")),
+		"percent progress output must end with newline to prevent log overlap, got trailing bytes: %q",
+		testutil.TrailingBytes(output, 20))
+}
+
+func TestPercentProgressPerPassLabelsLanguageRetry(t *testing.T) {
+	t.Parallel()
+
+	var buf testutil.SafeBuffer
+	var languages []string
+	engine := whisper.EngineFunc(func(_ context.Context, req whisper.TranscriptionRequest) (whisper.Transcription, error) {
+		languages = append(languages, req.Language)
+		require.NotNil(t, req.Progress)
+		req.Progress(50)
+		time.Sleep(100 * time.Millisecond)
+		req.Progress(100)
+		time.Sleep(100 * time.Millisecond)
+		if req.Language == "auto" {
+			return whisper.Transcription{Text: "hallo wereld", Language: "nl"}, nil
+		}
+		return whisper.Transcription{Text: "hallo Welt", Language: req.Language}, nil
+	})
+
+	wrapped, stop := percentProgressPerPass(&buf, true, engine)
+	result, err := whisper.TranscribeWithAllowedLanguages(context.Background(), wrapped, whisper.TranscriptionRequest{Language: "auto"}, []string{"en", "de"}, nil)
+	stop()
+	require.NoError(t, err)
+	require.Equal(t, "hallo Welt", result.Text)
+	require.Equal(t, []string{"auto", "en"}, languages)
+
+	output := string(buf.Bytes())
+	first := strings.Index(output, "Transcribing")
+	retry := strings.Index(output, "Retrying in en")
+	require.GreaterOrEqual(t, first, 0)
+	require.Greater(t, retry, first, "the retry gets its own bar after the first one")
+	require.True(t, strings.HasSuffix(output, "\n"))
+}
+
+func TestEstimateRemaining(t *testing.T) {
+	t.Parallel()
+
+	eta, ok := estimateRemaining(10*time.Second, 25)
+	require.True(t, ok)
+	require.Equal(t, 30*time.Second, eta)
+
+	_, ok = estimateRemaining(10*time.Second, 0)
+	require.False(t, ok)
+
+	_, ok = estimateRemaining(10*time.Second, 100)
+	require.False(t, ok)
+}
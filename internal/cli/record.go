@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/fmueller/voxclip/internal/notify"
 	"github.com/fmueller/voxclip/internal/record"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"golang.org/x/term"
 )
 
 type recordOptions struct {
@@ -16,6 +20,9 @@ type recordOptions struct {
 	output   string
 	input    string
 	format   string
+	// onAudio, when set, receives the audio while it is being recorded.
+	// The recording spinner is not shown since the caller owns the output.
+	onAudio func(format audio.Format, pcm []byte)
 }
 
 func newRecordCmd(app *appState) *cobra.Command {
@@ -24,7 +31,9 @@ func newRecordCmd(app *appState) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "record",
 		Short: "Record audio into a WAV file",
-		RunE: func(cmd *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, _ []string) (err error) {
+			defer func() { app.reportFailure(cmd.Context(), err) }()
+
 			opts.input = app.input
 			opts.format = app.inputFormat
 			path, err := app.recordAudio(cmd.Context(), *opts)
@@ -39,10 +48,11 @@ func newRecordCmd(app *appState) *cobra.Command {
 	bindLoggingFlags(cmd, app)
 	bindProgressFlag(cmd, app)
 	bindRecordingBackendFlags(cmd, app)
+	bindNotifyFlags(cmd, app)
 	cmd.Flags().DurationVar(&opts.duration, "duration", 0, "Record duration, e.g. 6s; 0 means interactive start/stop (acts as max timeout with --pid-file)")
 	cmd.Flags().StringVar(&opts.output, "output", "", "Output WAV file path")
 	cmd.Flags().BoolVar(&app.immediate, "immediate", false, "Start recording immediately without waiting for Enter")
-	cmd.Flags().StringVar(&app.pidFile, "pid-file", "", "Write PID to file and wait for SIGUSR1 to stop recording")
+	cmd.Flags().StringVar(&app.pidFile, "pid-file", "", "Write PID to file and wait for SIGUSR1 to stop recording; SIGUSR2 pauses and resumes")
 
 	return cmd
 }
@@ -64,16 +74,32 @@ func (a *appState) recordAudio(ctx context.Context, opts recordOptions) (string,
 		}
 	}
 
+	// Pausing takes over the keyboard in interactive mode, so it needs a
+	// terminal; without one, the backend waits for Enter as before.
+	pausable := a.pidFile != "" || (interactive && term.IsTerminal(int(os.Stdin.Fd())))
+
+	a.runRecordStartHook(ctx, outPath)
+	a.notifyUser(ctx, notify.UrgencyLow, "Recording…", recordingStopHint(interactive, a.pidFile != "", opts.duration))
 	a.log().Info("recording started", zap.String("backend", a.backend), zap.String("output", outPath))
 	stopProgress := func() {}
-	if a.pidFile != "" {
-		stopProgress = startSpinner(os.Stderr, a.progressEnabled(), "Recording")
-	} else if interactive {
-		stopProgress = startSpinner(os.Stderr, a.progressEnabled(), "Recording... press Enter to stop")
-	} else {
+	var meter *levelMeter
+	switch {
+	case opts.onAudio != nil:
+		// Streaming callers print their own output while recording.
+	case a.pidFile != "":
+		meter, stopProgress = startLevelMeter(os.Stderr, a.progressEnabled(), "Recording")
+	case pausable:
+		meter, stopProgress = startLevelMeter(os.Stderr, a.progressEnabled(), "Recording... press Enter to stop, p and Enter to pause")
+	case interactive:
+		meter, stopProgress = startLevelMeter(os.Stderr, a.progressEnabled(), "Recording... press Enter to stop")
+	default:
 		stopProgress = startDurationProgress(os.Stderr, a.progressEnabled(), "Recording", opts.duration)
 	}
 	defer stopProgress()
+	onAudio := opts.onAudio
+	if meter != nil {
+		onAudio = meter.onAudio
+	}
 
 	recConfig := record.Config{
 		OutputPath:  outPath,
@@ -83,31 +109,108 @@ func (a *appState) recordAudio(ctx context.Context, opts recordOptions) (string,
 		Channels:    1,
 		Input:       opts.input,
 		Format:      opts.format,
+		HighPass:    a.highPass,
 		Logger:      a.log(),
+		OnAudio:     onAudio,
 	}
-	if interactive && !a.progressEnabled() {
+	if interactive && (!a.progressEnabled() || opts.onAudio != nil) {
 		recConfig.InteractiveMessage = "Press Enter to stop recording."
 	}
 
-	if a.pidFile != "" {
+	var controls recordingControls
+	switch {
+	case a.pidFile != "":
 		stopCh := make(chan struct{})
 		cleanup := registerStopSignal(stopCh, a.log())
 		defer cleanup()
+		toggleCh := make(chan struct{}, 1)
+		cleanupPause := registerPauseSignal(toggleCh, a.log())
+		defer cleanupPause()
 
 		if err := writePIDFile(a.pidFile); err != nil {
 			return "", fmt.Errorf("write pid file: %w", err)
 		}
 		defer removePIDFile(a.pidFile, a.log())
-
-		recConfig.StopCh = stopCh
+		defer removePausedMarker(a.pidFile, a.log())
+
+		controls = recordingControls{stop: stopCh, toggle: toggleCh}
+	case pausable:
+		recConfig.Interactive = false
+		controls.stop, controls.toggle = watchStdinControls(os.Stdin)
+		if recConfig.InteractiveMessage != "" {
+			fmt.Fprintln(os.Stderr, "Press Enter to stop recording, or type p and press Enter to pause.")
+		}
+	}
+	controls.onPause = func(paused bool) {
+		a.recordingPaused(ctx, meter, paused)
 	}
 
-	backendName, err := record.RecordWithFallback(ctx, a.backend, recConfig)
+	var backendName string
+	if pausable {
+		backendName, err = a.recordSegments(ctx, recConfig, controls)
+	} else {
+		backendName, err = record.RecordWithFallback(ctx, a.backend, recConfig, a.backends...)
+	}
 	stopProgress()
+	a.runRecordStopHook(ctx, outPath)
 	if err != nil {
 		return "", err
 	}
+	a.notifyUser(ctx, notify.UrgencyLow, "Recording stopped", "")
+
+	// A recorder killed after the stop grace period leaves the header sizes
+	// of the file unfinished.
+	if repaired, err := audio.RepairWAV(outPath); err != nil {
+		a.log().Warn("failed to check the recording's WAV header", zap.String("path", outPath), zap.Error(err))
+	} else if repaired {
+		a.log().Info("repaired the recording's WAV header", zap.String("path", outPath))
+	}
 
 	a.log().Info("recording finished", zap.String("backend", backendName), zap.String("path", outPath))
+	if a.highPass > 0 && backendName != "sox" {
+		a.log().Warn("--highpass only applies to the sox backend; recorded without the filter", zap.String("backend", backendName))
+	}
+	if opts.output == "" {
+		a.enforceRetention(filepath.Dir(outPath))
+	}
 	return outPath, nil
 }
+
+// recordingPaused tells the user that the recording was paused or resumed.
+func (a *appState) recordingPaused(ctx context.Context, meter *levelMeter, paused bool) {
+	hint := "type p and press Enter to resume"
+	if a.pidFile != "" {
+		hint = "run voxclip resume or send SIGUSR2 to resume"
+		if paused {
+			writePausedMarker(a.pidFile, a.log())
+		} else {
+			removePausedMarker(a.pidFile, a.log())
+		}
+	}
+
+	if !paused {
+		meter.resume()
+		a.notifyUser(ctx, notify.UrgencyLow, "Recording resumed", "")
+		if meter == nil {
+			a.log().Info("recording resumed")
+		}
+		return
+	}
+	meter.pause("Paused... " + hint)
+	a.notifyUser(ctx, notify.UrgencyLow, "Recording paused", "")
+	if meter == nil {
+		a.log().Info("recording paused; " + hint)
+	}
+}
+
+// recordingStopHint tells the user how the recording that just started ends.
+func recordingStopHint(interactive, signaled bool, duration time.Duration) string {
+	switch {
+	case signaled:
+		return "Stops on SIGUSR1, e.g. when the hotkey is pressed again."
+	case interactive:
+		return "Press Enter to stop."
+	default:
+		return fmt.Sprintf("Stops after %s.", duration)
+	}
+}
@@ -0,0 +1,41 @@
+//go:build !windows
+
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/config"
+	"github.com/fmueller/voxclip/internal/record"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAudioRepairsWAVHeader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.wav")
+	require.NoError(t, os.WriteFile(broken, truncatedWAV([]int16{1, 2, 3, 4}), 0o644))
+
+	backends, err := record.CommandBackends([]config.Backend{{
+		Name:   "copy",
+		Check:  "sh",
+		Record: []string{"sh", "-c", `cp "$0" "$1"`, broken, "{output}"},
+	}})
+	require.NoError(t, err)
+	app := &appState{backend: "copy", backends: backends, noProgress: true}
+
+	path, err := app.recordAudio(context.Background(), recordOptions{
+		duration: time.Second,
+		output:   filepath.Join(dir, "recording.wav"),
+	})
+	require.NoError(t, err)
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, makePCM16WAVForTest([]int16{1, 2, 3, 4}, 16000, 1), raw)
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/fmueller/voxclip/internal/platform"
+	"github.com/fmueller/voxclip/internal/recordings"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// enforceRetention removes recordings the retention policy expires. Failures
+// are logged: cleanup must not fail the recording that triggered it.
+func (a *appState) enforceRetention(dir string) {
+	removed, err := recordings.Enforce(dir, a.retention, a.now())
+	for _, rec := range removed {
+		a.log().Debug("removed expired recording", zap.String("path", rec.Path))
+	}
+	if len(removed) > 0 {
+		a.log().Info("removed expired recordings", zap.Int("count", len(removed)), zap.String("dir", dir))
+	}
+	if err != nil {
+		a.log().Warn("failed to remove expired recordings", zap.String("dir", dir), zap.Error(err))
+	}
+}
+
+func newRecordingsCmd(app *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recordings",
+		Short: "Manage recordings kept by voxclip record and --keep-audio",
+	}
+	cmd.AddCommand(newRecordingsListCmd(app))
+	cmd.AddCommand(newRecordingsPlayPathCmd(app))
+	cmd.AddCommand(withConfig(newRecordingsPurgeCmd(app)))
+	return cmd
+}
+
+func newRecordingsListCmd(app *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recordings, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dir, recs, err := listRecordings()
+			if err != nil {
+				return err
+			}
+			if len(recs) == 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "No recordings in %s\n", dir)
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "#\tRECORDED\tDURATION\tSIZE\tPATH")
+			for i, rec := range recs {
+				duration := "-"
+				if d, err := audio.WAVDuration(rec.Path); err == nil {
+					duration = d.Round(time.Second).String()
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, rec.ModTime.Format("2006-01-02 15:04:05"), duration, recordings.FormatSize(rec.Size), rec.Path)
+			}
+			return w.Flush()
+		},
+	}
+	bindLoggingFlags(cmd, app)
+	return cmd
+}
+
+func newRecordingsPlayPathCmd(app *appState) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "play-path [number|name]",
+		Short: "Print the path of a recording, the newest by default",
+		Long: "Print the path of a recording for a player, e.g. aplay \"$(voxclip recordings play-path)\".\n" +
+			"Select a recording by its number in \"voxclip recordings list\" or by file name.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, recs, err := listRecordings()
+			if err != nil {
+				return err
+			}
+			if len(recs) == 0 {
+				return fmt.Errorf("no recordings in %s", dir)
+			}
+
+			selected := recs[0]
+			if len(args) == 1 {
+				if selected, err = selectRecording(recs, args[0]); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), selected.Path)
+			return nil
+		},
+	}
+	bindLoggingFlags(cmd, app)
+	return cmd
+}
+
+func newRecordingsPurgeCmd(app *appState) *cobra.Command {
+	var (
+		all       bool
+		olderThan time.Duration
+		dryRun    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Remove recordings the retention policy expires, or the ones selected by flags",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if all && olderThan > 0 {
+				return fmt.Errorf("--all and --older-than cannot be combined")
+			}
+
+			_, recs, err := listRecordings()
+			if err != nil {
+				return err
+			}
+
+			var selected []recordings.Recording
+			switch {
+			case all:
+				selected = recs
+			case olderThan > 0:
+				for _, rec := range recs {
+					if app.now().Sub(rec.ModTime) > olderThan {
+						selected = append(selected, rec)
+					}
+				}
+			default:
+				selected = app.retention.Expired(recs, app.now())
+			}
+
+			if !dryRun {
+				selected, err = recordings.Remove(selected)
+			}
+			for _, rec := range selected {
+				fmt.Fprintln(cmd.OutOrStdout(), rec.Path)
+			}
+			return err
+		},
+	}
+	bindLoggingFlags(cmd, app)
+	cmd.Flags().BoolVar(&all, "all", false, "Remove every recording")
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "Remove recordings older than this, e.g. 168h, instead of applying the retention policy")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the recordings that would be removed without removing them")
+	return cmd
+}
+
+func listRecordings() (string, []recordings.Recording, error) {
+	dir, err := platform.ResolveRecordingDir()
+	if err != nil {
+		return "", nil, err
+	}
+	recs, err := recordings.List(dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("list recordings in %s: %w", dir, err)
+	}
+	return dir, recs, nil
+}
+
+// selectRecording finds a recording by its 1-based position in recs or by
+// file name, with or without the .wav extension.
+func selectRecording(recs []recordings.Recording, selector string) (recordings.Recording, error) {
+	if n, err := strconv.Atoi(selector); err == nil {
+		if n < 1 || n > len(recs) {
+			return recordings.Recording{}, fmt.Errorf("no recording number %d; there are %d recordings", n, len(recs))
+		}
+		return recs[n-1], nil
+	}
+
+	name := filepath.Base(selector)
+	for _, rec := range recs {
+		if rec.Name() == name || strings.TrimSuffix(rec.Name(), ".wav") == name {
+			return rec, nil
+		}
+	}
+	return recordings.Recording{}, fmt.Errorf("no recording named %q; run voxclip recordings list", selector)
+}
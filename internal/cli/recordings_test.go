@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/platform"
+	"github.com/stretchr/testify/require"
+)
+
+// setupRecordingsDir points the recordings directory at a temporary one
+// holding three recordings, one, two and forty days old.
+func setupRecordingsDir(t *testing.T) []string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	t.Setenv(configPathEnv, "")
+	t.Setenv(configProfileEnv, "")
+
+	dir, err := platform.ResolveRecordingDir()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+
+	var paths []string
+	for i, age := range []time.Duration{24 * time.Hour, 48 * time.Hour, 40 * 24 * time.Hour} {
+		path := filepath.Join(dir, "recording-"+string(rune('c'-i))+".wav")
+		require.NoError(t, os.WriteFile(path, makePCM16WAVForTest(make([]int16, 16000), 16000, 1), 0o644))
+		modTime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestRecordingsListAndPlayPath(t *testing.T) {
+	paths := setupRecordingsDir(t)
+
+	stdout, _, err := runCommand(t, []string{"recordings", "list"})
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 4)
+	require.Regexp(t, `^1\s+\S+ \S+\s+1s\s+32\.0 kB\s+`+paths[0]+`$`, lines[1])
+	require.Contains(t, lines[3], paths[2])
+
+	stdout, _, err = runCommand(t, []string{"recordings", "play-path"})
+	require.NoError(t, err)
+	require.Equal(t, paths[0]+"\n", stdout)
+
+	stdout, _, err = runCommand(t, []string{"recordings", "play-path", "2"})
+	require.NoError(t, err)
+	require.Equal(t, paths[1]+"\n", stdout)
+
+	stdout, _, err = runCommand(t, []string{"recordings", "play-path", "recording-a"})
+	require.NoError(t, err)
+	require.Equal(t, paths[2]+"\n", stdout)
+
+	_, _, err = runCommand(t, []string{"recordings", "play-path", "4"})
+	require.ErrorContains(t, err, "there are 3 recordings")
+}
+
+func TestRecordingsPurge(t *testing.T) {
+	paths := setupRecordingsDir(t)
+
+	stdout, _, err := runCommand(t, []string{"recordings", "purge", "--dry-run"})
+	require.NoError(t, err)
+	require.Empty(t, stdout, "without limits in the config file, every recording is kept")
+	require.FileExists(t, paths[2])
+
+	stdout, _, err = runCommand(t, []string{"recordings", "purge", "--older-than", "36h"})
+	require.NoError(t, err)
+	require.Equal(t, paths[1]+"\n"+paths[2]+"\n", stdout)
+	require.NoFileExists(t, paths[1])
+	require.FileExists(t, paths[0])
+
+	config := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(config, []byte(`{"recordings": {"max_age": "1h"}}`), 0o644))
+	t.Setenv(configPathEnv, config)
+	stdout, _, err = runCommand(t, []string{"recordings", "purge"})
+	require.NoError(t, err)
+	require.Empty(t, stdout, "the newest recording is always kept")
+
+	stdout, _, err = runCommand(t, []string{"recordings", "purge", "--all"})
+	require.NoError(t, err)
+	require.Equal(t, paths[0]+"\n", stdout)
+	require.NoFileExists(t, paths[0])
+
+	_, stderr, err := runCommand(t, []string{"recordings", "list"})
+	require.NoError(t, err)
+	require.Contains(t, stderr, "No recordings in")
+}
+
+func TestRunDefaultKeepAudio(t *testing.T) {
+	t.Parallel()
+
+	audioFile := filepath.Join(t.TempDir(), "recording.wav")
+	app := &appState{
+		out:         new(bytes.Buffer),
+		keepAudio:   true,
+		preflightFn: noopLivePreflight,
+		recordFn: func(context.Context, recordOptions) (string, error) {
+			return audioFile, os.WriteFile(audioFile, []byte("fake"), 0o644)
+		},
+		transcribeFn: func(context.Context, string) (string, error) { return "hello", nil },
+		copyFn:       func(context.Context, string) error { return nil },
+	}
+
+	require.NoError(t, app.runDefault(context.Background()))
+	require.FileExists(t, audioFile)
+
+	app.keepAudio = false
+	require.NoError(t, app.runDefault(context.Background()))
+	require.NoFileExists(t, audioFile)
+}
@@ -2,7 +2,6 @@ package cli
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,9 +10,15 @@ import (
 	"time"
 
 	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/fmueller/voxclip/internal/autotype"
 	"github.com/fmueller/voxclip/internal/clipboard"
+	"github.com/fmueller/voxclip/internal/config"
+	"github.com/fmueller/voxclip/internal/hooks"
 	"github.com/fmueller/voxclip/internal/logging"
+	"github.com/fmueller/voxclip/internal/notify"
 	"github.com/fmueller/voxclip/internal/platform"
+	"github.com/fmueller/voxclip/internal/record"
+	"github.com/fmueller/voxclip/internal/recordings"
 	"github.com/fmueller/voxclip/internal/version"
 	"github.com/fmueller/voxclip/internal/whisper"
 	"go.uber.org/zap"
@@ -29,19 +34,38 @@ type appState struct {
 	model        string
 	modelDir     string
 	language     string
+	languages    []string
+	decoding     whisper.DecodingOptions
+	engine       string
+	serverAddr   string
+	remote       whisper.RemoteOptions
+	clipboard    clipboard.Options
+	outputs      []string
+	fileTemplate string
+	typing       autotype.Options
+	longAudio    longAudioOptions
+	config       config.File
 	autoDownload bool
 	backend      string
 	input        string
 	inputFormat  string
+	highPass     int
+	backends     []record.Backend
 	copyEmpty    bool
 	copyNewline  bool
+	restoreAfter time.Duration
+	notify       bool
+	notifyTool   string
 	silenceGate  bool
 	silenceDBFS  float64
 	duration     time.Duration
 	immediate    bool
 	pidFile      string
+	keepAudio    bool
+	retention    recordings.Policy
 
 	logger *zap.Logger
+	hooks  *hooks.Runner
 	now    func() time.Time
 	out    io.Writer
 
@@ -49,12 +73,27 @@ type appState struct {
 	recordFn     func(ctx context.Context, opts recordOptions) (string, error)
 	transcribeFn func(ctx context.Context, audioPath string) (string, error)
 	copyFn       func(ctx context.Context, value string) error
+	typeFn       func(ctx context.Context, value string) error
+	restoreFn    func(id string, delay time.Duration) error
+	// notifyFn shows a desktop notification; nil unless --notify is set.
+	notifyFn func(ctx context.Context, msg notify.Message) error
 }
 
 func NewRootCmd() *cobra.Command {
 	app := &appState{
-		model:        whisper.DefaultModel(),
-		language:     "auto",
+		model:      whisper.DefaultModel(),
+		language:   "auto",
+		engine:     engineBundled,
+		serverAddr: whisper.DefaultServerAddress,
+		remote: whisper.RemoteOptions{
+			Model:   whisper.DefaultRemoteModel,
+			Timeout: whisper.DefaultRemoteTimeout,
+			Retries: whisper.DefaultRemoteRetries,
+		},
+		longAudio: longAudioOptions{
+			chunkLength:  audio.DefaultChunkDuration,
+			chunkOverlap: audio.DefaultChunkOverlap,
+		},
 		autoDownload: true,
 		backend:      "auto",
 		silenceGate:  true,
@@ -65,7 +104,8 @@ func NewRootCmd() *cobra.Command {
 	app.preflightFn = app.ensureTranscriptionReady
 	app.recordFn = app.recordAudio
 	app.transcribeFn = app.transcribeAudio
-	app.copyFn = clipboard.CopyText
+	app.copyFn = app.copyToClipboard
+	app.typeFn = app.typeText
 
 	cmd := &cobra.Command{
 		Use:           "voxclip",
@@ -73,13 +113,62 @@ func NewRootCmd() *cobra.Command {
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		Version:       version.Resolve(),
-		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			file, err := commandConfig(cmd)
+			if err != nil {
+				return err
+			}
+			app.config = file
+
 			logger, err := logging.New(logging.Options{Verbose: app.verbose, JSON: app.jsonLogs})
 			if err != nil {
 				return fmt.Errorf("initialize logger: %w", err)
 			}
 			app.language = sanitizeLanguage(app.language)
+			languages, err := whisper.NormalizeLanguages(app.languages)
+			if err != nil {
+				return fmt.Errorf("invalid --languages: %w", err)
+			}
+			if len(languages) > 0 && app.language != whisper.AutoLanguage {
+				return fmt.Errorf("--languages restricts auto-detection and requires --language auto; got --language %s", app.language)
+			}
+			app.languages = languages
+			if err := app.decoding.Validate(); err != nil {
+				return fmt.Errorf("invalid decoding options: %w", err)
+			}
+			if _, err := clipboard.New(app.clipboard); err != nil {
+				return fmt.Errorf("invalid clipboard options: %w", err)
+			}
+			if _, err := app.outputSinks(); err != nil {
+				return err
+			}
+			if _, err := autotype.New(app.typing); err != nil {
+				return fmt.Errorf("invalid typing options: %w", err)
+			}
+			runner, err := hooks.New(file.Hooks, logger)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			retention, err := recordings.PolicyFromConfig(file.Recordings)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			backends, err := record.CommandBackends(file.Backends)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
 			app.logger = logger
+			app.hooks = runner
+			app.retention = retention
+			app.backends = backends
+
+			notifier, err := app.newNotifier()
+			if err != nil {
+				return fmt.Errorf("invalid notification options: %w", err)
+			}
+			if app.notify {
+				app.notifyFn = notifier.Send
+			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, _ []string) error {
@@ -87,22 +176,38 @@ func NewRootCmd() *cobra.Command {
 		},
 	}
 
+	withConfig(cmd)
 	cmd.SetVersionTemplate("{{.Name}} v{{.Version}}\n")
 
 	bindLoggingFlags(cmd, app)
 	bindProgressFlag(cmd, app)
 	bindModelFlags(cmd, app)
 	bindLanguageAndModelDownloadFlags(cmd, app)
+	bindEngineFlags(cmd, app)
+	bindDecodingFlags(cmd, app)
+	bindLongAudioFlags(cmd, app)
 	bindRecordingBackendFlags(cmd, app)
 	bindCopyAndSilenceFlags(cmd, app)
+	bindClipboardFlags(cmd, app)
+	bindOutputFlags(cmd, app, "stdout and clipboard")
+	bindNotifyFlags(cmd, app)
 	cmd.Flags().DurationVar(&app.duration, "duration", 0, "Record duration, e.g. 10s; 0 means interactive start/stop")
 	cmd.Flags().BoolVar(&app.immediate, "immediate", false, "Start recording immediately without waiting for Enter")
-	cmd.Flags().StringVar(&app.pidFile, "pid-file", "", "Write PID to file and wait for SIGUSR1 to stop recording")
-
-	cmd.AddCommand(newRecordCmd(app))
-	cmd.AddCommand(newTranscribeCmd(app))
-	cmd.AddCommand(newDevicesCmd(app))
-	cmd.AddCommand(newSetupCmd(app))
+	cmd.Flags().StringVar(&app.pidFile, "pid-file", "", "Write PID to file and wait for SIGUSR1 to stop recording; SIGUSR2 pauses and resumes")
+	cmd.Flags().BoolVar(&app.keepAudio, "keep-audio", false, "Keep the recording in the recordings directory instead of deleting it after transcription")
+
+	cmd.AddCommand(withConfig(newRecordCmd(app)))
+	cmd.AddCommand(newPauseCmd(app))
+	cmd.AddCommand(newResumeCmd(app))
+	cmd.AddCommand(withConfig(newTranscribeCmd(app)))
+	cmd.AddCommand(withConfig(newLiveCmd(app)))
+	cmd.AddCommand(withConfig(newDevicesCmd(app)))
+	cmd.AddCommand(withConfig(newEnginesCmd(app)))
+	cmd.AddCommand(withConfig(newDoctorCmd(app)))
+	cmd.AddCommand(newClipboardCmd(app))
+	cmd.AddCommand(newRecordingsCmd(app))
+	cmd.AddCommand(newAudioCmd())
+	cmd.AddCommand(withConfig(newSetupCmd(app)))
 	cmd.AddCommand(newVersionCmd())
 
 	return cmd
@@ -114,7 +219,7 @@ func bindLoggingFlags(cmd *cobra.Command, app *appState) {
 }
 
 func bindProgressFlag(cmd *cobra.Command, app *appState) {
-	cmd.Flags().BoolVar(&app.noProgress, "no-progress", app.noProgress, "Disable progress indicators")
+	cmd.Flags().BoolVar(&app.noProgress, "no-progress", app.noProgress, "Disable progress indicators and the recording level meter")
 }
 
 func bindModelFlags(cmd *cobra.Command, app *appState) {
@@ -124,33 +229,60 @@ func bindModelFlags(cmd *cobra.Command, app *appState) {
 
 func bindLanguageAndModelDownloadFlags(cmd *cobra.Command, app *appState) {
 	cmd.Flags().StringVar(&app.language, "language", app.language, "Language code (auto|en|de|...) for transcription")
+	cmd.Flags().StringSliceVar(&app.languages, "languages", app.languages, "Restrict auto-detection to these languages, e.g. en,de; the most likely of them is used when detection picks another, the first without per-language scores")
 	cmd.Flags().BoolVar(&app.autoDownload, "auto-download", app.autoDownload, "Automatically download missing models")
 }
 
+func bindDecodingFlags(cmd *cobra.Command, app *appState) {
+	d := &app.decoding
+	cmd.Flags().IntVar(&d.Threads, "threads", d.Threads, "Number of whisper threads; 0 uses the whisper default")
+	cmd.Flags().IntVar(&d.BeamSize, "beam-size", d.BeamSize, "Beam search size; 0 uses the whisper default")
+	cmd.Flags().IntVar(&d.BestOf, "best-of", d.BestOf, "Number of candidates when sampling; 0 uses the whisper default")
+	cmd.Flags().Float64Var(&d.Temperature, "temperature", d.Temperature, "Initial sampling temperature between 0 and 1")
+	cmd.Flags().Float64Var(&d.TemperatureIncrement, "temperature-increment", d.TemperatureIncrement, "Temperature step for decoding fallback; 0 uses the whisper default")
+	cmd.Flags().BoolVar(&d.NoFallback, "no-fallback", d.NoFallback, "Disable temperature fallback while decoding")
+	cmd.Flags().Float64Var(&d.EntropyThreshold, "entropy-threshold", d.EntropyThreshold, "Entropy threshold for decoder fallback; 0 uses the whisper default")
+	cmd.Flags().Float64Var(&d.LogprobThreshold, "logprob-threshold", d.LogprobThreshold, "Average log probability threshold for decoder fallback; 0 uses the whisper default")
+	cmd.Flags().IntVar(&d.MaxSegmentLength, "max-segment-length", d.MaxSegmentLength, "Maximum segment length in characters; 0 means no limit")
+	cmd.Flags().BoolVar(&d.SplitOnWord, "split-on-word", d.SplitOnWord, "Split segments on words rather than tokens")
+	cmd.Flags().StringArrayVar(&d.ExtraArgs, "whisper-arg", d.ExtraArgs, "Extra argument passed verbatim to whisper-cli (repeatable)")
+}
+
 func bindRecordingBackendFlags(cmd *cobra.Command, app *appState) {
-	cmd.Flags().StringVar(&app.backend, "backend", app.backend, "Recording backend: auto|pw-record|arecord|ffmpeg")
-	cmd.Flags().StringVar(&app.input, "input", app.input, "Input device (run \"voxclip devices\" to list); e.g. node-ID (pw-record), hw:1,0 (arecord), :1 (ffmpeg)")
+	cmd.Flags().StringVar(&app.backend, "backend", app.backend, "Recording backend: auto|pw-record|parec|arecord|ffmpeg|sox, or file:<wav> to replay a WAV file in real time instead of recording")
+	cmd.Flags().StringVar(&app.input, "input", app.input, "Input device (run \"voxclip devices\" to list), or name:<words> to match a device name, e.g. \"name:Blue Yeti\"; IDs are e.g. node name (pw-record), source name (parec), hw:1,0 (arecord), :1 (ffmpeg), device name (sox)")
 	cmd.Flags().StringVar(&app.inputFormat, "input-format", app.inputFormat, "Input format for ffmpeg backend (pulse|alsa)")
+	cmd.Flags().IntVar(&app.highPass, "highpass", app.highPass, "High-pass filter cutoff in Hz applied while recording, e.g. 80; sox backend only, 0 disables it")
 }
 
 func bindCopyAndSilenceFlags(cmd *cobra.Command, app *appState) {
-	cmd.Flags().BoolVar(&app.copyEmpty, "copy-empty", app.copyEmpty, "Copy blank transcripts to clipboard")
-	cmd.Flags().BoolVar(&app.copyNewline, "copy-newline", app.copyNewline, "Append a trailing newline to the clipboard text")
+	cmd.Flags().BoolVar(&app.copyEmpty, "copy-empty", app.copyEmpty, "Copy blank transcripts to clipboard and the other non-stdout outputs")
+	cmd.Flags().BoolVar(&app.copyNewline, "copy-newline", app.copyNewline, "Append a trailing newline to the clipboard, typed and exec output text")
+	cmd.Flags().DurationVar(&app.restoreAfter, "restore-clipboard", app.restoreAfter, "Put the previous clipboard text back after this delay, e.g. 2s; 0 keeps the transcript")
 	cmd.Flags().BoolVar(&app.silenceGate, "silence-gate", app.silenceGate, "Detect near-silent WAV audio and skip transcription")
 	cmd.Flags().Float64Var(&app.silenceDBFS, "silence-threshold-dbfs", app.silenceDBFS, "Silence gate threshold in dBFS")
 }
 
 func (a *appState) ensureTranscriptionReady(ctx context.Context) error {
-	if _, err := whisper.NewBundledEngine(a.log()); err != nil {
+	spec, err := lookupEngine(a.engine)
+	if err != nil {
 		return err
 	}
-	if _, err := a.ensureModelAvailable(ctx); err != nil {
-		return err
+
+	var modelPath string
+	if spec.localModel {
+		model, err := a.ensureModelAvailable(ctx)
+		if err != nil {
+			return err
+		}
+		modelPath = model.Path
 	}
-	return nil
+	return a.preflightEngine(ctx, spec, modelPath)
 }
 
-func (a *appState) runDefault(ctx context.Context) error {
+func (a *appState) runDefault(ctx context.Context) (err error) {
+	defer func() { a.reportFailure(ctx, err) }()
+
 	preflightFn := a.preflightFn
 	if preflightFn == nil {
 		preflightFn = a.ensureTranscriptionReady
@@ -166,9 +298,9 @@ func (a *appState) runDefault(ctx context.Context) error {
 		transcribeFn = a.transcribeAudio
 	}
 
-	copyFn := a.copyFn
-	if copyFn == nil {
-		copyFn = clipboard.CopyText
+	sinks, err := a.outputSinks(outputStdout, outputClipboard)
+	if err != nil {
+		return err
 	}
 
 	if err := preflightFn(ctx); err != nil {
@@ -180,6 +312,10 @@ func (a *appState) runDefault(ctx context.Context) error {
 		return err
 	}
 	defer func() {
+		if a.keepAudio {
+			a.log().Info("recording kept", zap.String("path", audioPath))
+			return
+		}
 		if err := os.Remove(audioPath); err != nil {
 			a.log().Warn("failed to remove recording", zap.String("path", audioPath), zap.Error(err))
 		}
@@ -189,36 +325,18 @@ func (a *appState) runDefault(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	started := time.Now()
 	if !skipped {
 		transcript, err = transcribeFn(ctx, audioPath)
 		if err != nil {
 			return err
 		}
 	}
+	a.runTranscribeDoneHook(ctx, audioPath, transcript, time.Since(started))
 
-	fmt.Fprintln(a.outWriter(), transcript)
-	if isBlankTranscript(transcript) {
-		a.log().Warn(noSpeechHint())
-		if !a.copyEmpty {
-			return nil
-		}
-	}
-
-	clipText := transcript
-	if a.copyNewline {
-		clipText += "\n"
-	}
-
-	if err := copyFn(ctx, clipText); err != nil {
-		if errors.Is(err, clipboard.ErrUnavailable) {
-			a.log().Warn("clipboard tool unavailable; transcript left on stdout")
-			return nil
-		}
-		a.log().Warn("failed to copy transcript to clipboard; transcript left on stdout", zap.Error(err))
-		return nil
-	}
-
-	a.log().Info("transcript copied to clipboard")
+	// Output failures are already reported as warnings and must not turn a
+	// finished transcription into an error exit.
+	_ = a.deliverTranscript(ctx, sinks, transcript)
 	return nil
 }
 
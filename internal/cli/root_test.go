@@ -19,11 +19,23 @@ func TestRootCommandRegistersCoreSubcommands(t *testing.T) {
 	require.NotNil(t, cmd.Flags().Lookup("model"))
 	require.NotNil(t, cmd.Flags().Lookup("model-dir"))
 	require.NotNil(t, cmd.Flags().Lookup("language"))
+	require.NotNil(t, cmd.Flags().Lookup("languages"))
+	require.NotNil(t, cmd.Flags().Lookup("threads"))
+	require.NotNil(t, cmd.Flags().Lookup("whisper-arg"))
+	require.NotNil(t, cmd.Flags().Lookup("engine"))
+	require.NotNil(t, cmd.Flags().Lookup("server-addr"))
+	require.NotNil(t, cmd.Flags().Lookup("long-audio"))
+	require.NotNil(t, cmd.Flags().Lookup("chunk-workers"))
 	require.NotNil(t, cmd.Flags().Lookup("auto-download"))
 	require.NotNil(t, cmd.Flags().Lookup("backend"))
 	require.NotNil(t, cmd.Flags().Lookup("input"))
 	require.NotNil(t, cmd.Flags().Lookup("input-format"))
 	require.NotNil(t, cmd.Flags().Lookup("copy-empty"))
+	require.NotNil(t, cmd.Flags().Lookup("clipboard-target"))
+	require.NotNil(t, cmd.Flags().Lookup("clipboard-command"))
+	require.NotNil(t, cmd.Flags().Lookup("restore-clipboard"))
+	require.NotNil(t, cmd.Flags().Lookup("output-to"))
+	require.NotNil(t, cmd.Flags().Lookup("type-delay"))
 	require.NotNil(t, cmd.Flags().Lookup("silence-gate"))
 	require.NotNil(t, cmd.Flags().Lookup("silence-threshold-dbfs"))
 	require.Equal(t, "true", cmd.Flags().Lookup("auto-download").DefValue)
@@ -56,6 +68,9 @@ func TestRootHelpParsesSuccessfully(t *testing.T) {
 	require.Contains(t, out.String(), "transcribe")
 	require.Contains(t, out.String(), "setup")
 	require.Contains(t, out.String(), "devices")
+	require.Contains(t, out.String(), "engines")
+	require.Contains(t, out.String(), "live")
+	require.Contains(t, out.String(), "doctor")
 	require.Contains(t, out.String(), "version")
 }
 
@@ -70,6 +85,10 @@ func TestSubcommandHelpParsesSuccessfully(t *testing.T) {
 		{name: "record", args: []string{"record", "--help"}, contains: "Record audio into a WAV file"},
 		{name: "transcribe", args: []string{"transcribe", "--help"}, contains: "Transcribe an audio file"},
 		{name: "devices", args: []string{"devices", "--help"}, contains: "List recording devices"},
+		{name: "engines", args: []string{"engines", "--help"}, contains: "List transcription engines"},
+		{name: "live", args: []string{"live", "--help"}, contains: "Record and transcribe continuously"},
+		{name: "doctor", args: []string{"doctor", "--help"}, contains: "Check which recording backend"},
+		{name: "clipboard restore", args: []string{"clipboard", "restore", "--help"}, contains: "Put back the clipboard contents"},
 		{name: "setup", args: []string{"setup", "--help"}, contains: "Download and verify speech model assets"},
 		{name: "version", args: []string{"version", "--help"}, contains: "Print the version number"},
 	}
@@ -103,6 +122,9 @@ func TestSubcommandsRejectIrrelevantFlags(t *testing.T) {
 		{name: "transcribe rejects backend", args: []string{"transcribe", "--backend", "auto", "/tmp/audio.wav"}},
 		{name: "setup rejects language", args: []string{"setup", "--language", "de"}},
 		{name: "devices rejects verbose", args: []string{"devices", "--verbose"}},
+		{name: "engines rejects backend", args: []string{"engines", "--backend", "auto"}},
+		{name: "live rejects output", args: []string{"live", "--output", "/tmp/audio.wav"}},
+		{name: "doctor rejects language", args: []string{"doctor", "--language", "de"}},
 		{name: "transcribe rejects pid-file", args: []string{"transcribe", "--pid-file", "/tmp/x.pid", "/tmp/audio.wav"}},
 		{name: "setup rejects pid-file", args: []string{"setup", "--pid-file", "/tmp/x.pid"}},
 	}
@@ -251,3 +273,13 @@ func TestVersionCommandOutputMatchesFlag(t *testing.T) {
 
 	require.Equal(t, flagOut.String(), subOut.String())
 }
+
+func TestInvalidOutputToIsRejected(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := runCommand(t, []string{"--output-to", "paste"})
+	require.ErrorContains(t, err, `unknown --output-to "paste"`)
+
+	_, _, err = runCommand(t, []string{"--output-to", "type", "--type-newline", "tab"})
+	require.ErrorContains(t, err, "invalid typing options")
+}
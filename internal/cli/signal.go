@@ -40,3 +40,30 @@ func registerStopSignal(stopCh chan struct{}, logger *zap.Logger) func() {
 		close(sigCh)
 	}
 }
+
+// registerPauseSignal sends on toggleCh for every SIGUSR2, which pauses or
+// resumes the recording, until the returned cleanup function is called.
+func registerPauseSignal(toggleCh chan<- struct{}, logger *zap.Logger) func() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR2)
+
+	go func() {
+		for sig := range sigCh {
+			logger.Debug(fmt.Sprintf("received %s; pausing or resuming recording", sig))
+			select {
+			case toggleCh <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigCh)
+		close(sigCh)
+	}
+}
+
+// signalPauseToggle pauses or resumes the recording of process pid.
+func signalPauseToggle(pid int) error {
+	return syscall.Kill(pid, syscall.SIGUSR2)
+}
@@ -3,13 +3,16 @@
 package cli
 
 import (
+	"context"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/fmueller/voxclip/internal/audio"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
@@ -58,3 +61,89 @@ func TestRegisterStopSignalClosesChannelOnSIGUSR1(t *testing.T) {
 		t.Fatal("stopCh was not closed within timeout after SIGUSR1")
 	}
 }
+
+func TestRegisterPauseSignalTogglesOnSIGUSR2(t *testing.T) {
+	toggleCh := make(chan struct{}, 1)
+	cleanup := registerPauseSignal(toggleCh, zap.NewNop())
+	defer cleanup()
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+
+	select {
+	case <-toggleCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no toggle within timeout after SIGUSR2")
+	}
+}
+
+func TestPauseAndResumeCommandsSignalRecording(t *testing.T) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR2)
+	defer signal.Stop(sigCh)
+
+	pidFile := filepath.Join(t.TempDir(), "voxclip.pid")
+	require.NoError(t, writePIDFile(pidFile))
+
+	stdout, _, err := runCommand(t, []string{"pause", "--pid-file", pidFile})
+	require.NoError(t, err)
+	require.Equal(t, "recording paused\n", stdout)
+	select {
+	case <-sigCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("pause did not send SIGUSR2")
+	}
+
+	require.NoError(t, os.WriteFile(pausedMarker(pidFile), nil, 0o644))
+	stdout, _, err = runCommand(t, []string{"resume", "--pid-file", pidFile})
+	require.NoError(t, err)
+	require.Equal(t, "recording resumed\n", stdout)
+	select {
+	case <-sigCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("resume did not send SIGUSR2")
+	}
+}
+
+func TestRecordAudioPausesOnSIGUSR2(t *testing.T) {
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "voxclip.pid")
+	app := &appState{backend: "file:" + writeToneWAV(t), pidFile: pidFile, noProgress: true}
+
+	type result struct {
+		path string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		path, err := app.recordAudio(context.Background(), recordOptions{output: filepath.Join(dir, "out", "recording.wav")})
+		done <- result{path: path, err: err}
+	}()
+
+	waitForFile := func(path string, exists bool) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(path)
+			return (err == nil) == exists
+		}, 2*time.Second, 10*time.Millisecond)
+	}
+	waitForFile(pidFile, true)
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+	waitForFile(pausedMarker(pidFile), true)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+	waitForFile(pausedMarker(pidFile), false)
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+	r := <-done
+	require.NoError(t, r.err)
+	duration, err := audio.WAVDuration(r.path)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, duration, 300*time.Millisecond)
+	require.NoFileExists(t, pidFile)
+	require.NoFileExists(t, pausedMarker(pidFile))
+
+	entries, err := os.ReadDir(filepath.Dir(r.path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
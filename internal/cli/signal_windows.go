@@ -18,3 +18,11 @@ func registerStopSignal(stopCh chan struct{}, logger *zap.Logger) func() {
 	logger.Warn("signal-based stop is not supported on Windows")
 	return func() {}
 }
+
+func registerPauseSignal(toggleCh chan<- struct{}, logger *zap.Logger) func() {
+	return func() {}
+}
+
+func signalPauseToggle(pid int) error {
+	return fmt.Errorf("pausing a recording is not supported on Windows")
+}
@@ -3,12 +3,12 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/fmueller/voxclip/internal/clipboard"
 	"github.com/fmueller/voxclip/internal/download"
 	"github.com/fmueller/voxclip/internal/whisper"
 	"github.com/spf13/cobra"
@@ -22,42 +22,32 @@ func newTranscribeCmd(app *appState) *cobra.Command {
 		Use:   "transcribe <audio-file>",
 		Short: "Transcribe an audio file",
 		Args:  cobra.ExactArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			defer func() { app.reportFailure(cmd.Context(), err) }()
+
 			transcribeFn := app.transcribeFn
 			if transcribeFn == nil {
 				transcribeFn = app.transcribeAudio
 			}
 
-			copyFn := app.copyFn
-			if copyFn == nil {
-				copyFn = clipboard.CopyText
+			sinks, err := app.outputSinks(outputStdout)
+			if err != nil {
+				return err
+			}
+			if copyToClipboard {
+				clip, _ := app.outputSink(outputClipboard)
+				sinks = append(sinks, clip)
 			}
 
+			started := time.Now()
 			transcript, err := transcribeFn(cmd.Context(), args[0])
 			if err != nil {
 				return err
 			}
+			app.runTranscribeDoneHook(cmd.Context(), args[0], transcript, time.Since(started))
 
-			fmt.Fprintln(cmd.OutOrStdout(), transcript)
-			if isBlankTranscript(transcript) {
-				app.log().Warn(noSpeechHint())
-			}
-			if copyToClipboard {
-				if isBlankTranscript(transcript) && !app.copyEmpty {
-					return nil
-				}
-
-				clipText := transcript
-				if app.copyNewline {
-					clipText += "\n"
-				}
-
-				if err := copyFn(cmd.Context(), clipText); err != nil {
-					return err
-				}
-				app.log().Info("transcript copied to clipboard")
-			}
-			return nil
+			app.out = cmd.OutOrStdout()
+			return app.deliverTranscript(cmd.Context(), sinks, transcript)
 		},
 	}
 
@@ -65,7 +55,13 @@ func newTranscribeCmd(app *appState) *cobra.Command {
 	bindProgressFlag(cmd, app)
 	bindModelFlags(cmd, app)
 	bindLanguageAndModelDownloadFlags(cmd, app)
+	bindEngineFlags(cmd, app)
+	bindDecodingFlags(cmd, app)
+	bindLongAudioFlags(cmd, app)
 	bindCopyAndSilenceFlags(cmd, app)
+	bindClipboardFlags(cmd, app)
+	bindOutputFlags(cmd, app, "stdout, plus clipboard with --copy")
+	bindNotifyFlags(cmd, app)
 	cmd.Flags().BoolVar(&copyToClipboard, "copy", false, "Copy transcript to clipboard")
 	return cmd
 }
@@ -82,42 +78,104 @@ func (a *appState) transcribeAudio(ctx context.Context, audioPath string) (strin
 		return transcript, nil
 	}
 
-	model, err := a.ensureModelAvailable(ctx)
-	if err != nil {
-		return "", err
+	var modelPath string
+	modelLabel := a.remote.Model
+	if a.usesLocalModel() {
+		model, err := a.ensureModelAvailable(ctx)
+		if err != nil {
+			return "", err
+		}
+		modelPath = model.Path
+		modelLabel = model.Path
 	}
 
-	engine, err := whisper.NewBundledEngine(a.log())
+	engine, err := a.newEngine()
 	if err != nil {
 		return "", err
 	}
 
-	a.log().Info("transcribing...", zap.String("audio", audioPath), zap.String("model", model.Path), zap.String("language", a.language))
-	stopSpinner := startSpinner(os.Stderr, a.progressEnabled(), "Transcribing")
-	started := time.Now()
-
-	transcript, err := engine.Transcribe(ctx, whisper.TranscriptionRequest{
+	a.log().Info("transcribing...", zap.String("audio", audioPath), zap.String("engine", a.engine), zap.String("model", modelLabel), zap.String("language", a.language))
+	req := whisper.TranscriptionRequest{
 		AudioPath: audioPath,
-		ModelPath: model.Path,
+		ModelPath: modelPath,
 		Language:  a.language,
-	})
-	stopSpinner()
+		Decoding:  a.decoding,
+	}
+	started := time.Now()
+
+	var result whisper.Transcription
+	chunked := false
+	if a.longAudio.enabled {
+		result, chunked, err = a.transcribeLongAudio(ctx, engine, req)
+	}
+	if err == nil && !chunked {
+		result, err = a.transcribeWhole(ctx, engine, req)
+	}
 	if err != nil {
 		a.log().Warn("transcription failed", zap.Duration("elapsed", time.Since(started)), zap.Error(err))
 		return "", err
 	}
-	a.log().Info("transcription finished", zap.Duration("elapsed", time.Since(started)))
 
-	return transcript, nil
+	fields := []zap.Field{zap.Duration("elapsed", time.Since(started))}
+	if result.Language != "" {
+		fields = append(fields, zap.String("language", result.Language))
+	}
+	if result.LanguageProbability > 0 {
+		fields = append(fields, zap.Float64("language_probability", result.LanguageProbability))
+	}
+	a.log().Info("transcription finished", fields...)
+
+	return result.Text, nil
 }
 
-func (a *appState) ensureModelAvailable(ctx context.Context) (whisper.ResolvedModel, error) {
+func (a *appState) transcribeWhole(ctx context.Context, engine whisper.Engine, req whisper.TranscriptionRequest) (whisper.Transcription, error) {
+	var stopProgress stopFunc
+	if reporter, ok := engine.(whisper.ProgressReporter); ok && reporter.ReportsProgress() {
+		engine, stopProgress = percentProgressPerPass(os.Stderr, a.progressEnabled(), engine)
+	} else {
+		stopProgress = startSpinner(os.Stderr, a.progressEnabled(), "Transcribing")
+	}
+	defer stopProgress()
+
+	return whisper.TranscribeWithAllowedLanguages(ctx, engine, req, a.languages, a.log())
+}
+
+// percentProgressPerPass wraps engine so every transcription pass gets its
+// own percent bar. When the language allowlist makes whisper transcribe
+// again, the retry starts a new bar with its own label and ETA instead of
+// the finished one dropping back to 0%.
+func percentProgressPerPass(w io.Writer, enabled bool, engine whisper.Engine) (whisper.Engine, stopFunc) {
+	stopProgress := stopFunc(func() {})
+	passes := 0
+
+	wrapped := whisper.EngineFunc(func(ctx context.Context, req whisper.TranscriptionRequest) (whisper.Transcription, error) {
+		description := "Transcribing"
+		if passes > 0 {
+			description = fmt.Sprintf("Retrying in %s", req.Language)
+		}
+		passes++
+
+		stopProgress()
+		var report func(int)
+		report, stopProgress = startPercentProgress(w, enabled, description)
+		if enabled {
+			req.Progress = report
+		}
+		return engine.Transcribe(ctx, req)
+	})
+	return wrapped, func() { stopProgress() }
+}
+
+func (a *appState) resolveModel() (whisper.ResolvedModel, error) {
 	modelDir, err := a.modelStorageDir()
 	if err != nil {
 		return whisper.ResolvedModel{}, err
 	}
+	return whisper.ResolveModel(a.model, modelDir)
+}
 
-	resolved, err := whisper.ResolveModel(a.model, modelDir)
+func (a *appState) ensureModelAvailable(ctx context.Context) (whisper.ResolvedModel, error) {
+	resolved, err := a.resolveModel()
 	if err != nil {
 		return whisper.ResolvedModel{}, err
 	}
@@ -149,7 +207,7 @@ func (a *appState) ensureModelAvailable(ctx context.Context) (whisper.ResolvedMo
 func sanitizeLanguage(input string) string {
 	trimmed := strings.TrimSpace(strings.ToLower(input))
 	if trimmed == "" {
-		return "auto"
+		return whisper.AutoLanguage
 	}
 	return trimmed
 }
@@ -2,41 +2,309 @@ package clipboard
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
+
+	"go.uber.org/zap"
 )
 
 var ErrUnavailable = errors.New("no clipboard command available")
 
+const (
+	TargetClipboard = "clipboard"
+	TargetPrimary   = "primary"
+	TargetBoth      = "both"
+
+	ToolAuto    = "auto"
+	ToolPbcopy  = "pbcopy"
+	ToolWlCopy  = "wl-copy"
+	ToolXclip   = "xclip"
+	ToolXsel    = "xsel"
+	ToolOSC52   = "osc52"
+	ToolCommand = "command"
+
+	// commandTargetPlaceholder is replaced with the selection name in a
+	// user-configured command template.
+	commandTargetPlaceholder = "{target}"
+)
+
+// Targets returns the valid values for Options.Target.
+func Targets() []string {
+	return []string{TargetClipboard, TargetPrimary, TargetBoth}
+}
+
+// Tools returns the valid values for Options.Tool.
+func Tools() []string {
+	return []string{ToolAuto, ToolWlCopy, ToolXclip, ToolXsel, ToolPbcopy, ToolOSC52, ToolCommand}
+}
+
+// Options selects where and how text is copied.
+type Options struct {
+	// Target is the selection to write: clipboard, primary or both.
+	// Defaults to clipboard.
+	Target string
+	// Tool forces a copy mechanism; auto picks the first available one.
+	Tool string
+	// Command is a shell command template that receives the text on stdin.
+	// {target} is replaced with "clipboard" or "primary". When set, auto
+	// uses it before any built-in tool.
+	Command string
+	Logger  *zap.Logger
+}
+
 type commandSpec struct {
 	name      string
 	args      []string
 	asyncFire bool
 }
 
+// Copier copies text with the tool resolved from Options.
+type Copier struct {
+	tool    string
+	targets []string
+	command string
+	logger  *zap.Logger
+
+	lookPath func(string) (string, error)
+	// terminal opens the terminal OSC 52 sequences are written to.
+	terminal func() (io.WriteCloser, error)
+	getenv   func(string) string
+	goos     string
+}
+
+// New validates opts and returns a Copier. The tool is resolved on each copy,
+// so a clipboard tool installed later is picked up.
+func New(opts Options) (*Copier, error) {
+	target := strings.TrimSpace(opts.Target)
+	if target == "" {
+		target = TargetClipboard
+	}
+	var targets []string
+	switch target {
+	case TargetClipboard, TargetPrimary:
+		targets = []string{target}
+	case TargetBoth:
+		targets = []string{TargetClipboard, TargetPrimary}
+	default:
+		return nil, fmt.Errorf("unknown clipboard target %q (valid: %s)", target, strings.Join(Targets(), ", "))
+	}
+
+	tool := strings.TrimSpace(opts.Tool)
+	if tool == "" {
+		tool = ToolAuto
+	}
+	if !slices.Contains(Tools(), tool) {
+		return nil, fmt.Errorf("unknown clipboard tool %q (valid: %s)", tool, strings.Join(Tools(), ", "))
+	}
+	if tool == ToolCommand && strings.TrimSpace(opts.Command) == "" {
+		return nil, errors.New("clipboard tool \"command\" requires a clipboard command template")
+	}
+	// Reject what the tool cannot write before anything is copied, so a copy
+	// never half succeeds.
+	for _, selection := range targets {
+		if !supportsTarget(tool, selection) {
+			return nil, fmt.Errorf("clipboard tool %q cannot write the %s selection (target %q)", tool, selection, target)
+		}
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &Copier{
+		tool:     tool,
+		targets:  targets,
+		command:  strings.TrimSpace(opts.Command),
+		logger:   logger,
+		lookPath: exec.LookPath,
+		terminal: openTerminal,
+		getenv:   os.Getenv,
+		goos:     runtime.GOOS,
+	}, nil
+}
+
+// CopyText copies value to the clipboard with the automatically detected
+// tool.
 func CopyText(ctx context.Context, value string) error {
+	copier, err := New(Options{})
+	if err != nil {
+		return err
+	}
+	return copier.Copy(ctx, value)
+}
+
+// Tool returns the name of the tool Copy would use, or ErrUnavailable.
+func (c *Copier) Tool() (string, error) {
+	return c.resolve()
+}
+
+// Targets returns the selections Copy writes.
+func (c *Copier) Targets() []string {
+	return c.targets
+}
+
+// Copy writes value to every configured selection.
+func (c *Copier) Copy(ctx context.Context, value string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
-	cmdSpec, err := detectCommand()
+	tool, err := c.resolve()
 	if err != nil {
 		return err
 	}
+	c.logger.Debug("copying with clipboard tool", zap.String("tool", tool), zap.Strings("targets", c.targets))
+
+	if tool == ToolOSC52 {
+		return c.copyOSC52(value)
+	}
+
+	for _, target := range c.targets {
+		spec, err := c.commandFor(tool, target)
+		if err != nil {
+			return err
+		}
+		if err := runCopyCommand(ctx, spec, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the forced tool, or the first available one in the order
+// command template, platform tools, OSC 52.
+func (c *Copier) resolve() (string, error) {
+	if c.tool != ToolAuto {
+		if c.tool != ToolOSC52 && c.tool != ToolCommand {
+			if _, err := c.lookPath(c.tool); err != nil {
+				return "", fmt.Errorf("%w: %s not found in PATH", ErrUnavailable, c.tool)
+			}
+		}
+		return c.tool, nil
+	}
+
+	if c.command != "" {
+		return ToolCommand, nil
+	}
+
+	candidates := []string{ToolWlCopy, ToolXclip, ToolXsel}
+	if c.goos == "darwin" {
+		candidates = []string{ToolPbcopy}
+	}
+	for _, candidate := range candidates {
+		if !c.supportsTargets(candidate) {
+			continue
+		}
+		if _, err := c.lookPath(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	// Over SSH or in a bare tmux session there is no clipboard tool, but the
+	// terminal emulator on the other end can still set the clipboard.
+	if tty, err := c.terminal(); err == nil {
+		_ = tty.Close()
+		return ToolOSC52, nil
+	}
+	return "", ErrUnavailable
+}
+
+// supportsTarget reports whether tool can write the target selection. macOS
+// has no primary selection, so pbcopy only writes the clipboard.
+func supportsTarget(tool, target string) bool {
+	return tool != ToolPbcopy || target != TargetPrimary
+}
+
+func (c *Copier) supportsTargets(tool string) bool {
+	for _, target := range c.targets {
+		if !supportsTarget(tool, target) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Copier) commandFor(tool, target string) (commandSpec, error) {
+	switch tool {
+	case ToolCommand:
+		script := strings.ReplaceAll(c.command, commandTargetPlaceholder, target)
+		return commandSpec{name: "sh", args: []string{"-c", script}}, nil
+	case ToolWlCopy:
+		if target == TargetPrimary {
+			return commandSpec{name: ToolWlCopy, args: []string{"--primary"}}, nil
+		}
+		return commandSpec{name: ToolWlCopy}, nil
+	case ToolXclip:
+		return commandSpec{name: ToolXclip, args: []string{"-selection", target, "-in", "-silent"}, asyncFire: true}, nil
+	case ToolXsel:
+		// xsel forks to keep serving the selection; waiting for its output
+		// would block until the selection is taken over.
+		return commandSpec{name: ToolXsel, args: []string{"--" + target, "--input"}, asyncFire: true}, nil
+	case ToolPbcopy:
+		if target == TargetPrimary {
+			return commandSpec{}, errors.New("the primary selection is not available on macOS")
+		}
+		return commandSpec{name: ToolPbcopy}, nil
+	default:
+		return commandSpec{}, fmt.Errorf("unknown clipboard tool %q", tool)
+	}
+}
+
+// copyOSC52 asks the terminal emulator to set the selections. Inside tmux the
+// sequence is wrapped so tmux passes it through to the outer terminal.
+func (c *Copier) copyOSC52(value string) error {
+	tty, err := c.terminal()
+	if err != nil {
+		return fmt.Errorf("%w: open terminal for OSC 52: %v", ErrUnavailable, err)
+	}
+	defer tty.Close()
+
+	var selections string
+	for _, target := range c.targets {
+		if target == TargetPrimary {
+			selections += "p"
+		} else {
+			selections += "c"
+		}
+	}
+
+	sequence := osc52Sequence(selections, value, c.getenv("TMUX") != "")
+	if _, err := io.WriteString(tty, sequence); err != nil {
+		return fmt.Errorf("write OSC 52 sequence: %w", err)
+	}
+	return nil
+}
+
+func osc52Sequence(selections, value string, tmux bool) string {
+	sequence := "\033]52;" + selections + ";" + base64.StdEncoding.EncodeToString([]byte(value)) + "\a"
+	if tmux {
+		return "\033Ptmux;" + strings.ReplaceAll(sequence, "\033", "\033\033") + "\033\\"
+	}
+	return sequence
+}
 
+func openTerminal() (io.WriteCloser, error) {
+	return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+}
+
+func runCopyCommand(ctx context.Context, spec commandSpec, value string) error {
 	copyCtx, cancel := context.WithTimeout(ctx, 4*time.Second)
 	defer cancel()
 
-	if cmdSpec.asyncFire {
-		return copyWithDetachedCommand(cmdSpec, value)
+	if spec.asyncFire {
+		return copyWithDetachedCommand(spec, value)
 	}
 
-	cmd := exec.CommandContext(copyCtx, cmdSpec.name, cmdSpec.args...)
+	cmd := exec.CommandContext(copyCtx, spec.name, spec.args...)
 	cmd.Stdin = strings.NewReader(value)
 	cmd.Stdout = io.Discard
 	cmd.Stderr = io.Discard
@@ -51,25 +319,6 @@ func CopyText(ctx context.Context, value string) error {
 	return nil
 }
 
-func detectCommand() (commandSpec, error) {
-	if runtime.GOOS == "darwin" {
-		if _, err := exec.LookPath("pbcopy"); err == nil {
-			return commandSpec{name: "pbcopy"}, nil
-		}
-		return commandSpec{}, ErrUnavailable
-	}
-
-	if _, err := exec.LookPath("wl-copy"); err == nil {
-		return commandSpec{name: "wl-copy"}, nil
-	}
-
-	if _, err := exec.LookPath("xclip"); err == nil {
-		return commandSpec{name: "xclip", args: []string{"-selection", "clipboard", "-in", "-silent"}, asyncFire: true}, nil
-	}
-
-	return commandSpec{}, ErrUnavailable
-}
-
 func copyWithDetachedCommand(spec commandSpec, value string) error {
 	cmd := exec.Command(spec.name, spec.args...)
 	cmd.Stdout = io.Discard
@@ -0,0 +1,245 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTerminal struct {
+	bytes.Buffer
+}
+
+func (f *fakeTerminal) Close() error { return nil }
+
+func newTestCopier(t *testing.T, opts Options, installed ...string) (*Copier, *fakeTerminal) {
+	t.Helper()
+
+	copier, err := New(opts)
+	require.NoError(t, err)
+
+	tty := &fakeTerminal{}
+	copier.goos = "linux"
+	copier.lookPath = func(name string) (string, error) {
+		for _, tool := range installed {
+			if tool == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+	copier.terminal = func() (io.WriteCloser, error) { return tty, nil }
+	copier.getenv = func(string) string { return "" }
+	return copier, tty
+}
+
+func TestResolvePrefersPlatformToolsInOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      Options
+		installed []string
+		want      string
+	}{
+		{name: "wayland first", installed: []string{ToolXsel, ToolXclip, ToolWlCopy}, want: ToolWlCopy},
+		{name: "xclip before xsel", installed: []string{ToolXsel, ToolXclip}, want: ToolXclip},
+		{name: "xsel", installed: []string{ToolXsel}, want: ToolXsel},
+		{name: "osc52 without tools", want: ToolOSC52},
+		{name: "command template first", opts: Options{Command: "tmux load-buffer -"}, installed: []string{ToolWlCopy}, want: ToolCommand},
+		{name: "forced tool", opts: Options{Tool: ToolOSC52}, installed: []string{ToolWlCopy}, want: ToolOSC52},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			copier, _ := newTestCopier(t, tt.opts, tt.installed...)
+			got, err := copier.Tool()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolveReportsMissingTools(t *testing.T) {
+	t.Parallel()
+
+	copier, _ := newTestCopier(t, Options{Tool: ToolXsel})
+	_, err := copier.Tool()
+	require.ErrorIs(t, err, ErrUnavailable)
+	require.ErrorContains(t, err, "xsel not found")
+
+	copier, _ = newTestCopier(t, Options{})
+	copier.terminal = func() (io.WriteCloser, error) { return nil, os.ErrNotExist }
+	_, err = copier.Tool()
+	require.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestCommandForTargets(t *testing.T) {
+	t.Parallel()
+
+	copier, _ := newTestCopier(t, Options{Command: "copy-to --sel {target}"})
+
+	tests := []struct {
+		tool   string
+		target string
+		want   commandSpec
+	}{
+		{tool: ToolWlCopy, target: TargetClipboard, want: commandSpec{name: "wl-copy"}},
+		{tool: ToolWlCopy, target: TargetPrimary, want: commandSpec{name: "wl-copy", args: []string{"--primary"}}},
+		{tool: ToolXclip, target: TargetPrimary, want: commandSpec{name: "xclip", args: []string{"-selection", "primary", "-in", "-silent"}, asyncFire: true}},
+		{tool: ToolXsel, target: TargetClipboard, want: commandSpec{name: "xsel", args: []string{"--clipboard", "--input"}, asyncFire: true}},
+		{tool: ToolPbcopy, target: TargetClipboard, want: commandSpec{name: "pbcopy"}},
+		{tool: ToolCommand, target: TargetPrimary, want: commandSpec{name: "sh", args: []string{"-c", "copy-to --sel primary"}}},
+	}
+	for _, tt := range tests {
+		got, err := copier.commandFor(tt.tool, tt.target)
+		require.NoError(t, err, tt.tool)
+		require.Equal(t, tt.want, got, tt.tool)
+	}
+
+	_, err := copier.commandFor(ToolPbcopy, TargetPrimary)
+	require.ErrorContains(t, err, "primary selection")
+}
+
+func TestCopyWritesOSC52Sequence(t *testing.T) {
+	t.Parallel()
+
+	copier, tty := newTestCopier(t, Options{Target: TargetBoth})
+	require.NoError(t, copier.Copy(context.Background(), "hi"))
+	require.Equal(t, "\033]52;cp;aGk=\a", tty.String())
+}
+
+func TestOSC52SequenceInsideTmux(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "\033Ptmux;\033\033]52;c;aGk=\a\033\\", osc52Sequence("c", "hi", true))
+}
+
+func TestCopyRunsCommandTemplateForEachTarget(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("command templates run through sh")
+	}
+
+	dir := t.TempDir()
+	copier, _ := newTestCopier(t, Options{Target: TargetBoth, Command: "cat > " + dir + "/{target}.txt"})
+	require.NoError(t, copier.Copy(context.Background(), "hello"))
+
+	for _, target := range []string{TargetClipboard, TargetPrimary} {
+		got, err := os.ReadFile(filepath.Join(dir, target+".txt"))
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(got))
+	}
+}
+
+func TestNewValidatesOptions(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(Options{Target: "secondary"})
+	require.ErrorContains(t, err, "unknown clipboard target")
+
+	_, err = New(Options{Tool: "xcopy"})
+	require.ErrorContains(t, err, "unknown clipboard tool")
+
+	_, err = New(Options{Tool: ToolCommand})
+	require.ErrorContains(t, err, "command template")
+}
+
+func TestNewRejectsTargetsTheToolCannotWrite(t *testing.T) {
+	t.Parallel()
+
+	for _, target := range []string{TargetPrimary, TargetBoth} {
+		_, err := New(Options{Tool: ToolPbcopy, Target: target})
+		require.ErrorContains(t, err, `clipboard tool "pbcopy" cannot write the primary selection`)
+	}
+
+	_, err := New(Options{Tool: ToolPbcopy, Target: TargetClipboard})
+	require.NoError(t, err)
+	_, err = New(Options{Tool: ToolOSC52, Target: TargetBoth})
+	require.NoError(t, err)
+}
+
+func TestResolveSkipsToolsThatCannotWriteTheTargets(t *testing.T) {
+	t.Parallel()
+
+	copier, _ := newTestCopier(t, Options{Target: TargetBoth}, ToolPbcopy)
+	copier.goos = "darwin"
+	got, err := copier.Tool()
+	require.NoError(t, err)
+	require.Equal(t, ToolOSC52, got)
+
+	copier, _ = newTestCopier(t, Options{}, ToolPbcopy)
+	copier.goos = "darwin"
+	got, err = copier.Tool()
+	require.NoError(t, err)
+	require.Equal(t, ToolPbcopy, got)
+}
+
+func TestReadCommandForTargets(t *testing.T) {
+	t.Parallel()
+
+	copier, _ := newTestCopier(t, Options{})
+
+	tests := []struct {
+		tool   string
+		target string
+		want   commandSpec
+	}{
+		{tool: ToolWlCopy, target: TargetPrimary, want: commandSpec{name: "wl-paste", args: []string{"--no-newline", "--primary"}}},
+		{tool: ToolXclip, target: TargetClipboard, want: commandSpec{name: "xclip", args: []string{"-selection", "clipboard", "-out"}}},
+		{tool: ToolXsel, target: TargetPrimary, want: commandSpec{name: "xsel", args: []string{"--primary", "--output"}}},
+		{tool: ToolPbcopy, target: TargetClipboard, want: commandSpec{name: "pbpaste"}},
+	}
+	for _, tt := range tests {
+		got, err := copier.readCommandFor(tt.tool, tt.target)
+		require.NoError(t, err, tt.tool)
+		require.Equal(t, tt.want, got, tt.tool)
+	}
+
+	_, err := copier.readCommandFor(ToolOSC52, TargetClipboard)
+	require.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestHasOnlyTextTypes(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, hasOnlyTextTypes([]string{"text/plain;charset=utf-8", "UTF8_STRING"}))
+	require.True(t, hasOnlyTextTypes([]string{"TARGETS", "STRING"}))
+	require.False(t, hasOnlyTextTypes([]string{"text/html", "image/png"}), "images must not be reduced to their text")
+	require.False(t, hasOnlyTextTypes([]string{"application/x-color"}))
+	require.False(t, hasOnlyTextTypes(nil))
+}
+
+func TestReadSkipsImages(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell script stubs")
+	}
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncase \"$1\" in --list-types) cat " + dir + "/types ;; *) cat " + dir + "/clipboard ;; esac\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "wl-paste"), []byte(script), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "wl-copy"), []byte("#!/bin/sh\nexit 0\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "clipboard"), []byte("previous text"), 0o644))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	copier, err := New(Options{Tool: ToolWlCopy})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "types"), []byte("text/plain\nUTF8_STRING\n"), 0o644))
+	text, err := copier.Read(context.Background(), TargetClipboard)
+	require.NoError(t, err)
+	require.Equal(t, "previous text", text)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "types"), []byte("image/png\n"), 0o644))
+	_, err = copier.Read(context.Background(), TargetClipboard)
+	require.ErrorIs(t, err, ErrNotText)
+}
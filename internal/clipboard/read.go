@@ -0,0 +1,124 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrNotText reports a selection that is empty or holds content, such as an
+// image, that cannot be read back as text.
+var ErrNotText = errors.New("clipboard is empty or does not hold text")
+
+// Read returns the text in target, read with the counterpart of the tool Copy
+// would use. Selections that also offer images are reported as ErrNotText,
+// since putting back only their text would lose the image.
+func (c *Copier) Read(ctx context.Context, target string) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tool, err := c.resolve()
+	if err != nil {
+		return "", err
+	}
+
+	if types, ok := c.typesCommandFor(tool, target); ok {
+		out, err := runReadCommand(ctx, types)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrNotText, err)
+		}
+		if !hasOnlyTextTypes(strings.Fields(out)) {
+			return "", ErrNotText
+		}
+	}
+
+	spec, err := c.readCommandFor(tool, target)
+	if err != nil {
+		return "", err
+	}
+	text, err := runReadCommand(ctx, spec)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNotText, err)
+	}
+	if text == "" {
+		return "", ErrNotText
+	}
+	return text, nil
+}
+
+func (c *Copier) readCommandFor(tool, target string) (commandSpec, error) {
+	switch tool {
+	case ToolWlCopy:
+		if target == TargetPrimary {
+			return commandSpec{name: "wl-paste", args: []string{"--no-newline", "--primary"}}, nil
+		}
+		return commandSpec{name: "wl-paste", args: []string{"--no-newline"}}, nil
+	case ToolXclip:
+		return commandSpec{name: ToolXclip, args: []string{"-selection", target, "-out"}}, nil
+	case ToolXsel:
+		return commandSpec{name: ToolXsel, args: []string{"--" + target, "--output"}}, nil
+	case ToolPbcopy:
+		if target == TargetPrimary {
+			return commandSpec{}, errors.New("the primary selection is not available on macOS")
+		}
+		return commandSpec{name: "pbpaste"}, nil
+	default:
+		return commandSpec{}, fmt.Errorf("%w: reading the clipboard is not supported with %s", ErrUnavailable, tool)
+	}
+}
+
+// typesCommandFor returns the command listing the content types a selection
+// offers, for tools that can list them.
+func (c *Copier) typesCommandFor(tool, target string) (commandSpec, bool) {
+	switch tool {
+	case ToolWlCopy:
+		if target == TargetPrimary {
+			return commandSpec{name: "wl-paste", args: []string{"--list-types", "--primary"}}, true
+		}
+		return commandSpec{name: "wl-paste", args: []string{"--list-types"}}, true
+	case ToolXclip:
+		return commandSpec{name: ToolXclip, args: []string{"-selection", target, "-target", "TARGETS", "-out"}}, true
+	default:
+		return commandSpec{}, false
+	}
+}
+
+// hasOnlyTextTypes reports whether types, MIME types from Wayland or X11
+// selection targets, include text and no image.
+func hasOnlyTextTypes(types []string) bool {
+	text := false
+	for _, t := range types {
+		switch {
+		case strings.HasPrefix(t, "image/"):
+			return false
+		case strings.HasPrefix(t, "text/plain"), t == "UTF8_STRING", t == "STRING", t == "TEXT":
+			text = true
+		}
+	}
+	return text
+}
+
+func runReadCommand(ctx context.Context, spec commandSpec) (string, error) {
+	readCtx, cancel := context.WithTimeout(ctx, 4*time.Second)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(readCtx, spec.name, spec.args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(readCtx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("read clipboard timed out: %w", readCtx.Err())
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", spec.name, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", spec.name, err)
+	}
+	return stdout.String(), nil
+}
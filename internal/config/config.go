@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// File is the on-disk voxclip configuration.
+//
+// Flags holds default values for command-line flags keyed by flag name, e.g.
+// {"model": "small", "threads": 4}. A named profile's flags are layered on
+// top. Flags passed on the command line always win over both.
+//
+// Remote holds settings that should not be passed as flags, such as
+// credentials.
+//
+// Hooks holds shell commands run around recording and transcription.
+//
+// Recordings holds the retention policy for the recordings directory.
+//
+// Backends defines additional recording backends that run a command.
+type File struct {
+	Flags      map[string]any     `json:"flags,omitempty"`
+	Profiles   map[string]Profile `json:"profiles,omitempty"`
+	Remote     Remote             `json:"remote,omitempty"`
+	Hooks      Hooks              `json:"hooks,omitempty"`
+	Recordings Recordings         `json:"recordings,omitempty"`
+	Backends   []Backend          `json:"backends,omitempty"`
+}
+
+// Remote configures the remote transcription engine.
+type Remote struct {
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// Hooks configures the lifecycle hook commands. Each command runs through sh;
+// an empty command disables its hook. Timeout is a Go duration such as "5s".
+type Hooks struct {
+	Timeout          string `json:"timeout,omitempty"`
+	OnRecordStart    string `json:"on_record_start,omitempty"`
+	OnRecordStop     string `json:"on_record_stop,omitempty"`
+	OnTranscribeDone string `json:"on_transcribe_done,omitempty"`
+	OnError          string `json:"on_error,omitempty"`
+}
+
+// Recordings limits what the recordings directory keeps. MaxAge is a Go
+// duration such as "168h", MaxSize a byte size such as "500MB". Unset limits
+// keep their defaults; "0" or 0 disables a limit.
+type Recordings struct {
+	MaxAge   string `json:"max_age,omitempty"`
+	MaxSize  string `json:"max_size,omitempty"`
+	MaxCount int    `json:"max_count,omitempty"`
+}
+
+// Backend defines a recording backend that runs a command. Record is the
+// command's argv; its arguments may contain the placeholders {output}, {rate},
+// {channels}, {input} and {duration}. Check is the binary that must be on PATH
+// for the backend to be available and defaults to the first element of
+// Record. StopSignal, such as "SIGTERM", stops the command and defaults to
+// SIGINT. Devices is an optional argv that lists input devices.
+type Backend struct {
+	Name       string   `json:"name"`
+	Check      string   `json:"check,omitempty"`
+	Record     []string `json:"record"`
+	StopSignal string   `json:"stop_signal,omitempty"`
+	Devices    []string `json:"devices,omitempty"`
+}
+
+type Profile struct {
+	Flags map[string]any `json:"flags,omitempty"`
+}
+
+// Load reads and parses the config file at path. A missing file is reported
+// with an error wrapping os.ErrNotExist so callers can treat it as optional.
+func Load(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("read config: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+
+	var file File
+	if err := dec.Decode(&file); err != nil {
+		return File{}, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// FlagValues merges the top-level flags with the given profile's flags and
+// renders every value in the string form pflag expects. List values become
+// one entry per element so repeated and slice flags receive each item.
+func (f File) FlagValues(profile string) (map[string][]string, error) {
+	merged := make(map[string]any, len(f.Flags))
+	for name, value := range f.Flags {
+		merged[name] = value
+	}
+
+	if profile != "" {
+		selected, ok := f.Profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q (known profiles: %v)", profile, f.ProfileNames())
+		}
+		for name, value := range selected.Flags {
+			merged[name] = value
+		}
+	}
+
+	values := make(map[string][]string, len(merged))
+	for name, value := range merged {
+		rendered, err := renderValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("flag %q: %w", name, err)
+		}
+		if rendered == nil {
+			continue
+		}
+		values[name] = rendered
+	}
+	return values, nil
+}
+
+func (f File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func renderValue(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			scalar, err := renderScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, scalar)
+		}
+		return out, nil
+	default:
+		scalar, err := renderScalar(v)
+		if err != nil {
+			return nil, err
+		}
+		return []string{scalar}, nil
+	}
+}
+
+func renderScalar(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return v.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v; use a string, number, boolean or a list of those", value)
+	}
+}
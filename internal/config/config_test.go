@@ -0,0 +1,139 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadMissingFileWrapsNotExist(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	require.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestLoadRejectsUnknownSections(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load(writeConfig(t, `{"flag": {"model": "small"}}`))
+	require.Error(t, err)
+}
+
+func TestLoadReadsRemoteSection(t *testing.T) {
+	t.Parallel()
+
+	file, err := Load(writeConfig(t, `{"flags": {"engine": "remote"}, "remote": {"api_key": "sk-test"}}`))
+	require.NoError(t, err)
+	require.Equal(t, "sk-test", file.Remote.APIKey)
+}
+
+func TestLoadReadsHooksSection(t *testing.T) {
+	t.Parallel()
+
+	file, err := Load(writeConfig(t, `{"hooks": {"timeout": "5s", "on_record_start": "playerctl pause", "on_error": "notify-send failed"}}`))
+	require.NoError(t, err)
+	require.Equal(t, Hooks{Timeout: "5s", OnRecordStart: "playerctl pause", OnError: "notify-send failed"}, file.Hooks)
+
+	_, err = Load(writeConfig(t, `{"hooks": {"on_start": "true"}}`))
+	require.Error(t, err, "unknown hook names are rejected")
+}
+
+func TestLoadReadsRecordingsSection(t *testing.T) {
+	t.Parallel()
+
+	file, err := Load(writeConfig(t, `{"recordings": {"max_age": "168h", "max_size": "500MB", "max_count": 20}}`))
+	require.NoError(t, err)
+	require.Equal(t, Recordings{MaxAge: "168h", MaxSize: "500MB", MaxCount: 20}, file.Recordings)
+}
+
+func TestLoadReadsBackendsSection(t *testing.T) {
+	t.Parallel()
+
+	file, err := Load(writeConfig(t, `{"backends": [{
+		"name": "jack",
+		"check": "jack_capture",
+		"record": ["jack_capture", "--channels", "{channels}", "{output}"],
+		"stop_signal": "SIGTERM",
+		"devices": ["jack_lsp", "-p"]
+	}]}`))
+	require.NoError(t, err)
+	require.Equal(t, []Backend{{
+		Name:       "jack",
+		Check:      "jack_capture",
+		Record:     []string{"jack_capture", "--channels", "{channels}", "{output}"},
+		StopSignal: "SIGTERM",
+		Devices:    []string{"jack_lsp", "-p"},
+	}}, file.Backends)
+}
+
+func TestFlagValuesRendersScalarsAndLists(t *testing.T) {
+	t.Parallel()
+
+	file, err := Load(writeConfig(t, `{
+		"flags": {
+			"model": "small",
+			"threads": 4,
+			"temperature": 0.2,
+			"split-on-word": true,
+			"whisper-arg": ["--prompt", "hello, world"]
+		}
+	}`))
+	require.NoError(t, err)
+
+	values, err := file.FlagValues("")
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"model":         {"small"},
+		"threads":       {"4"},
+		"temperature":   {"0.2"},
+		"split-on-word": {"true"},
+		"whisper-arg":   {"--prompt", "hello, world"},
+	}, values)
+}
+
+func TestFlagValuesProfileOverridesTopLevel(t *testing.T) {
+	t.Parallel()
+
+	file, err := Load(writeConfig(t, `{
+		"flags": {"model": "tiny", "threads": 2},
+		"profiles": {"meeting": {"flags": {"model": "medium"}}}
+	}`))
+	require.NoError(t, err)
+
+	values, err := file.FlagValues("meeting")
+	require.NoError(t, err)
+	require.Equal(t, []string{"medium"}, values["model"])
+	require.Equal(t, []string{"2"}, values["threads"])
+}
+
+func TestFlagValuesUnknownProfile(t *testing.T) {
+	t.Parallel()
+
+	file, err := Load(writeConfig(t, `{"profiles": {"meeting": {}}}`))
+	require.NoError(t, err)
+
+	_, err = file.FlagValues("dictation")
+	require.ErrorContains(t, err, `unknown profile "dictation"`)
+	require.ErrorContains(t, err, "meeting")
+}
+
+func TestFlagValuesRejectsNestedObjects(t *testing.T) {
+	t.Parallel()
+
+	file, err := Load(writeConfig(t, `{"flags": {"model": {"name": "tiny"}}}`))
+	require.NoError(t, err)
+
+	_, err = file.FlagValues("")
+	require.ErrorContains(t, err, `flag "model"`)
+}
@@ -0,0 +1,134 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/config"
+	"go.uber.org/zap"
+)
+
+const (
+	RecordStart    = "on_record_start"
+	RecordStop     = "on_record_stop"
+	TranscribeDone = "on_transcribe_done"
+	Error          = "on_error"
+
+	DefaultTimeout = 10 * time.Second
+)
+
+// Vars describes the event a hook runs for. Empty values are left out of the
+// hook's environment.
+type Vars struct {
+	AudioPath          string
+	AudioDuration      time.Duration
+	TranscriptFile     string
+	TranscribeDuration time.Duration
+	Model              string
+	Engine             string
+	Error              string
+}
+
+// environ renders v as VOXCLIP_* environment variables. Durations are in
+// seconds with millisecond precision.
+func (v Vars) environ(event string) []string {
+	env := []string{"VOXCLIP_HOOK=" + event}
+	add := func(name, value string) {
+		if value != "" {
+			env = append(env, name+"="+value)
+		}
+	}
+	seconds := func(d time.Duration) string {
+		if d <= 0 {
+			return ""
+		}
+		return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+	}
+
+	add("VOXCLIP_AUDIO_PATH", v.AudioPath)
+	add("VOXCLIP_AUDIO_DURATION", seconds(v.AudioDuration))
+	add("VOXCLIP_TRANSCRIPT_FILE", v.TranscriptFile)
+	add("VOXCLIP_TRANSCRIBE_DURATION", seconds(v.TranscribeDuration))
+	add("VOXCLIP_MODEL", v.Model)
+	add("VOXCLIP_ENGINE", v.Engine)
+	add("VOXCLIP_ERROR", v.Error)
+	return env
+}
+
+// Runner runs the configured hook commands. A nil Runner runs nothing.
+type Runner struct {
+	commands map[string]string
+	timeout  time.Duration
+	logger   *zap.Logger
+	// output receives what hooks print, so stdout stays reserved for the
+	// transcript.
+	output io.Writer
+}
+
+// New returns a Runner for the hooks section of the config file.
+func New(cfg config.Hooks, logger *zap.Logger) (*Runner, error) {
+	timeout := DefaultTimeout
+	if cfg.Timeout != "" {
+		parsed, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("hooks timeout: %w", err)
+		}
+		if parsed <= 0 {
+			return nil, fmt.Errorf("hooks timeout must be positive, got %s", cfg.Timeout)
+		}
+		timeout = parsed
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &Runner{
+		commands: map[string]string{
+			RecordStart:    cfg.OnRecordStart,
+			RecordStop:     cfg.OnRecordStop,
+			TranscribeDone: cfg.OnTranscribeDone,
+			Error:          cfg.OnError,
+		},
+		timeout: timeout,
+		logger:  logger,
+		output:  os.Stderr,
+	}, nil
+}
+
+// Run runs the hook for event, if one is configured, and waits for it to
+// finish or time out. Failures are logged and never returned: a hook must not
+// abort recording or transcription. Hooks still run after ctx is canceled, so
+// on_error sees an interrupted run.
+func (r *Runner) Run(ctx context.Context, event string, vars Vars) {
+	if r == nil || r.commands[event] == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.timeout)
+	defer cancel()
+
+	started := time.Now()
+	cmd := exec.CommandContext(ctx, "sh", "-c", r.commands[event])
+	cmd.Env = append(os.Environ(), vars.environ(event)...)
+	cmd.Stdout = r.output
+	cmd.Stderr = r.output
+	// Background processes started by the hook may keep its output open;
+	// do not wait for them after the hook itself has exited.
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		r.logger.Warn("hook timed out", zap.String("hook", event), zap.Duration("timeout", r.timeout))
+	case err != nil:
+		r.logger.Warn("hook failed", zap.String("hook", event), zap.Error(err))
+	default:
+		r.logger.Debug("hook finished", zap.String("hook", event), zap.Duration("elapsed", time.Since(started)))
+	}
+}
@@ -0,0 +1,100 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newTestRunner(t *testing.T, cfg config.Hooks) (*Runner, *observer.ObservedLogs, *bytes.Buffer) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks run through sh")
+	}
+
+	core, logs := observer.New(zap.DebugLevel)
+	runner, err := New(cfg, zap.New(core))
+	require.NoError(t, err)
+	output := new(bytes.Buffer)
+	runner.output = output
+	return runner, logs, output
+}
+
+func TestRunPassesEventEnvironment(t *testing.T) {
+	t.Parallel()
+
+	runner, _, output := newTestRunner(t, config.Hooks{
+		OnTranscribeDone: `echo "$VOXCLIP_HOOK $VOXCLIP_MODEL $VOXCLIP_AUDIO_DURATION $VOXCLIP_TRANSCRIBE_DURATION"; cat "$VOXCLIP_TRANSCRIPT_FILE"; echo "error=${VOXCLIP_ERROR-unset}"`,
+	})
+
+	transcript := filepath.Join(t.TempDir(), "transcript.txt")
+	require.NoError(t, os.WriteFile(transcript, []byte("hello world\n"), 0o644))
+
+	runner.Run(context.Background(), TranscribeDone, Vars{
+		AudioDuration:      1500 * time.Millisecond,
+		TranscriptFile:     transcript,
+		TranscribeDuration: 250 * time.Millisecond,
+		Model:              "small",
+	})
+	require.Equal(t, "on_transcribe_done small 1.500 0.250\nhello world\nerror=unset\n", output.String())
+}
+
+func TestRunLogsFailuresAndTimeouts(t *testing.T) {
+	t.Parallel()
+
+	runner, logs, _ := newTestRunner(t, config.Hooks{
+		Timeout:       "100ms",
+		OnRecordStart: "exit 4",
+		OnRecordStop:  "sleep 5",
+	})
+
+	runner.Run(context.Background(), RecordStart, Vars{})
+	require.Equal(t, 1, logs.FilterMessage("hook failed").Len())
+
+	started := time.Now()
+	runner.Run(context.Background(), RecordStop, Vars{})
+	require.Less(t, time.Since(started), 3*time.Second)
+	require.Equal(t, 1, logs.FilterMessage("hook timed out").Len())
+}
+
+func TestRunSkipsUnconfiguredHooksAndNilRunner(t *testing.T) {
+	t.Parallel()
+
+	runner, logs, output := newTestRunner(t, config.Hooks{})
+	runner.Run(context.Background(), Error, Vars{Error: "boom"})
+	require.Zero(t, logs.Len())
+	require.Empty(t, output.String())
+
+	var nilRunner *Runner
+	nilRunner.Run(context.Background(), Error, Vars{})
+}
+
+func TestRunStillRunsAfterCancel(t *testing.T) {
+	t.Parallel()
+
+	runner, _, output := newTestRunner(t, config.Hooks{OnError: `echo "$VOXCLIP_ERROR"`})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	runner.Run(ctx, Error, Vars{Error: "recording interrupted"})
+	require.Equal(t, "recording interrupted\n", output.String())
+}
+
+func TestNewValidatesTimeout(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(config.Hooks{Timeout: "soon"}, nil)
+	require.ErrorContains(t, err, "hooks timeout")
+
+	_, err = New(config.Hooks{Timeout: "-1s"}, nil)
+	require.ErrorContains(t, err, "must be positive")
+}
@@ -0,0 +1,282 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/applescript"
+	"go.uber.org/zap"
+)
+
+var ErrUnavailable = errors.New("no notification command available")
+
+const (
+	UrgencyLow      = "low"
+	UrgencyNormal   = "normal"
+	UrgencyCritical = "critical"
+
+	ToolAuto       = "auto"
+	ToolNotifySend = "notify-send"
+	ToolGdbus      = "gdbus"
+	ToolOsascript  = "osascript"
+
+	appName = "voxclip"
+)
+
+// Tools returns the valid values for Options.Tool.
+func Tools() []string {
+	return []string{ToolAuto, ToolNotifySend, ToolGdbus, ToolOsascript}
+}
+
+// gdbusID matches the notification ID in gdbus output such as "(uint32 7,)".
+var gdbusID = regexp.MustCompile(`uint32 (\d+)`)
+
+// Message is one desktop notification.
+type Message struct {
+	Title string
+	Body  string
+	// Urgency is low, normal or critical. Defaults to normal.
+	Urgency string
+}
+
+// Options selects how notifications are sent.
+type Options struct {
+	// Tool forces a notification command; auto picks the first available
+	// one.
+	Tool string
+	// IDFile keeps the ID of the last notification, so the next voxclip run
+	// replaces it instead of stacking another one. Optional.
+	IDFile string
+	Logger *zap.Logger
+}
+
+type command struct {
+	name string
+	args []string
+}
+
+// Notifier sends desktop notifications. Each notification replaces the
+// previous one where the notification server supports it.
+type Notifier struct {
+	tool   string
+	idFile string
+	logger *zap.Logger
+
+	mu sync.Mutex
+	// id is the notification to replace; 0 means none.
+	id uint64
+	// noReplace is set once notify-send rejected --print-id, which needs
+	// libnotify 0.7.9 or later.
+	noReplace bool
+
+	lookPath func(string) (string, error)
+	goos     string
+	run      func(ctx context.Context, cmd command) (string, error)
+}
+
+// New validates opts and returns a Notifier.
+func New(opts Options) (*Notifier, error) {
+	tool := strings.TrimSpace(opts.Tool)
+	if tool == "" {
+		tool = ToolAuto
+	}
+	if !slices.Contains(Tools(), tool) {
+		return nil, fmt.Errorf("unknown notification tool %q (valid: %s)", tool, strings.Join(Tools(), ", "))
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	n := &Notifier{
+		tool:     tool,
+		idFile:   opts.IDFile,
+		logger:   logger,
+		lookPath: exec.LookPath,
+		goos:     runtime.GOOS,
+		run:      runCommand,
+	}
+	if opts.IDFile != "" {
+		if data, err := os.ReadFile(opts.IDFile); err == nil {
+			n.id, _ = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 32)
+		}
+	}
+	return n, nil
+}
+
+// Tool returns the name of the command Send would use, or ErrUnavailable.
+func (n *Notifier) Tool() (string, error) {
+	return n.resolve()
+}
+
+// Send shows msg, replacing the previous notification.
+func (n *Notifier) Send(ctx context.Context, msg Message) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if msg.Urgency == "" {
+		msg.Urgency = UrgencyNormal
+	}
+
+	tool, err := n.resolve()
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	cmd := n.commandFor(tool, msg)
+	out, err := n.run(ctx, cmd)
+	if err != nil && tool == ToolNotifySend && !n.noReplace && isUnknownOptionError(err) {
+		n.logger.Debug("notify-send cannot replace notifications; sending without replacement", zap.Error(err))
+		n.noReplace = true
+		out, err = n.run(ctx, n.commandFor(tool, msg))
+	}
+	if err != nil {
+		return err
+	}
+
+	id, ok := parseID(tool, out)
+	if !ok {
+		return nil
+	}
+	n.id = id
+	if n.idFile != "" {
+		if err := os.MkdirAll(filepath.Dir(n.idFile), 0o755); err == nil {
+			err = os.WriteFile(n.idFile, []byte(strconv.FormatUint(id, 10)+"\n"), 0o644)
+		}
+		if err != nil {
+			n.logger.Debug("failed to save notification id", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) resolve() (string, error) {
+	if n.tool != ToolAuto {
+		if _, err := n.lookPath(n.tool); err != nil {
+			return "", fmt.Errorf("%w: %s not found in PATH", ErrUnavailable, n.tool)
+		}
+		return n.tool, nil
+	}
+
+	candidates := []string{ToolNotifySend, ToolGdbus}
+	if n.goos == "darwin" {
+		candidates = []string{ToolOsascript}
+	}
+	for _, candidate := range candidates {
+		if _, err := n.lookPath(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w: install one of %s", ErrUnavailable, strings.Join(candidates, ", "))
+}
+
+func (n *Notifier) commandFor(tool string, msg Message) command {
+	switch tool {
+	case ToolNotifySend:
+		args := []string{"--app-name=" + appName, "--urgency=" + msg.Urgency}
+		if !n.noReplace {
+			args = append(args, "--print-id")
+			if n.id > 0 {
+				args = append(args, "--replace-id="+strconv.FormatUint(n.id, 10))
+			}
+		}
+		return command{name: ToolNotifySend, args: append(args, "--", msg.Title, msg.Body)}
+	case ToolGdbus:
+		// Calls org.freedesktop.Notifications.Notify directly, for systems
+		// with a notification server but without libnotify's notify-send.
+		return command{name: ToolGdbus, args: []string{
+			"call", "--session",
+			"--dest=org.freedesktop.Notifications",
+			"--object-path=/org/freedesktop/Notifications",
+			"--method=org.freedesktop.Notifications.Notify",
+			gvariantString(appName),
+			strconv.FormatUint(n.id, 10),
+			gvariantString(""),
+			gvariantString(msg.Title),
+			gvariantString(msg.Body),
+			"[]",
+			fmt.Sprintf("{'urgency': <byte %d>}", urgencyLevel(msg.Urgency)),
+			"-1",
+		}}
+	default:
+		// macOS has no urgency levels and cannot replace notifications.
+		script := "display notification " + applescript.Quote(msg.Body) +
+			" with title " + applescript.Quote(appName) +
+			" subtitle " + applescript.Quote(msg.Title)
+		return command{name: ToolOsascript, args: []string{"-e", script}}
+	}
+}
+
+func parseID(tool, out string) (uint64, bool) {
+	var raw string
+	switch tool {
+	case ToolNotifySend:
+		raw = strings.TrimSpace(out)
+	case ToolGdbus:
+		match := gdbusID.FindStringSubmatch(out)
+		if match == nil {
+			return 0, false
+		}
+		raw = match[1]
+	default:
+		return 0, false
+	}
+	id, err := strconv.ParseUint(raw, 10, 32)
+	return id, err == nil && id > 0
+}
+
+// isUnknownOptionError reports whether notify-send failed on an option it
+// does not know, as older versions do with "Unknown option --print-id".
+func isUnknownOptionError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "unknown option")
+}
+
+// urgencyLevel maps an urgency to the byte the notification spec uses.
+func urgencyLevel(urgency string) int {
+	switch urgency {
+	case UrgencyLow:
+		return 0
+	case UrgencyCritical:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func gvariantString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
+
+func runCommand(ctx context.Context, spec command) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 4*time.Second)
+	defer cancel()
+
+	var stdout, stderr strings.Builder
+	cmd := exec.CommandContext(ctx, spec.name, spec.args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", spec.name, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", spec.name, err)
+	}
+	return stdout.String(), nil
+}
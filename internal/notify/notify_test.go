@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestNotifier returns a Notifier that records its commands and answers
+// each with the next of outputs.
+func newTestNotifier(t *testing.T, opts Options, goos string, outputs ...string) (*Notifier, *[]command) {
+	t.Helper()
+
+	n, err := New(opts)
+	require.NoError(t, err)
+
+	var commands []command
+	n.goos = goos
+	n.lookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
+	n.run = func(_ context.Context, cmd command) (string, error) {
+		commands = append(commands, cmd)
+		if len(outputs) == 0 {
+			return "", nil
+		}
+		out := outputs[0]
+		outputs = outputs[1:]
+		return out, nil
+	}
+	return n, &commands
+}
+
+func TestNotifySendReplacesPreviousNotification(t *testing.T) {
+	t.Parallel()
+
+	idFile := filepath.Join(t.TempDir(), "state", "notification-id")
+	n, commands := newTestNotifier(t, Options{IDFile: idFile}, "linux", "17\n", "17\n")
+
+	require.NoError(t, n.Send(context.Background(), Message{Title: "Recording", Urgency: UrgencyLow}))
+	require.NoError(t, n.Send(context.Background(), Message{Title: "Transcript", Body: "hello world"}))
+
+	require.Equal(t, []command{
+		{name: "notify-send", args: []string{"--app-name=voxclip", "--urgency=low", "--print-id", "--", "Recording", ""}},
+		{name: "notify-send", args: []string{"--app-name=voxclip", "--urgency=normal", "--print-id", "--replace-id=17", "--", "Transcript", "hello world"}},
+	}, *commands)
+
+	// The next run replaces the last notification of this one.
+	next, commands := newTestNotifier(t, Options{IDFile: idFile}, "linux")
+	require.NoError(t, next.Send(context.Background(), Message{Title: "Error", Urgency: UrgencyCritical}))
+	require.Contains(t, (*commands)[0].args, "--replace-id=17")
+}
+
+func TestNotifySendWithoutPrintIDSupportSendsWithoutReplacement(t *testing.T) {
+	t.Parallel()
+
+	idFile := filepath.Join(t.TempDir(), "notification-id")
+	require.NoError(t, os.WriteFile(idFile, []byte("17\n"), 0o644))
+	n, err := New(Options{IDFile: idFile})
+	require.NoError(t, err)
+
+	var commands []command
+	n.goos = "linux"
+	n.lookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
+	n.run = func(_ context.Context, cmd command) (string, error) {
+		commands = append(commands, cmd)
+		for _, arg := range cmd.args {
+			if arg == "--print-id" || strings.HasPrefix(arg, "--replace-id") {
+				return "", errors.New("notify-send: exit status 1: Unknown option " + arg)
+			}
+		}
+		return "", nil
+	}
+
+	require.NoError(t, n.Send(context.Background(), Message{Title: "Recording"}))
+	require.NoError(t, n.Send(context.Background(), Message{Title: "Transcript", Body: "hello world"}))
+
+	require.Equal(t, []command{
+		{name: "notify-send", args: []string{"--app-name=voxclip", "--urgency=normal", "--print-id", "--replace-id=17", "--", "Recording", ""}},
+		{name: "notify-send", args: []string{"--app-name=voxclip", "--urgency=normal", "--", "Recording", ""}},
+		{name: "notify-send", args: []string{"--app-name=voxclip", "--urgency=normal", "--", "Transcript", "hello world"}},
+	}, commands)
+}
+
+func TestNotifySendReportsOtherErrors(t *testing.T) {
+	t.Parallel()
+
+	n, err := New(Options{})
+	require.NoError(t, err)
+	calls := 0
+	n.lookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
+	n.goos = "linux"
+	n.run = func(context.Context, command) (string, error) {
+		calls++
+		return "", errors.New("notify-send: exit status 1: Cannot connect to the notification server")
+	}
+
+	require.ErrorContains(t, n.Send(context.Background(), Message{Title: "Recording"}), "notification server")
+	require.Equal(t, 1, calls)
+}
+
+func TestGdbusCallsNotificationService(t *testing.T) {
+	t.Parallel()
+
+	n, commands := newTestNotifier(t, Options{Tool: ToolGdbus}, "linux", "(uint32 42,)\n")
+	require.NoError(t, n.Send(context.Background(), Message{Title: "Didn't copy", Body: `C:\path`, Urgency: UrgencyCritical}))
+	require.NoError(t, n.Send(context.Background(), Message{Title: "Again"}))
+
+	require.Equal(t, []string{
+		"call", "--session",
+		"--dest=org.freedesktop.Notifications",
+		"--object-path=/org/freedesktop/Notifications",
+		"--method=org.freedesktop.Notifications.Notify",
+		"'voxclip'", "0", "''", `'Didn\'t copy'`, `'C:\\path'`, "[]", "{'urgency': <byte 2>}", "-1",
+	}, (*commands)[0].args)
+	require.Equal(t, "42", (*commands)[1].args[6], "the second call replaces the first notification")
+}
+
+func TestOsascriptNotification(t *testing.T) {
+	t.Parallel()
+
+	n, commands := newTestNotifier(t, Options{}, "darwin")
+	require.NoError(t, n.Send(context.Background(), Message{Title: "Transcript", Body: `say "hi"`}))
+	require.Equal(t, []command{{name: "osascript", args: []string{
+		"-e", `display notification "say \"hi\"" with title "voxclip" subtitle "Transcript"`,
+	}}}, *commands)
+}
+
+func TestResolveReportsMissingTools(t *testing.T) {
+	t.Parallel()
+
+	n, _ := newTestNotifier(t, Options{}, "linux")
+	n.lookPath = func(name string) (string, error) {
+		if name == ToolGdbus {
+			return "/usr/bin/gdbus", nil
+		}
+		return "", errors.New("not found")
+	}
+	tool, err := n.Tool()
+	require.NoError(t, err)
+	require.Equal(t, ToolGdbus, tool)
+
+	n.lookPath = func(string) (string, error) { return "", os.ErrNotExist }
+	_, err = n.Tool()
+	require.ErrorIs(t, err, ErrUnavailable)
+
+	_, err = New(Options{Tool: "growl"})
+	require.ErrorContains(t, err, "unknown notification tool")
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package platform
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// DetachProcess starts cmd in its own session so it keeps running after
+// voxclip exits and does not receive the terminal's Ctrl-C.
+func DetachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,5 @@
+package platform
+
+import "os/exec"
+
+func DetachProcess(cmd *exec.Cmd) {}
@@ -47,6 +47,46 @@ func DefaultRecordingDirFor(goos, homeDir, xdgDataHome string) (string, error) {
 	return filepath.Join(dataDir, "recordings"), nil
 }
 
+// DefaultConfigPathFor returns the location of the voxclip config file.
+// Linux follows the XDG base directory spec; macOS keeps it next to the data.
+func DefaultConfigPathFor(goos, homeDir, xdgConfigHome string) (string, error) {
+	if homeDir == "" {
+		return "", errors.New("home directory is empty")
+	}
+
+	switch goos {
+	case "linux":
+		if xdgConfigHome != "" {
+			return filepath.Join(xdgConfigHome, "voxclip", "config.json"), nil
+		}
+		return filepath.Join(homeDir, ".config", "voxclip", "config.json"), nil
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "Application Support", "voxclip", "config.json"), nil
+	default:
+		return "", fmt.Errorf("unsupported OS: %s", goos)
+	}
+}
+
+// DefaultStateDirFor returns where voxclip keeps runtime state such as
+// background process bookkeeping. Linux follows XDG_STATE_HOME.
+func DefaultStateDirFor(goos, homeDir, xdgStateHome string) (string, error) {
+	if homeDir == "" {
+		return "", errors.New("home directory is empty")
+	}
+
+	switch goos {
+	case "linux":
+		if xdgStateHome != "" {
+			return filepath.Join(xdgStateHome, "voxclip"), nil
+		}
+		return filepath.Join(homeDir, ".local", "state", "voxclip"), nil
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "Application Support", "voxclip", "state"), nil
+	default:
+		return "", fmt.Errorf("unsupported OS: %s", goos)
+	}
+}
+
 func ResolveModelDir(override string) (string, error) {
 	if override != "" {
 		return filepath.Clean(override), nil
@@ -69,6 +109,24 @@ func ResolveRecordingDir() (string, error) {
 	return DefaultRecordingDirFor(runtime.GOOS, homeDir, os.Getenv("XDG_DATA_HOME"))
 }
 
+func ResolveConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve user home: %w", err)
+	}
+
+	return DefaultConfigPathFor(runtime.GOOS, homeDir, os.Getenv("XDG_CONFIG_HOME"))
+}
+
+func ResolveStateDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve user home: %w", err)
+	}
+
+	return DefaultStateDirFor(runtime.GOOS, homeDir, os.Getenv("XDG_STATE_HOME"))
+}
+
 func defaultDataDirFor(goos, homeDir, xdgDataHome string) (string, error) {
 	if homeDir == "" {
 		return "", errors.New("home directory is empty")
@@ -36,3 +36,47 @@ func TestDefaultModelDirForUnsupportedOS(t *testing.T) {
 	_, err := DefaultModelDirFor("windows", "/Users/dev", "")
 	require.Error(t, err)
 }
+
+func TestDefaultConfigPathForLinuxWithXDG(t *testing.T) {
+	t.Parallel()
+
+	path, err := DefaultConfigPathFor("linux", "/home/dev", "/tmp/xdg-config")
+	require.NoError(t, err)
+	require.Equal(t, "/tmp/xdg-config/voxclip/config.json", path)
+}
+
+func TestDefaultConfigPathForLinuxWithoutXDG(t *testing.T) {
+	t.Parallel()
+
+	path, err := DefaultConfigPathFor("linux", "/home/dev", "")
+	require.NoError(t, err)
+	require.Equal(t, "/home/dev/.config/voxclip/config.json", path)
+}
+
+func TestDefaultConfigPathForMacOS(t *testing.T) {
+	t.Parallel()
+
+	path, err := DefaultConfigPathFor("darwin", "/Users/dev", "/tmp/ignored")
+	require.NoError(t, err)
+	require.Equal(t, "/Users/dev/Library/Application Support/voxclip/config.json", path)
+}
+
+func TestDefaultStateDirForLinux(t *testing.T) {
+	t.Parallel()
+
+	dir, err := DefaultStateDirFor("linux", "/home/dev", "")
+	require.NoError(t, err)
+	require.Equal(t, "/home/dev/.local/state/voxclip", dir)
+
+	dir, err = DefaultStateDirFor("linux", "/home/dev", "/tmp/xdg-state")
+	require.NoError(t, err)
+	require.Equal(t, "/tmp/xdg-state/voxclip", dir)
+}
+
+func TestDefaultStateDirForMacOS(t *testing.T) {
+	t.Parallel()
+
+	dir, err := DefaultStateDirFor("darwin", "/Users/dev", "")
+	require.NoError(t, err)
+	require.Equal(t, "/Users/dev/Library/Application Support/voxclip/state", dir)
+}
@@ -0,0 +1,44 @@
+//go:build !windows
+
+package platform
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ProcessRunning reports whether a process with the given PID exists.
+func ProcessRunning(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// ProcessArgs returns the command line of the process with the given PID.
+// On Linux it is read from /proc; elsewhere it comes from ps, split on
+// whitespace.
+func ProcessArgs(pid int) ([]string, error) {
+	if pid <= 0 {
+		return nil, fmt.Errorf("invalid pid %d", pid)
+	}
+	if raw, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/cmdline"); err == nil {
+		return strings.Split(string(bytes.TrimRight(raw, "\x00")), "\x00"), nil
+	}
+	out, err := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "args=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("read command line of process %d: %w", pid, err)
+	}
+	args := strings.Fields(string(out))
+	if len(args) == 0 {
+		return nil, fmt.Errorf("process %d not found", pid)
+	}
+	return args, nil
+}
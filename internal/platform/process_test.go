@@ -0,0 +1,29 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessRunning(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, ProcessRunning(os.Getpid()))
+	require.False(t, ProcessRunning(0))
+	require.False(t, ProcessRunning(-1))
+}
+
+func TestProcessArgs(t *testing.T) {
+	t.Parallel()
+
+	args, err := ProcessArgs(os.Getpid())
+	require.NoError(t, err)
+	require.NotEmpty(t, args)
+	require.Equal(t, filepath.Base(os.Args[0]), filepath.Base(args[0]))
+
+	_, err = ProcessArgs(0)
+	require.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package platform
+
+import (
+	"errors"
+	"os"
+)
+
+func ProcessRunning(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = process.Release()
+	return true
+}
+
+func ProcessArgs(int) ([]string, error) {
+	return nil, errors.New("reading process command lines is not supported on Windows")
+}
@@ -0,0 +1,194 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/fmueller/voxclip/internal/config"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{[a-z_]+\}`)
+
+// commandPlaceholders are the placeholders a record template may contain.
+var commandPlaceholders = map[string]bool{
+	"{output}":   true,
+	"{rate}":     true,
+	"{channels}": true,
+	"{input}":    true,
+	"{duration}": true,
+}
+
+// commandBackend records by running a command defined in the config file,
+// for setups the built-in backends do not cover, such as JACK or gstreamer
+// pipelines.
+type commandBackend struct {
+	name    string
+	check   string
+	args    []string
+	stop    syscall.Signal
+	devices []string
+}
+
+// CommandBackends builds the recording backends defined in the config file.
+// Names must be unique and must not shadow a built-in backend.
+func CommandBackends(defs []config.Backend) ([]Backend, error) {
+	reserved := map[string]bool{"auto": true}
+	for _, goos := range []string{"linux", "darwin"} {
+		for _, backend := range DefaultBackends(goos) {
+			reserved[backend.Name()] = true
+		}
+	}
+
+	backends := make([]Backend, 0, len(defs))
+	for i, def := range defs {
+		backend, err := newCommandBackend(def)
+		if err != nil {
+			if def.Name == "" {
+				return nil, fmt.Errorf("backends[%d]: %w", i, err)
+			}
+			return nil, fmt.Errorf("backend %q: %w", def.Name, err)
+		}
+		if reserved[backend.name] {
+			return nil, fmt.Errorf("backend %q: name is already taken by another backend", backend.name)
+		}
+		reserved[backend.name] = true
+		backends = append(backends, backend)
+	}
+	return backends, nil
+}
+
+func newCommandBackend(def config.Backend) (*commandBackend, error) {
+	name := strings.TrimSpace(def.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if strings.Contains(name, ":") {
+		return nil, errors.New("name must not contain ':'")
+	}
+	if len(def.Record) == 0 || strings.TrimSpace(def.Record[0]) == "" {
+		return nil, errors.New("record command is required")
+	}
+
+	hasOutput := false
+	for _, arg := range def.Record {
+		for _, placeholder := range placeholderPattern.FindAllString(arg, -1) {
+			if !commandPlaceholders[placeholder] {
+				return nil, fmt.Errorf("unknown placeholder %s in record command", placeholder)
+			}
+		}
+		hasOutput = hasOutput || strings.Contains(arg, "{output}")
+	}
+	if !hasOutput {
+		return nil, errors.New("record command must write to {output}")
+	}
+
+	stop := syscall.SIGINT
+	if def.StopSignal != "" {
+		signal, ok := stopSignals[strings.ToUpper(strings.TrimSpace(def.StopSignal))]
+		if !ok {
+			return nil, fmt.Errorf("unsupported stop_signal %q (supported: %s)", def.StopSignal, strings.Join(stopSignalNames, ", "))
+		}
+		stop = signal
+	}
+
+	check := strings.TrimSpace(def.Check)
+	if check == "" {
+		check = def.Record[0]
+	}
+
+	return &commandBackend{
+		name:    name,
+		check:   check,
+		args:    def.Record,
+		stop:    stop,
+		devices: def.Devices,
+	}, nil
+}
+
+func (b *commandBackend) Name() string {
+	return b.name
+}
+
+func (b *commandBackend) Available() bool {
+	return commandAvailable(b.check)
+}
+
+func (b *commandBackend) unavailableReason() string {
+	return fmt.Sprintf("%s not found on PATH", b.check)
+}
+
+func (b *commandBackend) Record(ctx context.Context, cfg Config) error {
+	if cfg.OutputPath == "" {
+		return fmt.Errorf("output path is required")
+	}
+
+	if err := os.MkdirAll(filepathDir(cfg.OutputPath), 0o755); err != nil {
+		return err
+	}
+
+	argv := expandCommand(b.args, cfg)
+
+	var cmd *exec.Cmd
+	if cfg.StopCh != nil || cfg.Duration > 0 {
+		cmd = exec.Command(argv[0], argv[1:]...)
+	} else {
+		cmd = exec.CommandContext(ctx, argv[0], argv[1:]...)
+	}
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if cfg.StopCh != nil {
+		return runSignalStopCommand(ctx, cmd, b.stop, cfg.StopCh, cfg.Duration, cfg.Logger)
+	}
+
+	if cfg.Interactive {
+		return runInteractiveCommand(ctx, cmd, b.stop, cfg.Logger, cfg.InteractiveMessage)
+	}
+
+	if cfg.Duration > 0 {
+		return runTimedCommand(ctx, cmd, b.stop, cfg.Duration, cfg.Logger)
+	}
+
+	return cmd.Run()
+}
+
+func (b *commandBackend) ListDevices(ctx context.Context) (string, error) {
+	if len(b.devices) == 0 {
+		return "no device-list command configured", nil
+	}
+	return commandOutput(ctx, b.devices[0], b.devices[1:]...)
+}
+
+// expandCommand fills the placeholders of a record template. An argument
+// that refers to {input} or {duration} is left out when no input device or
+// duration is set, so an option and its value belong in one argument, as in
+// --device={input}.
+func expandCommand(args []string, cfg Config) []string {
+	var duration string
+	if cfg.Duration > 0 {
+		duration = strconv.FormatFloat(cfg.Duration.Seconds(), 'f', -1, 64)
+	}
+	replacer := strings.NewReplacer(
+		"{output}", cfg.OutputPath,
+		"{rate}", strconv.Itoa(defaultSampleRate(cfg.SampleRate)),
+		"{channels}", strconv.Itoa(defaultChannels(cfg.Channels)),
+		"{input}", cfg.Input,
+		"{duration}", duration,
+	)
+
+	expanded := make([]string, 0, len(args))
+	for _, arg := range args {
+		if (cfg.Input == "" && strings.Contains(arg, "{input}")) || (duration == "" && strings.Contains(arg, "{duration}")) {
+			continue
+		}
+		expanded = append(expanded, replacer.Replace(arg))
+	}
+	return expanded
+}
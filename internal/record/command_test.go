@@ -0,0 +1,129 @@
+package record
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandBackendsValidatesDefinitions(t *testing.T) {
+	t.Parallel()
+
+	backends, err := CommandBackends([]config.Backend{
+		{Name: "jack", Record: []string{"jack_capture", "{output}"}},
+		{Name: "gst", Check: "gst-launch-1.0", Record: []string{"gst-launch-1.0", "-e", "location={output}"}, StopSignal: "sigint"},
+	})
+	require.NoError(t, err)
+	require.Len(t, backends, 2)
+	require.Equal(t, "jack", backends[0].Name())
+	require.Equal(t, "jack_capture not found on PATH", UnavailableReason(backends[0]))
+
+	for _, tc := range []struct {
+		def  config.Backend
+		want string
+	}{
+		{def: config.Backend{Record: []string{"rec", "{output}"}}, want: "backends[0]: name is required"},
+		{def: config.Backend{Name: "file:x", Record: []string{"rec", "{output}"}}, want: "must not contain ':'"},
+		{def: config.Backend{Name: "x"}, want: "record command is required"},
+		{def: config.Backend{Name: "x", Record: []string{"rec", "out.wav"}}, want: "must write to {output}"},
+		{def: config.Backend{Name: "x", Record: []string{"rec", "{output}", "{format}"}}, want: "unknown placeholder {format}"},
+		{def: config.Backend{Name: "x", Record: []string{"rec", "{output}"}, StopSignal: "SIGSTOP"}, want: "unsupported stop_signal"},
+		{def: config.Backend{Name: "sox", Record: []string{"rec", "{output}"}}, want: "name is already taken"},
+		{def: config.Backend{Name: "auto", Record: []string{"rec", "{output}"}}, want: "name is already taken"},
+	} {
+		_, err := CommandBackends([]config.Backend{tc.def})
+		require.ErrorContains(t, err, tc.want)
+	}
+
+	_, err = CommandBackends([]config.Backend{
+		{Name: "jack", Record: []string{"jack_capture", "{output}"}},
+		{Name: "jack", Record: []string{"jack_rec", "{output}"}},
+	})
+	require.ErrorContains(t, err, `backend "jack": name is already taken`)
+}
+
+func TestExpandCommand(t *testing.T) {
+	t.Parallel()
+
+	args := []string{"jack_capture", "--channels", "{channels}", "--rate={rate}", "--port={input}", "--duration={duration}", "{output}"}
+	require.Equal(t,
+		[]string{"jack_capture", "--channels", "1", "--rate=16000", "--port=system:capture_1", "--duration=1.5", "/tmp/out.wav"},
+		expandCommand(args, Config{OutputPath: "/tmp/out.wav", SampleRate: 16000, Channels: 1, Input: "system:capture_1", Duration: 1500 * time.Millisecond}),
+	)
+	require.Equal(t,
+		[]string{"jack_capture", "--channels", "1", "--rate=16000", "/tmp/out.wav"},
+		expandCommand(args, Config{OutputPath: "/tmp/out.wav"}),
+		"arguments with an unset input or duration are left out",
+	)
+}
+
+func TestCommandBackendStopsWithConfiguredSignal(t *testing.T) {
+	tempDir := t.TempDir()
+	signalFile := filepath.Join(tempDir, "signal.txt")
+	readyFile := filepath.Join(tempDir, "ready.txt")
+
+	// The stub ignores SIGINT, so only the configured SIGTERM stops it.
+	stub := "#!/bin/sh\nset -eu\ntrap '' INT\ntrap 'echo \"$1\" > \"$SIGNAL_FILE\"; exit 0' TERM\ntouch \"$READY_FILE\"\nwhile :; do sleep 0.02; done\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "custom-rec"), []byte(stub), 0o755))
+	t.Setenv("PATH", tempDir+":"+os.Getenv("PATH"))
+	t.Setenv("SIGNAL_FILE", signalFile)
+	t.Setenv("READY_FILE", readyFile)
+
+	backends, err := CommandBackends([]config.Backend{{Name: "custom", Record: []string{"custom-rec", "{output}"}, StopSignal: "SIGTERM"}})
+	require.NoError(t, err)
+	backend := backends[0]
+	require.True(t, backend.Available())
+
+	outPath := filepath.Join(tempDir, "out.wav")
+	stopCh := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- backend.Record(context.Background(), Config{OutputPath: outPath, StopCh: stopCh})
+	}()
+
+	waitForFile(t, readyFile, 5*time.Second)
+	close(stopCh)
+
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the command backend to stop")
+	}
+	got, err := os.ReadFile(signalFile)
+	require.NoError(t, err)
+	require.Equal(t, outPath+"\n", string(got))
+}
+
+func TestCommandBackendTakesPartInFallback(t *testing.T) {
+	tempDir := t.TempDir()
+	stub := "#!/bin/sh\nset -eu\nprintf 'RIFF' > \"$1\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "custom-rec"), []byte(stub), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "custom-list"), []byte("#!/bin/sh\necho 'port-1'\n"), 0o755))
+	t.Setenv("PATH", tempDir)
+
+	custom, err := CommandBackends([]config.Backend{{
+		Name:    "custom",
+		Record:  []string{"custom-rec", "{output}"},
+		Devices: []string{"custom-list"},
+	}})
+	require.NoError(t, err)
+
+	backends := DefaultBackends("linux", custom...)
+	require.Equal(t, "custom", backends[len(backends)-2].Name(), "custom backends precede the file backend")
+
+	outPath := filepath.Join(tempDir, "out.wav")
+	name, err := recordWithFallback(context.Background(), backends, "auto", Config{OutputPath: outPath})
+	require.NoError(t, err)
+	require.Equal(t, "custom", name, "unavailable built-in backends are skipped")
+	require.FileExists(t, outPath)
+
+	devices, err := custom[0].ListDevices(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "port-1", devices)
+}
@@ -0,0 +1,372 @@
+package record
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNoDeviceList reports a backend whose devices cannot be enumerated; its
+// ListDevices output is all there is.
+var ErrNoDeviceList = errors.New("backend does not enumerate devices")
+
+// DeviceKind tells microphones and other inputs apart from the monitors of
+// output devices, which record what is played.
+type DeviceKind string
+
+const (
+	DeviceSource  DeviceKind = "source"
+	DeviceMonitor DeviceKind = "monitor"
+)
+
+// Device is an input device of a backend. ID is what --input takes.
+// Channels is 0 when the listing does not tell.
+type Device struct {
+	Backend  string     `json:"backend"`
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Kind     DeviceKind `json:"kind"`
+	Default  bool       `json:"default"`
+	Channels int        `json:"channels,omitempty"`
+}
+
+// deviceParser is implemented by backends whose ListDevices output can be
+// parsed into devices.
+type deviceParser interface {
+	parseDevices(out string) []Device
+}
+
+// Devices lists the input devices of backend. Backends without a parser for
+// their listing return ErrNoDeviceList.
+func Devices(ctx context.Context, backend Backend) ([]Device, error) {
+	parser, ok := backend.(deviceParser)
+	if !ok {
+		return nil, ErrNoDeviceList
+	}
+	out, err := backend.ListDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	devices := parser.parseDevices(out)
+	hasDefault := false
+	for i := range devices {
+		devices[i].Backend = backend.Name()
+		hasDefault = hasDefault || devices[i].Default
+	}
+	// PulseAudio listings do not mark the default source; ask pactl.
+	if !hasDefault && len(devices) > 0 {
+		if name := pactlDefaultSource(ctx); name != "" {
+			for i := range devices {
+				devices[i].Default = devices[i].ID == name
+			}
+		}
+	}
+	return devices, nil
+}
+
+// pactlDefaultSource returns the name of the PulseAudio or PipeWire default
+// source, or "" when pactl is not installed or does not know it.
+func pactlDefaultSource(ctx context.Context) string {
+	if !commandAvailable("pactl") {
+		return ""
+	}
+	name, err := commandOutput(ctx, "pactl", "get-default-source")
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// parsePipeWireListing parses whichever listing the pw-record backend
+// produced: pw-cli ls Node, pw-record --list-targets or pactl.
+func parsePipeWireListing(out string) []Device {
+	switch {
+	case strings.Contains(out, "PipeWire:Interface:Node"):
+		return parsePWCLINodes(out)
+	case strings.Contains(out, "Available targets"):
+		return parsePWRecordTargets(out)
+	default:
+		return parsePactlSources(out)
+	}
+}
+
+// parsePWCLINodes parses pw-cli ls Node, keeping the audio sources and sinks;
+// recording from a sink records its monitor. The ID is the node name, which
+// pw-record --target accepts and which, unlike the node ID, survives reboots.
+func parsePWCLINodes(out string) []Device {
+	var devices []Device
+	var inNode bool
+	props := map[string]string{}
+	flush := func() {
+		if !inNode || props["node.name"] == "" {
+			return
+		}
+		var kind DeviceKind
+		switch class := props["media.class"]; {
+		case strings.HasPrefix(class, "Audio/Source"):
+			kind = DeviceSource
+		case strings.HasPrefix(class, "Audio/Sink"):
+			kind = DeviceMonitor
+		default:
+			return
+		}
+		name := props["node.description"]
+		if name == "" {
+			name = props["node.name"]
+		}
+		devices = append(devices, Device{ID: props["node.name"], Name: name, Kind: kind, Channels: atoiOrZero(props["audio.channels"])})
+	}
+
+	for _, line := range lines(out) {
+		if strings.HasPrefix(line, "id ") {
+			flush()
+			inNode = true
+			props = map[string]string{}
+			continue
+		}
+		key, value, ok := strings.Cut(line, " = ")
+		if !ok {
+			continue
+		}
+		props[strings.TrimSpace(strings.TrimPrefix(key, "*"))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	flush()
+	return devices
+}
+
+var pwTargetPattern = regexp.MustCompile(`^(\*)?\s*(\d+):\s*description="([^"]*)"`)
+
+// parsePWRecordTargets parses pw-record --list-targets, where "*" marks the
+// default target.
+func parsePWRecordTargets(out string) []Device {
+	var devices []Device
+	for _, line := range lines(out) {
+		match := pwTargetPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		devices = append(devices, Device{
+			ID:      match[2],
+			Name:    match[3],
+			Kind:    kindFromName(match[3]),
+			Default: match[1] == "*",
+		})
+	}
+	return devices
+}
+
+// parsePactlSources parses pactl list short sources: index, name, driver,
+// sample spec such as "s16le 2ch 48000Hz" and state, separated by tabs.
+func parsePactlSources(out string) []Device {
+	var devices []Device
+	for _, line := range lines(out) {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		device := Device{ID: fields[1], Name: fields[1], Kind: kindFromName(fields[1])}
+		if len(fields) > 3 {
+			for _, spec := range strings.Fields(fields[3]) {
+				if channels, ok := strings.CutSuffix(spec, "ch"); ok {
+					device.Channels = atoiOrZero(channels)
+				}
+			}
+		}
+		devices = append(devices, device)
+	}
+	return devices
+}
+
+// parseARecordDevices parses arecord -L: each device name starts a line and
+// its indented description follows. The null device is left out.
+func parseARecordDevices(out string) []Device {
+	var devices []Device
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if n := len(devices); n > 0 && devices[n-1].Name == devices[n-1].ID {
+				devices[n-1].Name = strings.TrimSpace(line)
+			}
+			continue
+		}
+		if line == "null" {
+			continue
+		}
+		devices = append(devices, Device{ID: line, Name: line, Kind: DeviceSource, Default: line == "default"})
+	}
+	return devices
+}
+
+var avfoundationDevicePattern = regexp.MustCompile(`\]\s*\[(\d+)\]\s*(.+)$`)
+
+// parseAVFoundationDevices parses the audio section of ffmpeg -f avfoundation
+// -list_devices true. Device :0 is what the ffmpeg backend records without
+// --input.
+func parseAVFoundationDevices(out string) []Device {
+	var devices []Device
+	audio := false
+	for _, line := range lines(out) {
+		switch {
+		case strings.Contains(line, "AVFoundation audio devices"):
+			audio = true
+			continue
+		case strings.Contains(line, "AVFoundation video devices"):
+			audio = false
+			continue
+		}
+		if !audio {
+			continue
+		}
+		match := avfoundationDevicePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		devices = append(devices, Device{
+			ID:      ":" + match[1],
+			Name:    strings.TrimSpace(match[2]),
+			Kind:    DeviceSource,
+			Default: match[1] == "0",
+		})
+	}
+	return devices
+}
+
+// parseFFMPEGLinuxDevices parses the PulseAudio and ALSA sections of the
+// Linux ffmpeg backend's listing.
+func parseFFMPEGLinuxDevices(out string) []Device {
+	var devices []Device
+	for _, section := range strings.Split(out, "\n\n") {
+		header, body, _ := strings.Cut(section, "\n")
+		switch {
+		case strings.HasPrefix(header, "PulseAudio/PipeWire sources:"):
+			devices = append(devices, parsePactlSources(body)...)
+		case strings.HasPrefix(header, "ALSA devices:"):
+			devices = append(devices, parseARecordDevices(body)...)
+		}
+	}
+	return devices
+}
+
+// kindFromName recognizes PulseAudio monitor sources by their name.
+func kindFromName(name string) DeviceKind {
+	if strings.HasSuffix(name, ".monitor") || strings.HasPrefix(name, "Monitor of ") {
+		return DeviceMonitor
+	}
+	return DeviceSource
+}
+
+// lines splits out into trimmed, non-empty lines.
+func lines(out string) []string {
+	var result []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+func atoiOrZero(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// inputNamePrefix selects an input device by name instead of by ID, as in
+// --input "name:Blue Yeti".
+const inputNamePrefix = "name:"
+
+// resolveInput turns an input of the form name:<query> into the ID of the
+// one device of backend whose name or ID matches query. Other inputs are
+// returned unchanged.
+func resolveInput(ctx context.Context, backend Backend, input string) (string, error) {
+	query, ok := strings.CutPrefix(input, inputNamePrefix)
+	if !ok {
+		return input, nil
+	}
+	if query = strings.TrimSpace(query); query == "" {
+		return "", errors.New(`--input name: needs a device name, e.g. "name:Blue Yeti"`)
+	}
+
+	devices, err := Devices(ctx, backend)
+	if errors.Is(err, ErrNoDeviceList) {
+		return "", fmt.Errorf("cannot select an input by name: %w", err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("list devices to resolve %q: %w", input, err)
+	}
+
+	matches := matchDevices(devices, query)
+	switch len(matches) {
+	case 1:
+		return matches[0].ID, nil
+	case 0:
+		if len(devices) == 0 {
+			return "", fmt.Errorf("no device matches %q: no devices found", query)
+		}
+		return "", fmt.Errorf("no device matches %q; devices: %s", query, describeDevices(devices))
+	default:
+		return "", fmt.Errorf("%q matches %d devices, use a more specific name: %s", query, len(matches), describeDevices(matches))
+	}
+}
+
+// matchDevices returns the devices whose name or ID contains every word of
+// query, ignoring case. Sources win over monitors, and a device whose name
+// equals query wins over the other matches.
+func matchDevices(devices []Device, query string) []Device {
+	words := strings.Fields(strings.ToLower(query))
+
+	var sources, monitors []Device
+	for _, device := range devices {
+		haystack := strings.ToLower(device.Name + " " + device.ID)
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(haystack, word) {
+				matched = false
+				break
+			}
+		}
+		switch {
+		case !matched:
+		case device.Kind == DeviceMonitor:
+			monitors = append(monitors, device)
+		default:
+			sources = append(sources, device)
+		}
+	}
+
+	matches := sources
+	if len(matches) == 0 {
+		matches = monitors
+	}
+	if len(matches) > 1 {
+		for _, device := range matches {
+			if strings.EqualFold(device.Name, query) || strings.EqualFold(device.ID, query) {
+				return []Device{device}
+			}
+		}
+	}
+	return matches
+}
+
+func describeDevices(devices []Device) string {
+	described := make([]string, 0, len(devices))
+	for _, device := range devices {
+		if device.Name == device.ID {
+			described = append(described, device.ID)
+			continue
+		}
+		described = append(described, fmt.Sprintf("%s (%s)", device.Name, device.ID))
+	}
+	return strings.Join(described, ", ")
+}
@@ -0,0 +1,158 @@
+package record
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// deviceFixture returns captured device listing output from testdata/devices.
+func deviceFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "testdata", "devices", name))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestParsePWCLINodes(t *testing.T) {
+	t.Parallel()
+
+	devices := parsePipeWireListing(trimLeadingWhitespacePerLine(deviceFixture(t, "pw-cli-ls-node.txt")))
+	require.Equal(t, []Device{
+		{ID: "alsa_output.pci-0000_00_1f.3.analog-stereo", Name: "Built-in Audio Analog Stereo", Kind: DeviceMonitor},
+		{ID: "alsa_input.pci-0000_00_1f.3.analog-stereo", Name: "Built-in Audio Analog Stereo", Kind: DeviceSource},
+		{ID: "alsa_input.usb-Blue_Microphones_Yeti_Stereo_Microphone_REV8-00.analog-stereo", Name: "Blue Yeti Analog Stereo", Kind: DeviceSource},
+	}, devices, "drivers and application streams are left out")
+}
+
+func TestParsePWRecordTargets(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []Device{
+		{ID: "49", Name: "Built-in Audio Analog Stereo", Kind: DeviceSource},
+		{ID: "62", Name: "Blue Yeti Analog Stereo", Kind: DeviceSource, Default: true},
+		{ID: "48", Name: "Monitor of Built-in Audio Analog Stereo", Kind: DeviceMonitor},
+	}, parsePipeWireListing(deviceFixture(t, "pw-record-list-targets.txt")))
+}
+
+func TestParsePactlSources(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []Device{
+		{ID: "alsa_output.pci-0000_00_1f.3.analog-stereo.monitor", Name: "alsa_output.pci-0000_00_1f.3.analog-stereo.monitor", Kind: DeviceMonitor, Channels: 2},
+		{ID: "alsa_input.pci-0000_00_1f.3.analog-stereo", Name: "alsa_input.pci-0000_00_1f.3.analog-stereo", Kind: DeviceSource, Channels: 2},
+		{ID: "alsa_input.usb-Blue_Microphones_Yeti_Stereo_Microphone_REV8-00.analog-stereo", Name: "alsa_input.usb-Blue_Microphones_Yeti_Stereo_Microphone_REV8-00.analog-stereo", Kind: DeviceSource, Channels: 2},
+		{ID: "bluez_input.00_1B_66_AA_BB_CC.0", Name: "bluez_input.00_1B_66_AA_BB_CC.0", Kind: DeviceSource, Channels: 1},
+	}, parsePactlSources(deviceFixture(t, "pactl-list-short-sources.txt")))
+}
+
+func TestParseARecordDevices(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []Device{
+		{ID: "default", Name: "Default ALSA Output (currently PipeWire Media Server)", Kind: DeviceSource, Default: true},
+		{ID: "pipewire", Name: "PipeWire Sound Server", Kind: DeviceSource},
+		{ID: "sysdefault:CARD=PCH", Name: "HDA Intel PCH, ALC257 Analog", Kind: DeviceSource},
+		{ID: "hw:CARD=PCH,DEV=0", Name: "HDA Intel PCH, ALC257 Analog", Kind: DeviceSource},
+		{ID: "plughw:CARD=PCH,DEV=0", Name: "HDA Intel PCH, ALC257 Analog", Kind: DeviceSource},
+		{ID: "hw:CARD=Microphone,DEV=0", Name: "Yeti Stereo Microphone, USB Audio", Kind: DeviceSource},
+	}, parseARecordDevices(deviceFixture(t, "arecord-L.txt")))
+}
+
+func TestParseAVFoundationDevices(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []Device{
+		{ID: ":0", Name: "MacBook Pro Microphone", Kind: DeviceSource, Default: true},
+		{ID: ":1", Name: "Yeti Stereo Microphone", Kind: DeviceSource},
+		{ID: ":2", Name: "BlackHole 2ch", Kind: DeviceSource},
+	}, parseAVFoundationDevices(deviceFixture(t, "ffmpeg-avfoundation-list-devices.txt")), "video devices are left out")
+}
+
+func TestParseFFMPEGLinuxDevices(t *testing.T) {
+	t.Parallel()
+
+	out := "PulseAudio/PipeWire sources:\n" + deviceFixture(t, "pactl-list-short-sources.txt") + "\nALSA devices:\n" + deviceFixture(t, "arecord-L.txt")
+	devices := parseFFMPEGLinuxDevices(out)
+	require.Len(t, devices, 10)
+	require.Equal(t, "bluez_input.00_1B_66_AA_BB_CC.0", devices[3].ID)
+	require.Equal(t, "default", devices[4].ID)
+}
+
+func TestDevicesMarksPactlDefaultSource(t *testing.T) {
+	tempDir := t.TempDir()
+	fixture, err := filepath.Abs(filepath.Join("..", "..", "testdata", "devices", "pactl-list-short-sources.txt"))
+	require.NoError(t, err)
+	stub := "#!/bin/sh\nif [ \"$1\" = get-default-source ]; then echo 'bluez_input.00_1B_66_AA_BB_CC.0'; else cat '" + fixture + "'; fi\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "pactl"), []byte(stub), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "parec"), []byte("#!/bin/sh\nexit 0\n"), 0o755))
+	t.Setenv("PATH", tempDir+":"+os.Getenv("PATH"))
+
+	devices, err := Devices(context.Background(), newParecBackend())
+	require.NoError(t, err)
+	require.Len(t, devices, 4)
+	for _, device := range devices {
+		require.Equal(t, "parec", device.Backend)
+		require.Equal(t, device.ID == "bluez_input.00_1B_66_AA_BB_CC.0", device.Default, device.ID)
+	}
+
+	_, err = Devices(context.Background(), newSoxBackend("alsa"))
+	require.ErrorIs(t, err, ErrNoDeviceList)
+}
+
+func TestMatchDevices(t *testing.T) {
+	t.Parallel()
+
+	devices := parsePWCLINodes(deviceFixture(t, "pw-cli-ls-node.txt"))
+
+	matches := matchDevices(devices, "blue YETI")
+	require.Len(t, matches, 1)
+	require.Equal(t, "alsa_input.usb-Blue_Microphones_Yeti_Stereo_Microphone_REV8-00.analog-stereo", matches[0].ID)
+
+	matches = matchDevices(devices, "built-in audio")
+	require.Len(t, matches, 1, "sources win over the monitor of the same name")
+	require.Equal(t, DeviceSource, matches[0].Kind)
+
+	require.Len(t, matchDevices(devices, "analog stereo"), 2)
+	require.Empty(t, matchDevices(devices, "rode"))
+
+	devices = parseARecordDevices(deviceFixture(t, "arecord-L.txt"))
+	matches = matchDevices(devices, "hw:CARD=PCH,DEV=0")
+	require.Len(t, matches, 1, "an exact ID wins over the devices containing it")
+	require.Equal(t, "hw:CARD=PCH,DEV=0", matches[0].ID)
+}
+
+func TestRecordWithFallbackResolvesInputByName(t *testing.T) {
+	tempDir := t.TempDir()
+	argsFile := filepath.Join(tempDir, "args.txt")
+	fixture, err := filepath.Abs(filepath.Join("..", "..", "testdata", "devices", "pactl-list-short-sources.txt"))
+	require.NoError(t, err)
+	pactl := "#!/bin/sh\nif [ \"$1\" = get-default-source ]; then exit 1; fi\ncat '" + fixture + "'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "pactl"), []byte(pactl), 0o755))
+	parec := "#!/bin/sh\nset -eu\nprintf '%s\\n' \"$@\" > \"$ARGS_FILE\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "parec"), []byte(parec), 0o755))
+	t.Setenv("PATH", tempDir+":"+os.Getenv("PATH"))
+	t.Setenv("ARGS_FILE", argsFile)
+
+	backends := []Backend{newParecBackend()}
+	outPath := filepath.Join(tempDir, "out.wav")
+
+	_, err = recordWithFallback(context.Background(), backends, "parec", Config{OutputPath: outPath, Input: "name:Blue Yeti"})
+	require.NoError(t, err)
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	require.Contains(t, string(args), "--device=alsa_input.usb-Blue_Microphones_Yeti_Stereo_Microphone_REV8-00.analog-stereo\n")
+
+	_, err = recordWithFallback(context.Background(), backends, "parec", Config{OutputPath: outPath, Input: "name:alsa_input"})
+	require.ErrorContains(t, err, `parec: "alsa_input" matches 2 devices, use a more specific name: alsa_input.pci-0000_00_1f.3.analog-stereo, alsa_input.usb-Blue`)
+
+	_, err = recordWithFallback(context.Background(), backends, "parec", Config{OutputPath: outPath, Input: "name:Rode"})
+	require.ErrorContains(t, err, `parec: no device matches "Rode"; devices: alsa_output.pci-0000_00_1f.3.analog-stereo.monitor, `)
+
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "rec"), []byte("#!/bin/sh\nexit 0\n"), 0o755))
+	_, err = recordWithFallback(context.Background(), []Backend{newSoxBackend("alsa")}, "sox", Config{OutputPath: outPath, Input: "name:Rode"})
+	require.ErrorIs(t, err, ErrNoDeviceList)
+}
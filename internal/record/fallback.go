@@ -77,7 +77,7 @@ func (b *ffmpegLinuxBackend) Record(ctx context.Context, cfg Config) error {
 		cmd.Stderr = os.Stderr
 
 		if cfg.StopCh != nil {
-			err := runSignalStopCommand(ctx, cmd, cfg.StopCh, cfg.Duration, cfg.Logger)
+			err := runSignalStopCommand(ctx, cmd, os.Interrupt, cfg.StopCh, cfg.Duration, cfg.Logger)
 			if err == nil {
 				return nil
 			}
@@ -89,7 +89,7 @@ func (b *ffmpegLinuxBackend) Record(ctx context.Context, cfg Config) error {
 		}
 
 		if cfg.Interactive {
-			err := runInteractiveCommand(ctx, cmd, cfg.Logger, cfg.InteractiveMessage)
+			err := runInteractiveCommand(ctx, cmd, os.Interrupt, cfg.Logger, cfg.InteractiveMessage)
 			if err == nil {
 				return nil
 			}
@@ -101,7 +101,7 @@ func (b *ffmpegLinuxBackend) Record(ctx context.Context, cfg Config) error {
 		}
 
 		if cfg.Duration > 0 {
-			err := runTimedCommand(ctx, cmd, cfg.Duration, cfg.Logger)
+			err := runTimedCommand(ctx, cmd, os.Interrupt, cfg.Duration, cfg.Logger)
 			if err == nil {
 				return nil
 			}
@@ -152,6 +152,10 @@ func (b *ffmpegLinuxBackend) ListDevices(ctx context.Context) (string, error) {
 	return strings.Join(sections, "\n\n"), nil
 }
 
+func (b *ffmpegLinuxBackend) parseDevices(out string) []Device {
+	return parseFFMPEGLinuxDevices(out)
+}
+
 func defaultSampleRate(value int) int {
 	if value <= 0 {
 		return 16000
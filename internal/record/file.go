@@ -0,0 +1,175 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+)
+
+const (
+	fileBackendName = "file"
+	// fileBackendPrefix selects the file backend together with the WAV to
+	// replay, as in --backend file:/path/to/sample.wav.
+	fileBackendPrefix = fileBackendName + ":"
+)
+
+// fileBackend replays a WAV file instead of recording from a microphone, so
+// the full recording flow runs on headless CI and in demos. The audio is
+// written in real time, so followers and timeouts behave as with a live
+// recording.
+type fileBackend struct {
+	path string
+	// chunk is how much audio is written at a time, and how often.
+	chunk time.Duration
+	// enterCh waits for Enter to stop an interactive replay.
+	enterCh func(message string) <-chan error
+}
+
+func newFileBackend(path string) Backend {
+	return &fileBackend{path: path, chunk: followInterval, enterCh: waitForTerminalEnter}
+}
+
+func (b *fileBackend) Name() string {
+	return fileBackendName
+}
+
+func (b *fileBackend) Available() bool {
+	return b.path != ""
+}
+
+func (b *fileBackend) unavailableReason() string {
+	return "select with --backend file:/path/to/audio.wav to replay a WAV file"
+}
+
+// Record writes the audio of the WAV file to cfg.OutputPath in its own format.
+// It stops after cfg.Duration, on cfg.StopCh or on Enter for interactive
+// recordings, padding the replay with silence once the file has ended, like a
+// microphone would. Without any of those it stops at the end of the file.
+func (b *fileBackend) Record(ctx context.Context, cfg Config) error {
+	if cfg.OutputPath == "" {
+		return fmt.Errorf("output path is required")
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	format, pcm, err := audio.ReadWAV(b.path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", b.path, err)
+	}
+	if format.BlockAlign() == 0 || format.SampleRate == 0 {
+		return fmt.Errorf("read %s: %w", b.path, audio.ErrUnsupportedWAV)
+	}
+	logger.Debug("replaying wav file",
+		zap.String("path", b.path),
+		zap.Int("sample_rate", format.SampleRate),
+		zap.Int("channels", format.Channels),
+		zap.Duration("length", format.Duration(len(pcm))),
+	)
+
+	var enterCh <-chan error
+	if cfg.Interactive && cfg.StopCh == nil {
+		enterCh = b.enterCh(cfg.InteractiveMessage)
+	}
+	limit := -1
+	if cfg.Duration > 0 {
+		limit = format.Bytes(cfg.Duration)
+	}
+	// Silence padding only makes sense when something else ends the replay.
+	pad := limit >= 0 || cfg.StopCh != nil || enterCh != nil
+
+	if err := os.MkdirAll(filepathDir(cfg.OutputPath), 0o755); err != nil {
+		return err
+	}
+	w, err := audio.CreateWAV(cfg.OutputPath, format)
+	if err != nil {
+		return err
+	}
+
+	chunkSize := max(format.Bytes(b.chunk), format.BlockAlign())
+	ticker := time.NewTicker(b.chunk)
+	defer ticker.Stop()
+
+	written := 0
+replay:
+	for {
+		next := pcm[min(written, len(pcm)):min(written+chunkSize, len(pcm))]
+		if len(next) == 0 && pad {
+			next = make([]byte, chunkSize)
+		}
+		if limit >= 0 && written+len(next) > limit {
+			next = next[:limit-written]
+		}
+		if len(next) == 0 {
+			break replay
+		}
+		if _, err := w.Write(next); err != nil {
+			_ = w.Close()
+			return err
+		}
+		written += len(next)
+
+		select {
+		case <-ticker.C:
+		case <-cfg.StopCh:
+			break replay
+		case err := <-enterCh:
+			if err != nil {
+				_ = w.Close()
+				return err
+			}
+			break replay
+		case <-ctx.Done():
+			_ = w.Close()
+			return ctx.Err()
+		}
+	}
+	return w.Close()
+}
+
+func (b *fileBackend) ListDevices(context.Context) (string, error) {
+	return "replaying " + b.path, nil
+}
+
+// waitForTerminalEnter waits for Enter when stdin is a terminal. Otherwise,
+// as on CI, it never fires and the replay ends with the file.
+func waitForTerminalEnter(message string) <-chan error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil
+	}
+	ch := make(chan error, 1)
+	go func() {
+		ch <- WaitForEnter(os.Stdin, os.Stderr, message)
+	}()
+	return ch
+}
+
+// withFileBackend applies a preferred backend of the form file:<path>: the
+// file backend replays path and is the only candidate, so a missing file is
+// reported instead of silently recording from a microphone.
+func withFileBackend(backends []Backend, preferred string) ([]Backend, string, error) {
+	path, ok := strings.CutPrefix(preferred, fileBackendPrefix)
+	if !ok {
+		return backends, preferred, nil
+	}
+	if path = strings.TrimSpace(path); path == "" {
+		return nil, "", errors.New("--backend file: needs a WAV path, e.g. file:/path/to/audio.wav")
+	}
+	return []Backend{newFileBackend(path)}, fileBackendName, nil
+}
+
+// UnavailableReason explains why backend is not available.
+func UnavailableReason(backend Backend) string {
+	if b, ok := backend.(interface{ unavailableReason() string }); ok {
+		return b.unavailableReason()
+	}
+	return "not available on PATH"
+}
@@ -0,0 +1,152 @@
+package record
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/stretchr/testify/require"
+)
+
+var replayFormat = audio.Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+
+// newTestFileBackend replays 100ms of audio from a temporary WAV in 10ms
+// chunks, as if stdin were not a terminal.
+func newTestFileBackend(t *testing.T) (*fileBackend, []byte) {
+	t.Helper()
+
+	pcm := make([]byte, replayFormat.Bytes(100*time.Millisecond))
+	for i := range pcm {
+		pcm[i] = byte(i%251 + 1)
+	}
+	path := filepath.Join(t.TempDir(), "sample.wav")
+	require.NoError(t, audio.WriteWAV(path, replayFormat, pcm))
+
+	b := newFileBackend(path).(*fileBackend)
+	b.chunk = 10 * time.Millisecond
+	b.enterCh = func(string) <-chan error { return nil }
+	return b, pcm
+}
+
+func replayed(t *testing.T, path string) []byte {
+	t.Helper()
+	format, pcm, err := audio.ReadWAV(path)
+	require.NoError(t, err)
+	require.Equal(t, replayFormat, format)
+	return pcm
+}
+
+func TestFileBackendReplaysFileInRealTime(t *testing.T) {
+	t.Parallel()
+
+	b, pcm := newTestFileBackend(t)
+	out := filepath.Join(t.TempDir(), "out", "recording.wav")
+
+	started := time.Now()
+	require.NoError(t, b.Record(context.Background(), Config{OutputPath: out, Interactive: true}))
+	require.GreaterOrEqual(t, time.Since(started), 90*time.Millisecond)
+	require.Equal(t, pcm, replayed(t, out), "without a terminal the replay ends with the file")
+}
+
+func TestFileBackendHonorsDuration(t *testing.T) {
+	t.Parallel()
+
+	b, pcm := newTestFileBackend(t)
+	out := filepath.Join(t.TempDir(), "recording.wav")
+
+	require.NoError(t, b.Record(context.Background(), Config{OutputPath: out, Duration: 150 * time.Millisecond}))
+	got := replayed(t, out)
+	require.Len(t, got, replayFormat.Bytes(150*time.Millisecond))
+	require.Equal(t, pcm, got[:len(pcm)])
+	require.Equal(t, make([]byte, len(got)-len(pcm)), got[len(pcm):], "the file is padded with silence")
+
+	require.NoError(t, b.Record(context.Background(), Config{OutputPath: out, Duration: 50 * time.Millisecond}))
+	require.Equal(t, pcm[:replayFormat.Bytes(50*time.Millisecond)], replayed(t, out))
+}
+
+func TestFileBackendStopsOnStopChannelAndEnter(t *testing.T) {
+	t.Parallel()
+
+	b, pcm := newTestFileBackend(t)
+	out := filepath.Join(t.TempDir(), "recording.wav")
+	stopCh := make(chan struct{})
+	time.AfterFunc(40*time.Millisecond, func() { close(stopCh) })
+
+	require.NoError(t, b.Record(context.Background(), Config{OutputPath: out, StopCh: stopCh, Duration: time.Minute}))
+	got := replayed(t, out)
+	require.Less(t, len(got), len(pcm))
+	require.Equal(t, pcm[:len(got)], got)
+
+	enter := make(chan error, 1)
+	b.enterCh = func(string) <-chan error { return enter }
+	time.AfterFunc(200*time.Millisecond, func() { enter <- nil })
+	require.NoError(t, b.Record(context.Background(), Config{OutputPath: out, Interactive: true}))
+	require.Greater(t, len(replayed(t, out)), len(pcm), "a terminal replay continues with silence until Enter")
+}
+
+func TestFileBackendCancel(t *testing.T) {
+	t.Parallel()
+
+	b, _ := newTestFileBackend(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	err := b.Record(ctx, Config{OutputPath: filepath.Join(t.TempDir(), "recording.wav"), Duration: time.Minute})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFileBackendStreamsFixtureToFollower(t *testing.T) {
+	t.Parallel()
+
+	fixture := filepath.Join("..", "..", "testdata", "audio", "fsdd", "1_jackson_0.wav")
+	format, want, err := audio.ReadWAV(fixture)
+	require.NoError(t, err)
+
+	backends, preferred, err := withFileBackend(DefaultBackends("linux"), "file:"+fixture)
+	require.NoError(t, err)
+	require.Len(t, backends, 1, "a replay never falls back to a microphone")
+
+	var mu sync.Mutex
+	var got []byte
+	var gotFormat audio.Format
+	out := filepath.Join(t.TempDir(), "recording.wav")
+	name, err := recordWithFallback(context.Background(), backends, preferred, Config{
+		OutputPath: out,
+		OnAudio: func(f audio.Format, pcm []byte) {
+			mu.Lock()
+			defer mu.Unlock()
+			gotFormat = f
+			got = append(got, pcm...)
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "file", name)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, format, gotFormat)
+	require.Equal(t, want, got)
+	_, recorded, err := audio.ReadWAV(out)
+	require.NoError(t, err)
+	require.Equal(t, want, recorded)
+}
+
+func TestWithFileBackend(t *testing.T) {
+	t.Parallel()
+
+	backends := DefaultBackends("linux")
+	got, preferred, err := withFileBackend(backends, "arecord")
+	require.NoError(t, err)
+	require.Equal(t, backends, got)
+	require.Equal(t, "arecord", preferred)
+
+	_, err = SelectBackend(backends, "file")
+	require.ErrorContains(t, err, "not available")
+	require.Contains(t, UnavailableReason(backends[len(backends)-1]), "--backend file:")
+
+	_, _, err = withFileBackend(backends, "file:")
+	require.ErrorContains(t, err, "needs a WAV path")
+}
@@ -0,0 +1,114 @@
+package record
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"go.uber.org/zap"
+)
+
+// followInterval is how often a recording in progress is checked for new
+// audio. Backends flush their output at least this often.
+const followInterval = 100 * time.Millisecond
+
+// wavFollower reads the audio a backend appends to its output WAV while it is
+// still recording.
+type wavFollower struct {
+	path   string
+	format audio.Format
+	// offset is the position of the next unread byte; zero until the header
+	// has been parsed.
+	offset  int64
+	onAudio func(format audio.Format, pcm []byte)
+}
+
+// followRecording passes the audio written to cfg.OutputPath to cfg.OnAudio
+// as it arrives. The returned function stops following after delivering the
+// audio written up to that point, so it must be called once the backend has
+// exited.
+func followRecording(cfg Config) (stop func()) {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	f := &wavFollower{path: cfg.OutputPath, onAudio: cfg.OnAudio}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(followInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				if err := f.poll(); err != nil {
+					logger.Debug("read final recorded audio", zap.Error(err))
+				}
+				return
+			case <-ticker.C:
+				if err := f.poll(); err != nil {
+					logger.Debug("read recorded audio", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// poll delivers the complete sample frames appended since the last call.
+func (f *wavFollower) poll() error {
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	// A backend retried after a failure truncates the file and starts over.
+	if f.offset > 0 && stat.Size() < f.offset {
+		f.offset = 0
+	}
+
+	if f.offset == 0 {
+		format, offset, err := audio.ReadFormat(file)
+		if errors.Is(err, audio.ErrInvalidWAV) {
+			// The header has not been written completely yet.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if format.BlockAlign() == 0 {
+			return audio.ErrUnsupportedWAV
+		}
+		f.format, f.offset = format, offset
+	}
+
+	block := int64(f.format.BlockAlign())
+	available := (stat.Size() - f.offset) / block * block
+	if available <= 0 {
+		return nil
+	}
+
+	pcm := make([]byte, available)
+	if _, err := file.ReadAt(pcm, f.offset); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	f.offset += available
+	f.onAudio(f.format, pcm)
+	return nil
+}
@@ -0,0 +1,105 @@
+package record
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/stretchr/testify/require"
+)
+
+// streamingWAVHeader returns a 16 kHz mono s16 header whose data size is
+// zero, as pw-record writes it before recording.
+func streamingWAVHeader() []byte {
+	header := []byte("RIFF\x00\x00\x00\x00WAVEfmt ")
+	header = binary.LittleEndian.AppendUint32(header, 16)
+	header = binary.LittleEndian.AppendUint16(header, 1)
+	header = binary.LittleEndian.AppendUint16(header, 1)
+	header = binary.LittleEndian.AppendUint32(header, 16000)
+	header = binary.LittleEndian.AppendUint32(header, 32000)
+	header = binary.LittleEndian.AppendUint16(header, 2)
+	header = binary.LittleEndian.AppendUint16(header, 16)
+	header = append(header, "data"...)
+	return binary.LittleEndian.AppendUint32(header, 0)
+}
+
+func TestRecordWithFallbackStreamsAudioWhileRecording(t *testing.T) {
+	t.Parallel()
+
+	outPath := filepath.Join(t.TempDir(), "live.wav")
+	var want []byte
+
+	var mu sync.Mutex
+	var got []byte
+	var deliveries int
+	var format audio.Format
+
+	backend := &stubBackend{name: "pw-record", available: true, recordFn: func(cfg Config) error {
+		f, err := os.Create(cfg.OutputPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		// Write the header in two steps to exercise a partially written file.
+		header := streamingWAVHeader()
+		if _, err := f.Write(header[:20]); err != nil {
+			return err
+		}
+		time.Sleep(2 * followInterval)
+		if _, err := f.Write(header[20:]); err != nil {
+			return err
+		}
+		for i := range 4 {
+			// An odd length leaves half a sample frame for the next poll.
+			block := make([]byte, 3201)
+			for j := range block {
+				block[j] = byte(i*7 + j)
+			}
+			want = append(want, block...)
+			if _, err := f.Write(block); err != nil {
+				return err
+			}
+			time.Sleep(2 * followInterval)
+		}
+		return nil
+	}}
+
+	_, err := recordWithFallback(context.Background(), []Backend{backend}, "auto", Config{
+		OutputPath: outPath,
+		OnAudio: func(f audio.Format, pcm []byte) {
+			mu.Lock()
+			defer mu.Unlock()
+			format = f
+			got = append(got, pcm...)
+			deliveries++
+		},
+	})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, audio.Format{SampleRate: 16000, Channels: 1, BitsPerSample: 16}, format)
+	require.Equal(t, want[:len(want)/2*2], got)
+	require.Greater(t, deliveries, 1, "audio must arrive while recording, not only at the end")
+}
+
+func TestWAVFollowerRestartsWhenFileIsRewritten(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "retry.wav")
+	var got []byte
+	follower := &wavFollower{path: path, onAudio: func(_ audio.Format, pcm []byte) { got = append(got, pcm...) }}
+
+	require.NoError(t, os.WriteFile(path, append(streamingWAVHeader(), 1, 2, 3, 4, 5, 6), 0o644))
+	require.NoError(t, follower.poll())
+	require.NoError(t, os.WriteFile(path, append(streamingWAVHeader(), 9, 9), 0o644))
+	require.NoError(t, follower.poll())
+
+	require.Equal(t, []byte{1, 2, 3, 4, 5, 6, 9, 9}, got)
+}
@@ -0,0 +1,82 @@
+package record
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// parecBackend records through PulseAudio's parec, for systems running plain
+// PulseAudio without PipeWire or ffmpeg.
+type parecBackend struct{}
+
+func newParecBackend() Backend {
+	return &parecBackend{}
+}
+
+func (b *parecBackend) Name() string {
+	return "parec"
+}
+
+func (b *parecBackend) Available() bool {
+	return commandAvailable("parec")
+}
+
+func (b *parecBackend) Record(ctx context.Context, cfg Config) error {
+	if cfg.OutputPath == "" {
+		return fmt.Errorf("output path is required")
+	}
+
+	if err := os.MkdirAll(filepathDir(cfg.OutputPath), 0o755); err != nil {
+		return err
+	}
+
+	// parec writes the WAV through libsndfile, which completes the header
+	// when parec exits on SIGINT.
+	args := []string{
+		"--file-format=wav",
+		"--format=s16le",
+		"--rate=" + strconv.Itoa(defaultSampleRate(cfg.SampleRate)),
+		"--channels=" + strconv.Itoa(defaultChannels(cfg.Channels)),
+	}
+	if cfg.Input != "" {
+		args = append(args, "--device="+cfg.Input)
+	}
+	args = append(args, cfg.OutputPath)
+
+	var cmd *exec.Cmd
+	if cfg.StopCh != nil || cfg.Duration > 0 {
+		cmd = exec.Command("parec", args...)
+	} else {
+		cmd = exec.CommandContext(ctx, "parec", args...)
+	}
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if cfg.StopCh != nil {
+		return runSignalStopCommand(ctx, cmd, os.Interrupt, cfg.StopCh, cfg.Duration, cfg.Logger)
+	}
+
+	if cfg.Interactive {
+		return runInteractiveCommand(ctx, cmd, os.Interrupt, cfg.Logger, cfg.InteractiveMessage)
+	}
+
+	if cfg.Duration > 0 {
+		return runTimedCommand(ctx, cmd, os.Interrupt, cfg.Duration, cfg.Logger)
+	}
+
+	return cmd.Run()
+}
+
+func (b *parecBackend) ListDevices(ctx context.Context) (string, error) {
+	if !commandAvailable("pactl") {
+		return "", fmt.Errorf("pactl not found; install pulseaudio-utils to list sources")
+	}
+	return commandOutput(ctx, "pactl", "list", "short", "sources")
+}
+
+func (b *parecBackend) parseDevices(out string) []Device {
+	return parsePactlSources(out)
+}
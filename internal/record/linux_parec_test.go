@@ -0,0 +1,132 @@
+package record
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParecPassesFormatAndDevice(t *testing.T) {
+	tempDir := t.TempDir()
+	argsFile := filepath.Join(tempDir, "args.txt")
+
+	stubPath := filepath.Join(tempDir, "parec")
+	stub := "#!/bin/sh\nset -eu\nprintf '%s\\n' \"$@\" > \"$ARGS_FILE\"\n"
+	require.NoError(t, os.WriteFile(stubPath, []byte(stub), 0o755))
+
+	t.Setenv("PATH", tempDir+":"+os.Getenv("PATH"))
+	t.Setenv("ARGS_FILE", argsFile)
+
+	backend := newParecBackend()
+	require.True(t, backend.Available())
+
+	outPath := filepath.Join(tempDir, "out.wav")
+	err := backend.Record(context.Background(), Config{
+		OutputPath: outPath,
+		SampleRate: 16000,
+		Channels:   1,
+		Input:      "alsa_input.usb-Blue_Yeti-00.analog-stereo",
+	})
+	require.NoError(t, err)
+
+	argsRaw, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	require.Equal(t, "--file-format=wav\n--format=s16le\n--rate=16000\n--channels=1\n--device=alsa_input.usb-Blue_Yeti-00.analog-stereo\n"+outPath+"\n", string(argsRaw))
+
+	require.NoError(t, backend.Record(context.Background(), Config{OutputPath: outPath}))
+	argsRaw, err = os.ReadFile(argsFile)
+	require.NoError(t, err)
+	require.NotContains(t, string(argsRaw), "--device")
+}
+
+func TestParecDurationModeStopsWithInterrupt(t *testing.T) {
+	tempDir := t.TempDir()
+	signalFile := filepath.Join(tempDir, "signal.txt")
+
+	stubPath := filepath.Join(tempDir, "parec")
+	stub := "#!/bin/sh\nset -eu\ntrap 'touch \"$SIGNAL_FILE\"; exit 0' INT\nwhile :; do sleep 0.02; done\n"
+	require.NoError(t, os.WriteFile(stubPath, []byte(stub), 0o755))
+
+	t.Setenv("PATH", tempDir+":"+os.Getenv("PATH"))
+	t.Setenv("SIGNAL_FILE", signalFile)
+
+	backend := newParecBackend()
+	err := backend.Record(context.Background(), Config{
+		OutputPath: filepath.Join(tempDir, "out.wav"),
+		Duration:   300 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	waitForFile(t, signalFile, 5*time.Second)
+}
+
+func TestParecSignalStopModeStopsOnChannel(t *testing.T) {
+	tempDir, readyFile := setupRunningCommandStub(t, "parec", false)
+
+	backend := newParecBackend()
+	require.True(t, backend.Available())
+
+	stopCh := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- backend.Record(context.Background(), Config{
+			OutputPath: filepath.Join(tempDir, "out.wav"),
+			StopCh:     stopCh,
+		})
+	}()
+
+	waitForFile(t, readyFile, 5*time.Second)
+	close(stopCh)
+
+	err := <-errCh
+	require.NoError(t, err)
+}
+
+func TestParecDurationModeReturnsContextCancellation(t *testing.T) {
+	tempDir, readyFile := setupRunningCommandStub(t, "parec", false)
+
+	backend := newParecBackend()
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- backend.Record(ctx, Config{
+			OutputPath: filepath.Join(tempDir, "out.wav"),
+			Duration:   3 * time.Second,
+		})
+	}()
+	t.Cleanup(cancel)
+
+	waitForFile(t, readyFile, 5*time.Second)
+	cancel()
+
+	err := <-errCh
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParecListsPulseAudioSources(t *testing.T) {
+	tempDir := t.TempDir()
+	stub := "#!/bin/sh\nprintf '1\\talsa_input.pci-0000_00_1f.3.analog-stereo\\tmodule-alsa-card.c\\ts16le 2ch 44100Hz\\tSUSPENDED\\n'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "pactl"), []byte(stub), 0o755))
+	t.Setenv("PATH", tempDir)
+
+	out, err := newParecBackend().ListDevices(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, out, "alsa_input.pci-0000_00_1f.3.analog-stereo")
+
+	t.Setenv("PATH", t.TempDir())
+	_, err = newParecBackend().ListDevices(context.Background())
+	require.ErrorContains(t, err, "pactl not found")
+}
+
+func TestDefaultLinuxBackendOrder(t *testing.T) {
+	t.Parallel()
+
+	var names []string
+	for _, backend := range DefaultBackends("linux") {
+		names = append(names, backend.Name())
+	}
+	require.Equal(t, []string{"pw-record", "parec", "arecord", "ffmpeg", "sox", "file"}, names)
+}
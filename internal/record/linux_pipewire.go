@@ -48,15 +48,15 @@ func (b *pipewireBackend) Record(ctx context.Context, cfg Config) error {
 	cmd.Stderr = os.Stderr
 
 	if cfg.StopCh != nil {
-		return runSignalStopCommand(ctx, cmd, cfg.StopCh, cfg.Duration, cfg.Logger)
+		return runSignalStopCommand(ctx, cmd, os.Interrupt, cfg.StopCh, cfg.Duration, cfg.Logger)
 	}
 
 	if cfg.Interactive {
-		return runInteractiveCommand(ctx, cmd, cfg.Logger, cfg.InteractiveMessage)
+		return runInteractiveCommand(ctx, cmd, os.Interrupt, cfg.Logger, cfg.InteractiveMessage)
 	}
 
 	if cfg.Duration > 0 {
-		return runTimedCommand(ctx, cmd, cfg.Duration, cfg.Logger)
+		return runTimedCommand(ctx, cmd, os.Interrupt, cfg.Duration, cfg.Logger)
 	}
 
 	return cmd.Run()
@@ -85,3 +85,7 @@ func (b *pipewireBackend) ListDevices(ctx context.Context) (string, error) {
 
 	return "", errors.New("no pipewire device listing command available")
 }
+
+func (b *pipewireBackend) parseDevices(out string) []Device {
+	return parsePipeWireListing(out)
+}
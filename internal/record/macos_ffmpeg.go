@@ -59,15 +59,15 @@ func (b *ffmpegMacBackend) Record(ctx context.Context, cfg Config) error {
 	cmd.Stderr = os.Stderr
 
 	if cfg.StopCh != nil {
-		return runSignalStopCommand(ctx, cmd, cfg.StopCh, cfg.Duration, cfg.Logger)
+		return runSignalStopCommand(ctx, cmd, os.Interrupt, cfg.StopCh, cfg.Duration, cfg.Logger)
 	}
 
 	if cfg.Interactive {
-		return runInteractiveCommand(ctx, cmd, cfg.Logger, cfg.InteractiveMessage)
+		return runInteractiveCommand(ctx, cmd, os.Interrupt, cfg.Logger, cfg.InteractiveMessage)
 	}
 
 	if cfg.Duration > 0 {
-		return runTimedCommand(ctx, cmd, cfg.Duration, cfg.Logger)
+		return runTimedCommand(ctx, cmd, os.Interrupt, cfg.Duration, cfg.Logger)
 	}
 
 	return cmd.Run()
@@ -82,3 +82,7 @@ func (b *ffmpegMacBackend) ListDevices(ctx context.Context) (string, error) {
 	}
 	return trimmed, nil
 }
+
+func (b *ffmpegMacBackend) parseDevices(out string) []Device {
+	return parseAVFoundationDevices(out)
+}
@@ -13,6 +13,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/fmueller/voxclip/internal/audio"
 	"go.uber.org/zap"
 	"golang.org/x/term"
 )
@@ -33,6 +34,13 @@ type Config struct {
 	StopCh             <-chan struct{}
 	InteractiveMessage string
 	Logger             *zap.Logger
+	// HighPass, when positive, is the cutoff frequency in Hz of a high-pass
+	// filter applied while recording. Only the sox backend supports it.
+	HighPass int
+	// OnAudio, when set, receives the recorded PCM audio in order while the
+	// backend is still recording. It is called from another goroutine and
+	// may block; the remaining audio is delivered once it returns.
+	OnAudio func(format audio.Format, pcm []byte)
 }
 
 type Backend interface {
@@ -68,30 +76,44 @@ func SelectBackend(backends []Backend, preferred string) (Backend, error) {
 	return nil, ErrNoBackendAvailable
 }
 
-func DefaultBackends(goos string) []Backend {
+// DefaultBackends returns the backends for goos in the order they are tried.
+// The custom backends, such as those from CommandBackends, follow the built-in
+// ones and precede the file backend.
+func DefaultBackends(goos string, custom ...Backend) []Backend {
+	var backends []Backend
 	switch goos {
 	case "linux":
-		return []Backend{newPipeWireBackend(), newALSARecorderBackend(), newFFMPEGLinuxBackend()}
+		backends = []Backend{newPipeWireBackend(), newParecBackend(), newALSARecorderBackend(), newFFMPEGLinuxBackend(), newSoxBackend("alsa")}
 	case "darwin":
-		return []Backend{newFFMPEGMacOSBackend()}
+		backends = []Backend{newFFMPEGMacOSBackend(), newSoxBackend("coreaudio")}
 	default:
 		return nil
 	}
+	backends = append(backends, custom...)
+	return append(backends, newFileBackend(""))
 }
 
-func NewBackend(preferred string) (Backend, error) {
-	backends := DefaultBackends(runtime.GOOS)
+func NewBackend(preferred string, custom ...Backend) (Backend, error) {
+	backends := DefaultBackends(runtime.GOOS, custom...)
 	if len(backends) == 0 {
 		return nil, fmt.Errorf("unsupported OS: %s", runtime.GOOS)
 	}
+	backends, preferred, err := withFileBackend(backends, preferred)
+	if err != nil {
+		return nil, err
+	}
 	return SelectBackend(backends, preferred)
 }
 
-func RecordWithFallback(ctx context.Context, preferred string, cfg Config) (string, error) {
-	backends := DefaultBackends(runtime.GOOS)
+func RecordWithFallback(ctx context.Context, preferred string, cfg Config, custom ...Backend) (string, error) {
+	backends := DefaultBackends(runtime.GOOS, custom...)
 	if len(backends) == 0 {
 		return "", fmt.Errorf("unsupported OS: %s", runtime.GOOS)
 	}
+	backends, preferred, err := withFileBackend(backends, preferred)
+	if err != nil {
+		return "", err
+	}
 
 	return recordWithFallback(ctx, backends, preferred, cfg)
 }
@@ -109,7 +131,18 @@ func recordWithFallback(ctx context.Context, backends []Backend, preferred strin
 			continue
 		}
 
-		err := backend.Record(ctx, cfg)
+		input, err := resolveInput(ctx, backend, cfg.Input)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", backend.Name(), err))
+			continue
+		}
+		backendCfg := cfg
+		backendCfg.Input = input
+		if input != cfg.Input && cfg.Logger != nil {
+			cfg.Logger.Info("input resolved by name", zap.String("backend", backend.Name()), zap.String("input", input))
+		}
+
+		err = recordFollowing(ctx, backend, backendCfg)
 		if err == nil {
 			return backend.Name(), nil
 		}
@@ -133,6 +166,15 @@ func recordWithFallback(ctx context.Context, backends []Backend, preferred strin
 	return "", fmt.Errorf("record audio with available backends: %w", errors.Join(errs...))
 }
 
+func recordFollowing(ctx context.Context, backend Backend, cfg Config) error {
+	if cfg.OnAudio == nil {
+		return backend.Record(ctx, cfg)
+	}
+	stop := followRecording(cfg)
+	defer stop()
+	return backend.Record(ctx, cfg)
+}
+
 func orderBackends(backends []Backend, preferred string) ([]Backend, error) {
 	if len(backends) == 0 {
 		return nil, errors.New("no backends configured")
@@ -194,7 +236,7 @@ func WaitForEnter(in io.Reader, out io.Writer, message string) error {
 	return err
 }
 
-func runInteractiveCommand(ctx context.Context, cmd *exec.Cmd, logger *zap.Logger, stopMessage string) error {
+func runInteractiveCommand(ctx context.Context, cmd *exec.Cmd, stop os.Signal, logger *zap.Logger, stopMessage string) error {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
@@ -204,12 +246,12 @@ func runInteractiveCommand(ctx context.Context, cmd *exec.Cmd, logger *zap.Logge
 	}
 
 	if err := WaitForEnter(os.Stdin, os.Stderr, stopMessage); err != nil {
-		_ = cmd.Process.Signal(os.Interrupt)
+		_ = cmd.Process.Signal(stop)
 		_ = cmd.Wait()
 		return err
 	}
 
-	stopSignalSent := cmd.Process.Signal(os.Interrupt) == nil
+	stopSignalSent := cmd.Process.Signal(stop) == nil
 	err := cmd.Wait()
 	if err == nil {
 		return nil
@@ -238,7 +280,7 @@ func runInteractiveCommand(ctx context.Context, cmd *exec.Cmd, logger *zap.Logge
 	}
 }
 
-func runTimedCommand(ctx context.Context, cmd *exec.Cmd, duration time.Duration, logger *zap.Logger) error {
+func runTimedCommand(ctx context.Context, cmd *exec.Cmd, stop os.Signal, duration time.Duration, logger *zap.Logger) error {
 	if duration <= 0 {
 		return cmd.Run()
 	}
@@ -264,7 +306,7 @@ func runTimedCommand(ctx context.Context, cmd *exec.Cmd, duration time.Duration,
 		case err := <-done:
 			return err
 		case <-timer.C:
-			stopSignalSent := signalInterrupt(cmd.Process)
+			stopSignalSent := signalStop(cmd.Process, stop)
 			err, exited := waitForDone(done, timedStopGracePeriod)
 			if !exited {
 				logger.Debug("recording process did not exit after timed stop signal; killing")
@@ -293,7 +335,7 @@ func runTimedCommand(ctx context.Context, cmd *exec.Cmd, duration time.Duration,
 
 			return err
 		case <-ctx.Done():
-			_ = signalInterrupt(cmd.Process)
+			_ = signalStop(cmd.Process, stop)
 			if _, exited := waitForDone(done, timedStopGracePeriod); !exited {
 				logger.Debug("recording process did not exit after context cancellation; killing")
 				_ = cmd.Process.Kill()
@@ -304,7 +346,7 @@ func runTimedCommand(ctx context.Context, cmd *exec.Cmd, duration time.Duration,
 	}
 }
 
-func runSignalStopCommand(ctx context.Context, cmd *exec.Cmd, stopCh <-chan struct{}, duration time.Duration, logger *zap.Logger) error {
+func runSignalStopCommand(ctx context.Context, cmd *exec.Cmd, stop os.Signal, stopCh <-chan struct{}, duration time.Duration, logger *zap.Logger) error {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
@@ -330,11 +372,11 @@ func runSignalStopCommand(ctx context.Context, cmd *exec.Cmd, stopCh <-chan stru
 		case err := <-done:
 			return err
 		case <-stopCh:
-			return shutdownProcess(cmd, done, true, logger)
+			return shutdownProcess(cmd, stop, done, true, logger)
 		case <-timerCh:
-			return shutdownProcess(cmd, done, true, logger)
+			return shutdownProcess(cmd, stop, done, true, logger)
 		case <-ctx.Done():
-			_ = signalInterrupt(cmd.Process)
+			_ = signalStop(cmd.Process, stop)
 			if _, exited := waitForDone(done, timedStopGracePeriod); !exited {
 				logger.Debug("recording process did not exit after context cancellation; killing")
 				_ = cmd.Process.Kill()
@@ -345,8 +387,8 @@ func runSignalStopCommand(ctx context.Context, cmd *exec.Cmd, stopCh <-chan stru
 	}
 }
 
-func shutdownProcess(cmd *exec.Cmd, done <-chan error, expectStop bool, logger *zap.Logger) error {
-	stopSignalSent := signalInterrupt(cmd.Process)
+func shutdownProcess(cmd *exec.Cmd, stop os.Signal, done <-chan error, expectStop bool, logger *zap.Logger) error {
+	stopSignalSent := signalStop(cmd.Process, stop)
 	err, exited := waitForDone(done, timedStopGracePeriod)
 	if !exited {
 		logger.Debug("recording process did not exit after stop signal; killing")
@@ -376,11 +418,11 @@ func shutdownProcess(cmd *exec.Cmd, done <-chan error, expectStop bool, logger *
 	return err
 }
 
-func signalInterrupt(process *os.Process) bool {
+func signalStop(process *os.Process, stop os.Signal) bool {
 	if process == nil {
 		return false
 	}
-	return process.Signal(os.Interrupt) == nil
+	return process.Signal(stop) == nil
 }
 
 func waitForDone(done <-chan error, timeout time.Duration) (error, bool) {
@@ -0,0 +1,12 @@
+package record
+
+import "syscall"
+
+// stopSignals are the signals a command backend may be stopped with. Windows
+// processes can only be interrupted or killed.
+var stopSignals = map[string]syscall.Signal{
+	"SIGINT":  syscall.SIGINT,
+	"SIGKILL": syscall.SIGKILL,
+}
+
+var stopSignalNames = []string{"SIGINT", "SIGKILL"}
@@ -0,0 +1,96 @@
+package record
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// soxBackend records through SoX, on Linux and macOS. It uses rec, SoX's
+// recording front end, and falls back to sox -d when only sox is installed.
+type soxBackend struct {
+	// driver is SoX's audio device driver for this OS, used to describe
+	// --input in device listings.
+	driver string
+}
+
+func newSoxBackend(driver string) Backend {
+	return &soxBackend{driver: driver}
+}
+
+func (b *soxBackend) Name() string {
+	return "sox"
+}
+
+func (b *soxBackend) Available() bool {
+	return commandAvailable("rec") || commandAvailable("sox")
+}
+
+func (b *soxBackend) Record(ctx context.Context, cfg Config) error {
+	if cfg.OutputPath == "" {
+		return fmt.Errorf("output path is required")
+	}
+	if cfg.HighPass < 0 {
+		return fmt.Errorf("high-pass frequency must not be negative, got %d", cfg.HighPass)
+	}
+
+	if err := os.MkdirAll(filepathDir(cfg.OutputPath), 0o755); err != nil {
+		return err
+	}
+
+	name, args := soxCommand()
+	args = append(args,
+		"-q",
+		"-r", strconv.Itoa(defaultSampleRate(cfg.SampleRate)),
+		"-c", strconv.Itoa(defaultChannels(cfg.Channels)),
+		"-b", "16",
+		"-e", "signed-integer",
+		cfg.OutputPath,
+	)
+	if cfg.HighPass > 0 {
+		args = append(args, "highpass", strconv.Itoa(cfg.HighPass))
+	}
+
+	var cmd *exec.Cmd
+	if cfg.StopCh != nil || cfg.Duration > 0 {
+		cmd = exec.Command(name, args...)
+	} else {
+		cmd = exec.CommandContext(ctx, name, args...)
+	}
+	// SoX's default device driver selects its input device through AUDIODEV.
+	if cfg.Input != "" {
+		cmd.Env = append(os.Environ(), "AUDIODEV="+cfg.Input)
+	}
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if cfg.StopCh != nil {
+		return runSignalStopCommand(ctx, cmd, os.Interrupt, cfg.StopCh, cfg.Duration, cfg.Logger)
+	}
+
+	if cfg.Interactive {
+		return runInteractiveCommand(ctx, cmd, os.Interrupt, cfg.Logger, cfg.InteractiveMessage)
+	}
+
+	if cfg.Duration > 0 {
+		return runTimedCommand(ctx, cmd, os.Interrupt, cfg.Duration, cfg.Logger)
+	}
+
+	return cmd.Run()
+}
+
+// ListDevices describes how to select an input, since SoX cannot list devices.
+func (b *soxBackend) ListDevices(context.Context) (string, error) {
+	return fmt.Sprintf("sox records from the default %s device; pass another %s device name with --input", b.driver, b.driver), nil
+}
+
+// soxCommand returns the command and leading arguments that record from the
+// default audio device.
+func soxCommand() (string, []string) {
+	if commandAvailable("rec") {
+		return "rec", nil
+	}
+	return "sox", []string{"-d"}
+}
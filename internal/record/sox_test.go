@@ -0,0 +1,108 @@
+package record
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setupSoxStub installs a stub that records its arguments and AUDIODEV, with
+// nothing else on PATH so an installed SoX does not interfere.
+func setupSoxStub(t *testing.T, name string) (string, string) {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	argsFile := filepath.Join(tempDir, "args.txt")
+
+	stub := "#!/bin/sh\nset -eu\nprintf '%s\\n' \"AUDIODEV=${AUDIODEV:-}\" \"$@\" > \"$ARGS_FILE\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, name), []byte(stub), 0o755))
+
+	t.Setenv("PATH", tempDir)
+	t.Setenv("ARGS_FILE", argsFile)
+	t.Setenv("AUDIODEV", "")
+	return tempDir, argsFile
+}
+
+func TestSoxRecordsWithRec(t *testing.T) {
+	tempDir, argsFile := setupSoxStub(t, "rec")
+
+	backend := newSoxBackend("alsa")
+	require.True(t, backend.Available())
+
+	outPath := filepath.Join(tempDir, "out.wav")
+	err := backend.Record(context.Background(), Config{
+		OutputPath: outPath,
+		SampleRate: 16000,
+		Channels:   1,
+		Input:      "hw:1,0",
+		HighPass:   80,
+	})
+	require.NoError(t, err)
+
+	argsRaw, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	require.Equal(t, "AUDIODEV=hw:1,0\n-q\n-r\n16000\n-c\n1\n-b\n16\n-e\nsigned-integer\n"+outPath+"\nhighpass\n80\n", string(argsRaw))
+
+	err = backend.Record(context.Background(), Config{OutputPath: outPath, HighPass: -1})
+	require.ErrorContains(t, err, "must not be negative")
+}
+
+func TestSoxFallsBackToSoxDefaultDevice(t *testing.T) {
+	tempDir, argsFile := setupSoxStub(t, "sox")
+
+	backend := newSoxBackend("coreaudio")
+	require.True(t, backend.Available())
+
+	outPath := filepath.Join(tempDir, "out.wav")
+	require.NoError(t, backend.Record(context.Background(), Config{OutputPath: outPath}))
+
+	argsRaw, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	require.Equal(t, "AUDIODEV=\n-d\n-q\n-r\n16000\n-c\n1\n-b\n16\n-e\nsigned-integer\n"+outPath+"\n", string(argsRaw))
+
+	devices, err := backend.ListDevices(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, devices, "default coreaudio device")
+}
+
+func TestSoxSignalStopModeStopsOnChannel(t *testing.T) {
+	tempDir, readyFile := setupRunningCommandStub(t, "rec", false)
+
+	backend := newSoxBackend("alsa")
+	stopCh := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- backend.Record(context.Background(), Config{
+			OutputPath: filepath.Join(tempDir, "out.wav"),
+			StopCh:     stopCh,
+		})
+	}()
+
+	waitForFile(t, readyFile, 5*time.Second)
+	close(stopCh)
+
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for sox to stop")
+	}
+}
+
+func TestDefaultMacOSBackendOrder(t *testing.T) {
+	t.Parallel()
+
+	var names []string
+	for _, backend := range DefaultBackends("darwin") {
+		names = append(names, backend.Name())
+	}
+	require.Equal(t, []string{"ffmpeg", "sox", "file"}, names)
+
+	ordered, err := orderBackends(DefaultBackends("darwin"), "sox")
+	require.NoError(t, err)
+	require.Equal(t, "sox", ordered[0].Name())
+}
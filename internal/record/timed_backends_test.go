@@ -88,7 +88,7 @@ func TestRunTimedCommandKillsWhenInterruptIgnored(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- runTimedCommand(ctx, cmd, 3*time.Second, nil)
+		errCh <- runTimedCommand(ctx, cmd, os.Interrupt, 3*time.Second, nil)
 	}()
 	t.Cleanup(cancel)
 
@@ -105,7 +105,7 @@ func TestRunTimedCommandKillsOnTimerWhenInterruptIgnored(t *testing.T) {
 	cmd := exec.Command(filepath.Join(tempDir, "ignore-int"))
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- runTimedCommand(context.Background(), cmd, 100*time.Millisecond, nil)
+		errCh <- runTimedCommand(context.Background(), cmd, os.Interrupt, 100*time.Millisecond, nil)
 	}()
 
 	err := <-errCh
@@ -119,7 +119,7 @@ func TestRunSignalStopCommandStopsOnChannel(t *testing.T) {
 	stopCh := make(chan struct{})
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- runSignalStopCommand(context.Background(), cmd, stopCh, 0, nil)
+		errCh <- runSignalStopCommand(context.Background(), cmd, os.Interrupt, stopCh, 0, nil)
 	}()
 
 	waitForFile(t, readyFile, 5*time.Second)
@@ -137,7 +137,7 @@ func TestRunSignalStopCommandReturnsContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- runSignalStopCommand(ctx, cmd, stopCh, 0, nil)
+		errCh <- runSignalStopCommand(ctx, cmd, os.Interrupt, stopCh, 0, nil)
 	}()
 	t.Cleanup(cancel)
 
@@ -155,7 +155,7 @@ func TestRunSignalStopCommandKillsWhenInterruptIgnored(t *testing.T) {
 	stopCh := make(chan struct{})
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- runSignalStopCommand(context.Background(), cmd, stopCh, 0, nil)
+		errCh <- runSignalStopCommand(context.Background(), cmd, os.Interrupt, stopCh, 0, nil)
 	}()
 
 	waitForFile(t, readyFile, 5*time.Second)
@@ -173,7 +173,7 @@ func TestRunSignalStopCommandSubprocessExitsOnItsOwn(t *testing.T) {
 
 	cmd := exec.Command(stubPath)
 	stopCh := make(chan struct{})
-	err := runSignalStopCommand(context.Background(), cmd, stopCh, 0, nil)
+	err := runSignalStopCommand(context.Background(), cmd, os.Interrupt, stopCh, 0, nil)
 	require.NoError(t, err)
 }
 
@@ -294,7 +294,7 @@ func TestRunSignalStopCommandStopsOnMaxDuration(t *testing.T) {
 	stopCh := make(chan struct{})
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- runSignalStopCommand(context.Background(), cmd, stopCh, 200*time.Millisecond, nil)
+		errCh <- runSignalStopCommand(context.Background(), cmd, os.Interrupt, stopCh, 200*time.Millisecond, nil)
 	}()
 
 	waitForFile(t, readyFile, 5*time.Second)
@@ -310,7 +310,7 @@ func TestRunSignalStopCommandSignalBeforeMaxDuration(t *testing.T) {
 	stopCh := make(chan struct{})
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- runSignalStopCommand(context.Background(), cmd, stopCh, 10*time.Second, nil)
+		errCh <- runSignalStopCommand(context.Background(), cmd, os.Interrupt, stopCh, 10*time.Second, nil)
 	}()
 
 	waitForFile(t, readyFile, 5*time.Second)
@@ -327,7 +327,7 @@ func TestRunSignalStopCommandZeroMaxDurationWaitsForSignal(t *testing.T) {
 	stopCh := make(chan struct{})
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- runSignalStopCommand(context.Background(), cmd, stopCh, 0, nil)
+		errCh <- runSignalStopCommand(context.Background(), cmd, os.Interrupt, stopCh, 0, nil)
 	}()
 
 	waitForFile(t, readyFile, 5*time.Second)
@@ -345,7 +345,7 @@ func TestRunSignalStopCommandContextCancelWithMaxDuration(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- runSignalStopCommand(ctx, cmd, stopCh, 10*time.Second, nil)
+		errCh <- runSignalStopCommand(ctx, cmd, os.Interrupt, stopCh, 10*time.Second, nil)
 	}()
 	t.Cleanup(cancel)
 
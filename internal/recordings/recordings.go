@@ -0,0 +1,198 @@
+package recordings
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/config"
+)
+
+// Pattern matches the files voxclip names in the recordings directory. Other
+// files there are never listed or removed.
+const Pattern = "recording-*.wav"
+
+// Recording is one WAV file in the recordings directory.
+type Recording struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Name returns the file name of r.
+func (r Recording) Name() string {
+	return filepath.Base(r.Path)
+}
+
+// List returns the recordings in dir, newest first. A missing directory has
+// no recordings.
+func List(dir string) ([]Recording, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, Pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	recs := make([]Recording, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("stat recording: %w", err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		recs = append(recs, Recording{Path: path, Size: info.Size(), ModTime: info.ModTime()})
+	}
+
+	sort.SliceStable(recs, func(i, j int) bool {
+		if !recs[i].ModTime.Equal(recs[j].ModTime) {
+			return recs[i].ModTime.After(recs[j].ModTime)
+		}
+		// Names carry the recording time, so they break ties in order.
+		return recs[i].Path > recs[j].Path
+	})
+	return recs, nil
+}
+
+// Policy limits what the recordings directory keeps. Zero values mean no
+// limit, and the zero Policy keeps every recording.
+type Policy struct {
+	MaxAge   time.Duration
+	MaxSize  int64
+	MaxCount int
+}
+
+// Unlimited reports whether p keeps every recording.
+func (p Policy) Unlimited() bool {
+	return p.MaxAge <= 0 && p.MaxSize <= 0 && p.MaxCount <= 0
+}
+
+// PolicyFromConfig parses the recordings section of the config file. Unset
+// limits, and "0", mean no limit, so recordings are only removed once the
+// user has set a limit.
+func PolicyFromConfig(cfg config.Recordings) (Policy, error) {
+	policy := Policy{MaxCount: cfg.MaxCount}
+	if cfg.MaxCount < 0 {
+		return Policy{}, fmt.Errorf("recordings max_count must not be negative, got %d", cfg.MaxCount)
+	}
+	if cfg.MaxAge != "" {
+		age, err := time.ParseDuration(cfg.MaxAge)
+		if err != nil {
+			return Policy{}, fmt.Errorf("recordings max_age: %w", err)
+		}
+		if age < 0 {
+			return Policy{}, fmt.Errorf("recordings max_age must not be negative, got %s", cfg.MaxAge)
+		}
+		policy.MaxAge = age
+	}
+	if cfg.MaxSize != "" {
+		size, err := ParseSize(cfg.MaxSize)
+		if err != nil {
+			return Policy{}, fmt.Errorf("recordings max_size: %w", err)
+		}
+		policy.MaxSize = size
+	}
+	return policy, nil
+}
+
+// Expired returns the recordings p removes, given recs newest first as List
+// returns them. The newest recording is always kept, so a policy never
+// deletes the file that was just recorded.
+func (p Policy) Expired(recs []Recording, now time.Time) []Recording {
+	var expired []Recording
+	var total int64
+	for i, rec := range recs {
+		total += rec.Size
+		switch {
+		case i == 0:
+		case p.MaxCount > 0 && i >= p.MaxCount,
+			p.MaxSize > 0 && total > p.MaxSize,
+			p.MaxAge > 0 && now.Sub(rec.ModTime) > p.MaxAge:
+			expired = append(expired, rec)
+		}
+	}
+	return expired
+}
+
+// Remove deletes recs and returns the ones that were removed. Files that are
+// already gone count as removed.
+func Remove(recs []Recording) ([]Recording, error) {
+	removed := make([]Recording, 0, len(recs))
+	var errs []error
+	for _, rec := range recs {
+		if err := os.Remove(rec.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, rec)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// Enforce removes the recordings in dir that p expires.
+func Enforce(dir string, p Policy, now time.Time) ([]Recording, error) {
+	if p.Unlimited() {
+		return nil, nil
+	}
+	recs, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	return Remove(p.Expired(recs, now))
+}
+
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30},
+	{"KB", 1000}, {"MB", 1000 * 1000}, {"GB", 1000 * 1000 * 1000},
+	{"K", 1000}, {"M", 1000 * 1000}, {"G", 1000 * 1000 * 1000},
+	{"B", 1},
+}
+
+// ParseSize parses a byte size such as "500MB", "2GiB" or "1048576". Units
+// are matched regardless of case.
+func ParseSize(s string) (int64, error) {
+	value := strings.TrimSpace(s)
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(strings.ToUpper(value), strings.ToUpper(unit.suffix)) {
+			value, multiplier = strings.TrimSpace(value[:len(value)-len(unit.suffix)]), unit.bytes
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid size %q; use bytes or a unit such as 500MB or 2GiB", s)
+	}
+	size := n * float64(multiplier)
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+	return int64(size), nil
+}
+
+// FormatSize renders n bytes for humans, e.g. "1.5 MB".
+func FormatSize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,165 @@
+package recordings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func writeRecording(t *testing.T, dir, name string, size int, age time.Duration) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0o644))
+	modTime := testNow.Add(-age)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	return path
+}
+
+func names(recs []Recording) []string {
+	out := make([]string, 0, len(recs))
+	for _, rec := range recs {
+		out = append(out, rec.Name())
+	}
+	return out
+}
+
+func TestListReturnsRecordingsNewestFirst(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeRecording(t, dir, "recording-20260101-090000.wav", 10, 48*time.Hour)
+	writeRecording(t, dir, "recording-20260102-090000.wav", 20, time.Hour)
+	writeRecording(t, dir, "notes.wav", 5, 0)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "recording-dir.wav"), 0o755))
+
+	recs, err := List(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"recording-20260102-090000.wav", "recording-20260101-090000.wav"}, names(recs))
+	require.EqualValues(t, 20, recs[0].Size)
+
+	recs, err = List(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	require.Empty(t, recs)
+}
+
+func TestPolicyExpired(t *testing.T) {
+	t.Parallel()
+
+	recs := []Recording{
+		{Path: "recording-4.wav", Size: 400, ModTime: testNow.Add(-72 * time.Hour)},
+		{Path: "recording-3.wav", Size: 300, ModTime: testNow.Add(-73 * time.Hour)},
+		{Path: "recording-2.wav", Size: 200, ModTime: testNow.Add(-74 * time.Hour)},
+		{Path: "recording-1.wav", Size: 100, ModTime: testNow.Add(-75 * time.Hour)},
+	}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		want   []string
+	}{
+		{name: "no limits", policy: Policy{}, want: []string{}},
+		{name: "count", policy: Policy{MaxCount: 2}, want: []string{"recording-2.wav", "recording-1.wav"}},
+		{name: "size", policy: Policy{MaxSize: 800}, want: []string{"recording-2.wav", "recording-1.wav"}},
+		{name: "age", policy: Policy{MaxAge: 73*time.Hour + time.Minute}, want: []string{"recording-2.wav", "recording-1.wav"}},
+		{name: "newest is always kept", policy: Policy{MaxAge: time.Hour, MaxSize: 1}, want: []string{"recording-3.wav", "recording-2.wav", "recording-1.wav"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, names(tt.policy.Expired(recs, testNow)))
+		})
+	}
+}
+
+func TestEnforceRemovesExpiredRecordings(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	kept := writeRecording(t, dir, "recording-20260228-090000.wav", 10, 24*time.Hour)
+	old := writeRecording(t, dir, "recording-20260101-090000.wav", 10, 60*24*time.Hour)
+	other := writeRecording(t, dir, "keep-me.wav", 10, 90*24*time.Hour)
+
+	removed, err := Enforce(dir, Policy{MaxAge: 30 * 24 * time.Hour}, testNow)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Base(old)}, names(removed))
+	require.FileExists(t, kept)
+	require.FileExists(t, other)
+	require.NoFileExists(t, old)
+}
+
+func TestEnforceWithoutLimitsKeepsEveryRecording(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	recent := writeRecording(t, dir, "recording-20260228-090000.wav", 10, 24*time.Hour)
+	old := writeRecording(t, dir, "recording-20250101-090000.wav", 10, 400*24*time.Hour)
+
+	removed, err := Enforce(dir, Policy{}, testNow)
+	require.NoError(t, err)
+	require.Empty(t, removed)
+	require.FileExists(t, recent)
+	require.FileExists(t, old)
+}
+
+func TestPolicyFromConfig(t *testing.T) {
+	t.Parallel()
+
+	policy, err := PolicyFromConfig(config.Recordings{})
+	require.NoError(t, err)
+	require.Equal(t, Policy{}, policy, "retention is opt-in")
+	require.True(t, policy.Unlimited())
+
+	policy, err = PolicyFromConfig(config.Recordings{MaxAge: "720h"})
+	require.NoError(t, err)
+	require.Equal(t, Policy{MaxAge: 30 * 24 * time.Hour}, policy)
+	require.False(t, policy.Unlimited())
+
+	policy, err = PolicyFromConfig(config.Recordings{MaxAge: "0", MaxSize: "1.5GiB", MaxCount: 50})
+	require.NoError(t, err)
+	require.Equal(t, Policy{MaxSize: 1536 << 20, MaxCount: 50}, policy)
+
+	_, err = PolicyFromConfig(config.Recordings{MaxAge: "30d"})
+	require.ErrorContains(t, err, "max_age")
+	_, err = PolicyFromConfig(config.Recordings{MaxSize: "lots"})
+	require.ErrorContains(t, err, "max_size")
+	_, err = PolicyFromConfig(config.Recordings{MaxCount: -1})
+	require.ErrorContains(t, err, "max_count")
+}
+
+func TestParseAndFormatSize(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]int64{
+		"1024":   1024,
+		"500MB":  500_000_000,
+		"2 GiB":  2 << 30,
+		"1.5KiB": 1536,
+		"750M":   750_000_000,
+		"500mb":  500_000_000,
+		"10k":    10_000,
+		"2gib":   2 << 30,
+	} {
+		got, err := ParseSize(input)
+		require.NoError(t, err, input)
+		require.Equal(t, want, got, input)
+	}
+
+	_, err := ParseSize("-5MB")
+	require.Error(t, err)
+	_, err = ParseSize("NaN")
+	require.Error(t, err)
+	for _, input := range []string{"1e30GB", "Inf", "10000000000GiB"} {
+		_, err = ParseSize(input)
+		require.ErrorContains(t, err, "too large", input)
+	}
+
+	require.Equal(t, "999 B", FormatSize(999))
+	require.Equal(t, "1.5 MB", FormatSize(1_500_000))
+	require.Equal(t, "2.0 GB", FormatSize(2_000_000_000))
+}
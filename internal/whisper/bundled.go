@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -32,13 +33,9 @@ func NewBundledEngine(logger *zap.Logger) (*BundledEngine, error) {
 		return &BundledEngine{Executable: override, Logger: logger}, nil
 	}
 
-	voxclipExe, err := os.Executable()
+	voxclipExe, err := currentExecutable()
 	if err != nil {
-		return nil, fmt.Errorf("resolve voxclip executable path: %w", err)
-	}
-	voxclipExe, err = filepath.EvalSymlinks(voxclipExe)
-	if err != nil {
-		return nil, fmt.Errorf("resolve voxclip executable symlinks: %w", err)
+		return nil, err
 	}
 
 	whisperExe, err := ResolveBundledEnginePath(voxclipExe)
@@ -60,65 +57,117 @@ func ResolveBundledEnginePath(voxclipExecutable string) (string, error) {
 }
 
 func EnginePathCandidates(voxclipExecutable string) []string {
+	return bundledPathCandidates(voxclipExecutable, engineBinaryName())
+}
+
+// bundledPathCandidates lists where a whisper.cpp binary shipped alongside
+// voxclip may live, in lookup order.
+func bundledPathCandidates(voxclipExecutable, binaryName string) []string {
 	binDir := filepath.Dir(voxclipExecutable)
-	engineName := engineBinaryName()
 	hostTarget := fmt.Sprintf("%s_%s", runtime.GOOS, normalizeArch(runtime.GOARCH))
 
 	return []string{
-		filepath.Join(binDir, "..", "libexec", "whisper", engineName),
-		filepath.Join(binDir, "libexec", "whisper", engineName),
-		filepath.Join(binDir, "packaging", "whisper", hostTarget, engineName),
-		filepath.Join(binDir, engineName),
+		filepath.Join(binDir, "..", "libexec", "whisper", binaryName),
+		filepath.Join(binDir, "libexec", "whisper", binaryName),
+		filepath.Join(binDir, "packaging", "whisper", hostTarget, binaryName),
+		filepath.Join(binDir, binaryName),
 	}
 }
 
-func (b *BundledEngine) Transcribe(ctx context.Context, req TranscriptionRequest) (string, error) {
+func currentExecutable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("resolve voxclip executable path: %w", err)
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return "", fmt.Errorf("resolve voxclip executable symlinks: %w", err)
+	}
+	return exe, nil
+}
+
+func (b *BundledEngine) Transcribe(ctx context.Context, req TranscriptionRequest) (Transcription, error) {
 	if strings.TrimSpace(req.AudioPath) == "" {
-		return "", errors.New("audio path is required")
+		return Transcription{}, errors.New("audio path is required")
 	}
 	if strings.TrimSpace(req.ModelPath) == "" {
-		return "", errors.New("model path is required")
+		return Transcription{}, errors.New("model path is required")
 	}
 
 	if err := ensureExecutable(b.Executable); err != nil {
-		return "", fmt.Errorf("bundled whisper engine missing or not executable: %w", err)
+		return Transcription{}, fmt.Errorf("bundled whisper engine missing or not executable: %w", err)
 	}
 
 	outBase := filepath.Join(os.TempDir(), fmt.Sprintf("voxclip-%d", time.Now().UnixNano()))
 	txtOut := outBase + ".txt"
+	jsonOut := outBase + ".json"
 
-	args := []string{"-m", req.ModelPath, "-f", req.AudioPath, "-nt", "-otxt", "-of", outBase}
+	args := []string{"-m", req.ModelPath, "-f", req.AudioPath, "-nt", "-otxt", "-oj", "-of", outBase}
 	lang := strings.TrimSpace(req.Language)
-	if lang != "" && lang != "auto" {
+	if !isAutoLanguage(lang) {
 		args = append(args, "-l", lang)
 	}
+	args = append(args, req.Decoding.cliArgs()...)
+	if req.Progress != nil {
+		args = append(args, "-pp")
+	}
 
 	cmd := exec.CommandContext(ctx, b.Executable, args...)
 	var stderr bytes.Buffer
 	cmd.Stdout = ioDiscard{}
 	cmd.Stderr = &stderr
+	if req.Progress != nil {
+		cmd.Stderr = io.MultiWriter(&stderr, newProgressWriter(req.Progress))
+	}
 
 	b.Logger.Debug("running whisper engine", zap.String("engine", b.Executable), zap.Strings("args", args))
 	if err := cmd.Run(); err != nil {
 		errText := strings.TrimSpace(stderr.String())
 		if isMissingSharedLibraryError(errText) {
-			return "", fmt.Errorf("bundled whisper engine at %s is missing required shared libraries (%s); reinstall Voxclip from an official release or rebuild whisper-cli with BUILD_SHARED_LIBS=OFF", b.Executable, errText)
+			return Transcription{}, fmt.Errorf("bundled whisper engine at %s is missing required shared libraries (%s); reinstall Voxclip from an official release or rebuild whisper-cli with BUILD_SHARED_LIBS=OFF", b.Executable, errText)
 		}
 		if isIllegalInstructionError(errText) || isIllegalInstructionError(err.Error()) {
-			return "", fmt.Errorf("bundled whisper engine crashed with an illegal CPU instruction; " +
+			return Transcription{}, fmt.Errorf("bundled whisper engine crashed with an illegal CPU instruction; " +
 				"your CPU may lack required instruction set extensions; " +
 				"set VOXCLIP_WHISPER_PATH to a whisper-cli binary built for your CPU")
 		}
-		return "", fmt.Errorf("whisper transcribe failed: %w (%s)", err, errText)
+		return Transcription{}, fmt.Errorf("whisper transcribe failed: %w (%s)", err, errText)
 	}
 
 	defer os.Remove(txtOut)
+	defer os.Remove(jsonOut)
 	content, err := os.ReadFile(txtOut)
 	if err != nil {
-		return "", fmt.Errorf("read whisper output: %w", err)
+		return Transcription{}, fmt.Errorf("read whisper output: %w", err)
 	}
 
-	return strings.TrimSpace(string(content)), nil
+	result := Transcription{Text: strings.TrimSpace(string(content))}
+	if segments, err := readCLISegments(jsonOut); err != nil {
+		b.Logger.Debug("whisper segment timings unavailable", zap.Error(err))
+	} else {
+		result.Segments = segments
+	}
+	if isAutoLanguage(lang) {
+		if detected, probability, ok := parseDetectedLanguage(stderr.String()); ok {
+			result.Language = detected
+			result.LanguageProbability = probability
+		}
+	} else {
+		result.Language = lang
+	}
+
+	return result, nil
+}
+
+func (b *BundledEngine) Preflight(_ context.Context, modelPath string) error {
+	if err := ensureExecutable(b.Executable); err != nil {
+		return fmt.Errorf("whisper runtime is not executable: %w", err)
+	}
+	return checkGGMLModel(modelPath)
+}
+
+func (b *BundledEngine) ReportsProgress() bool {
+	return true
 }
 
 type ioDiscard struct{}
@@ -128,10 +177,14 @@ func (ioDiscard) Write(p []byte) (int, error) {
 }
 
 func engineBinaryName() string {
+	return executableName("whisper-cli")
+}
+
+func executableName(name string) string {
 	if runtime.GOOS == "windows" {
-		return "whisper-cli.exe"
+		return name + ".exe"
 	}
-	return "whisper-cli"
+	return name
 }
 
 func ensureExecutable(path string) error {
@@ -1,13 +1,16 @@
 package whisper
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestResolveBundledEnginePathFindsLibexecSibling(t *testing.T) {
@@ -93,3 +96,72 @@ func TestIsIllegalInstructionError(t *testing.T) {
 	require.False(t, isIllegalInstructionError("some other runtime error"))
 	require.False(t, isIllegalInstructionError(""))
 }
+
+// writeStubEngine creates a fake whisper-cli that writes text to the -of
+// output base, as plain text and as a single -oj segment, and reports
+// auto-detection on stderr unless -l is passed.
+func writeStubEngine(t *testing.T, text, detected string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), engineBinaryName())
+	stub := `#!/bin/sh
+out=""
+lang=""
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -of) out="$2"; shift ;;
+    -l) lang="$2"; shift ;;
+    -pp) echo "whisper_print_progress_callback: progress =  50%" >&2 ;;
+  esac
+  shift
+done
+if [ -z "$lang" ]; then
+  echo "whisper_full_with_state: auto-detected language: ` + detected + ` (p = 0.612000)" >&2
+fi
+printf '%s\n' "` + text + `" > "$out.txt"
+printf '{"transcription": [{"offsets": {"from": 0, "to": 1500}, "text": "%s"}]}' "` + text + `" > "$out.json"
+`
+	require.NoError(t, os.WriteFile(path, []byte(stub), 0o755))
+	return path
+}
+
+func TestBundledEngineReportsDetectedLanguage(t *testing.T) {
+	t.Parallel()
+
+	engine := &BundledEngine{Executable: writeStubEngine(t, " hello world", "nn"), Logger: zap.NewNop()}
+
+	result, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: "in.wav", ModelPath: "model.bin", Language: "auto"})
+	require.NoError(t, err)
+	require.Equal(t, "hello world", result.Text)
+	require.Equal(t, "nn", result.Language)
+	require.InDelta(t, 0.612, result.LanguageProbability, 1e-9)
+	require.Equal(t, []Segment{{Start: 0, End: 1500 * time.Millisecond, Text: "hello world"}}, result.Segments)
+}
+
+func TestBundledEngineReportsExplicitLanguage(t *testing.T) {
+	t.Parallel()
+
+	engine := &BundledEngine{Executable: writeStubEngine(t, "hallo", "nn"), Logger: zap.NewNop()}
+
+	result, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: "in.wav", ModelPath: "model.bin", Language: "de"})
+	require.NoError(t, err)
+	require.Equal(t, "hallo", result.Text)
+	require.Equal(t, "de", result.Language)
+	require.Zero(t, result.LanguageProbability)
+}
+
+func TestBundledEngineReportsProgress(t *testing.T) {
+	t.Parallel()
+
+	engine := &BundledEngine{Executable: writeStubEngine(t, "hello", "en"), Logger: zap.NewNop()}
+
+	var reported []int
+	_, err := engine.Transcribe(context.Background(), TranscriptionRequest{
+		AudioPath: "in.wav",
+		ModelPath: "model.bin",
+		Language:  "en",
+		Progress:  func(percent int) { reported = append(reported, percent) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{50}, reported)
+}
@@ -0,0 +1,269 @@
+package whisper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/fmueller/voxclip/internal/audio"
+)
+
+// maxOverlapWords bounds how many repeated words are looked for where two
+// chunks meet. A second of overlap rarely holds more than a few words.
+const maxOverlapWords = 8
+
+const blankAudioMarker = "[BLANK_AUDIO]"
+
+// TranscribeChunks transcribes the chunks of a split recording with at most
+// workers concurrent engine runs and stitches the results into one
+// transcript with timestamps relative to the original recording.
+//
+// req.AudioPath is ignored; every chunk is transcribed with the rest of req.
+// When req.Progress is set it receives the overall percentage, weighted by
+// chunk length.
+func TranscribeChunks(ctx context.Context, engine Engine, req TranscriptionRequest, chunks []audio.Chunk, workers int) (Transcription, error) {
+	if len(chunks) == 0 {
+		return Transcription{}, nil
+	}
+	workers = max(min(workers, len(chunks)), 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	progress := newChunkProgress(chunks, req.Progress)
+	results := make([]Transcription, len(chunks))
+
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	slots := make(chan struct{}, workers)
+
+	for i, chunk := range chunks {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			chunkReq := req
+			chunkReq.AudioPath = chunk.Path
+			chunkReq.Progress = nil
+			if progress != nil {
+				chunkReq.Progress = func(percent int) { progress.report(i, percent) }
+			}
+
+			result, err := engine.Transcribe(ctx, chunkReq)
+			if err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("transcribe chunk %d (%s-%s): %w", i+1, chunk.KeepStart, chunk.KeepEnd, err)
+				}
+				errMu.Unlock()
+				cancel()
+				return
+			}
+			results[i] = result
+			if progress != nil {
+				progress.report(i, 100)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return Transcription{}, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return Transcription{}, err
+	}
+	return StitchChunks(chunks, results), nil
+}
+
+// StitchChunks joins per-chunk transcriptions. Segment times are shifted by
+// each chunk's offset, and segments are kept only by the chunk whose span
+// between cuts contains their midpoint, so speech in the overlap is not
+// transcribed twice. Words repeated across a join are removed as well, for
+// engines that do not report segments and for segments straddling a cut.
+func StitchChunks(chunks []audio.Chunk, results []Transcription) Transcription {
+	var stitched Transcription
+	var previous []string
+
+	for i, chunk := range chunks {
+		segments := keptSegments(chunk, results[i], i == len(chunks)-1)
+		if len(previous) > 0 {
+			segments = trimLeadingWords(segments, overlappingWords(previous, segmentWords(segments)))
+		}
+		stitched.Segments = append(stitched.Segments, segments...)
+		if words := segmentWords(segments); len(words) > 0 {
+			previous = words
+		}
+	}
+
+	texts := make([]string, 0, len(stitched.Segments))
+	for _, segment := range stitched.Segments {
+		texts = append(texts, segment.Text)
+	}
+	stitched.Text = strings.Join(texts, " ")
+	stitched.Language, stitched.LanguageProbability = dominantLanguage(chunks, results)
+	return stitched
+}
+
+func keptSegments(chunk audio.Chunk, result Transcription, last bool) []Segment {
+	if len(result.Segments) == 0 {
+		text := strings.TrimSpace(result.Text)
+		if text == "" || text == blankAudioMarker {
+			return nil
+		}
+		return []Segment{{Start: chunk.KeepStart, End: chunk.KeepEnd, Text: text}}
+	}
+
+	var kept []Segment
+	for _, segment := range result.Segments {
+		if segment.Text == blankAudioMarker {
+			continue
+		}
+		start := chunk.Start + segment.Start
+		end := chunk.Start + segment.End
+		mid := start + (end-start)/2
+		if mid < chunk.KeepStart || (mid >= chunk.KeepEnd && !last) {
+			continue
+		}
+		kept = append(kept, Segment{Start: start, End: end, Text: segment.Text})
+	}
+	return kept
+}
+
+func segmentWords(segments []Segment) []string {
+	var words []string
+	for _, segment := range segments {
+		words = append(words, strings.Fields(segment.Text)...)
+	}
+	return words
+}
+
+// overlappingWords returns the length of the longest run of words that ends
+// previous and starts next, comparing words case- and punctuation-blind.
+func overlappingWords(previous, next []string) int {
+	limit := min(len(previous), len(next), maxOverlapWords)
+	for n := limit; n > 0; n-- {
+		match := true
+		for j := 0; j < n; j++ {
+			if normalizeWord(previous[len(previous)-n+j]) != normalizeWord(next[j]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return n
+		}
+	}
+	return 0
+}
+
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	}))
+}
+
+func trimLeadingWords(segments []Segment, n int) []Segment {
+	for n > 0 && len(segments) > 0 {
+		words := strings.Fields(segments[0].Text)
+		if len(words) > n {
+			segments[0].Text = strings.Join(words[n:], " ")
+			return segments
+		}
+		n -= len(words)
+		segments = segments[1:]
+	}
+	return segments
+}
+
+// dominantLanguage picks the language covering the most audio and averages
+// its reported probability.
+func dominantLanguage(chunks []audio.Chunk, results []Transcription) (string, float64) {
+	coverage := map[string]time.Duration{}
+	probability := map[string]float64{}
+	count := map[string]int{}
+	for i, result := range results {
+		if result.Language == "" {
+			continue
+		}
+		coverage[result.Language] += chunks[i].KeepEnd - chunks[i].KeepStart
+		probability[result.Language] += result.LanguageProbability
+		count[result.Language]++
+	}
+
+	var best string
+	for _, result := range results {
+		lang := result.Language
+		if lang != "" && (best == "" || coverage[lang] > coverage[best]) {
+			best = lang
+		}
+	}
+	if best == "" {
+		return "", 0
+	}
+	return best, probability[best] / float64(count[best])
+}
+
+type chunkProgress struct {
+	mu       sync.Mutex
+	weights  []float64
+	percents []int
+	last     int
+	notify   func(int)
+}
+
+func newChunkProgress(chunks []audio.Chunk, report func(int)) *chunkProgress {
+	if report == nil {
+		return nil
+	}
+
+	var total time.Duration
+	for _, chunk := range chunks {
+		total += chunk.KeepEnd - chunk.KeepStart
+	}
+	weights := make([]float64, len(chunks))
+	for i, chunk := range chunks {
+		if total > 0 {
+			weights[i] = float64(chunk.KeepEnd-chunk.KeepStart) / float64(total)
+		} else {
+			weights[i] = 1 / float64(len(chunks))
+		}
+	}
+	return &chunkProgress{weights: weights, percents: make([]int, len(chunks)), last: -1, notify: report}
+}
+
+func (p *chunkProgress) report(chunk, percent int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if percent <= p.percents[chunk] {
+		return
+	}
+	p.percents[chunk] = percent
+
+	var overall float64
+	for i, value := range p.percents {
+		overall += p.weights[i] * float64(value)
+	}
+	rounded := int(overall)
+	if rounded == p.last {
+		return
+	}
+	p.last = rounded
+	p.notify(rounded)
+}
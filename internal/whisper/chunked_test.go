@@ -0,0 +1,144 @@
+package whisper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/audio"
+	"github.com/stretchr/testify/require"
+)
+
+func testChunks() []audio.Chunk {
+	return []audio.Chunk{
+		{Path: "chunk-000.wav", Start: 0, KeepStart: 0, KeepEnd: 10 * time.Second},
+		{Path: "chunk-001.wav", Start: 9 * time.Second, KeepStart: 10 * time.Second, KeepEnd: 20 * time.Second},
+		{Path: "chunk-002.wav", Start: 19 * time.Second, KeepStart: 20 * time.Second, KeepEnd: 25 * time.Second},
+	}
+}
+
+func TestStitchChunksShiftsTimestampsAndDropsOverlapSegments(t *testing.T) {
+	t.Parallel()
+
+	results := []Transcription{
+		{Segments: []Segment{
+			{Start: 0, End: 4 * time.Second, Text: "Good morning everyone."},
+			{Start: 4 * time.Second, End: 9500 * time.Millisecond, Text: "Let's start with the roadmap."},
+			{Start: 10200 * time.Millisecond, End: 11 * time.Second, Text: "First,"},
+		}},
+		{Segments: []Segment{
+			{Start: 0, End: 600 * time.Millisecond, Text: "roadmap."},
+			{Start: 1200 * time.Millisecond, End: 6 * time.Second, Text: "First, the release date."},
+		}},
+		{Segments: []Segment{
+			{Start: 1500 * time.Millisecond, End: 5 * time.Second, Text: "Thanks."},
+			{Start: 5 * time.Second, End: 6 * time.Second, Text: "[BLANK_AUDIO]"},
+		}},
+	}
+
+	stitched := StitchChunks(testChunks(), results)
+	require.Equal(t, "Good morning everyone. Let's start with the roadmap. First, the release date. Thanks.", stitched.Text)
+	require.Equal(t, []Segment{
+		{Start: 0, End: 4 * time.Second, Text: "Good morning everyone."},
+		{Start: 4 * time.Second, End: 9500 * time.Millisecond, Text: "Let's start with the roadmap."},
+		{Start: 10200 * time.Millisecond, End: 15 * time.Second, Text: "First, the release date."},
+		{Start: 20500 * time.Millisecond, End: 24 * time.Second, Text: "Thanks."},
+	}, stitched.Segments)
+}
+
+func TestStitchChunksRemovesRepeatedWordsWithoutSegments(t *testing.T) {
+	t.Parallel()
+
+	results := []Transcription{
+		{Text: "we should ship on Friday", Language: "en", LanguageProbability: 0.9},
+		{Text: "on friday, after the review", Language: "en", LanguageProbability: 0.7},
+		{Text: "[BLANK_AUDIO]"},
+	}
+
+	stitched := StitchChunks(testChunks(), results)
+	require.Equal(t, "we should ship on Friday after the review", stitched.Text)
+	require.Equal(t, "en", stitched.Language)
+	require.InDelta(t, 0.8, stitched.LanguageProbability, 1e-9)
+	require.Equal(t, 10*time.Second, stitched.Segments[1].Start)
+}
+
+func TestStitchChunksPicksLanguageCoveringMostAudio(t *testing.T) {
+	t.Parallel()
+
+	results := []Transcription{
+		{Text: "hallo", Language: "de"},
+		{Text: "hello", Language: "en"},
+		{Text: "welt", Language: "de"},
+	}
+
+	stitched := StitchChunks(testChunks(), results)
+	require.Equal(t, "de", stitched.Language)
+}
+
+func TestTranscribeChunksRespectsWorkerBudget(t *testing.T) {
+	t.Parallel()
+
+	var running, peak atomic.Int32
+	engine := EngineFunc(func(_ context.Context, req TranscriptionRequest) (Transcription, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return Transcription{Text: req.AudioPath, Language: req.Language}, nil
+	})
+
+	chunks := append(testChunks(), testChunks()...)
+	result, err := TranscribeChunks(context.Background(), engine, TranscriptionRequest{Language: "en"}, chunks, 2)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, peak.Load())
+	require.Equal(t, "chunk-000.wav chunk-001.wav chunk-002.wav chunk-000.wav chunk-001.wav chunk-002.wav", result.Text)
+	require.Equal(t, "en", result.Language)
+}
+
+func TestTranscribeChunksStopsOnFirstError(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	engine := EngineFunc(func(ctx context.Context, req TranscriptionRequest) (Transcription, error) {
+		calls.Add(1)
+		if req.AudioPath == "chunk-000.wav" {
+			return Transcription{}, errors.New("model crashed")
+		}
+		<-ctx.Done()
+		return Transcription{}, ctx.Err()
+	})
+
+	_, err := TranscribeChunks(context.Background(), engine, TranscriptionRequest{}, testChunks(), 1)
+	require.ErrorContains(t, err, "transcribe chunk 1")
+	require.ErrorContains(t, err, "model crashed")
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestTranscribeChunksReportsWeightedProgress(t *testing.T) {
+	t.Parallel()
+
+	engine := EngineFunc(func(_ context.Context, req TranscriptionRequest) (Transcription, error) {
+		req.Progress(50)
+		return Transcription{Text: "x"}, nil
+	})
+
+	var mu sync.Mutex
+	var reported []int
+	req := TranscriptionRequest{Progress: func(percent int) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, percent)
+	}}
+
+	_, err := TranscribeChunks(context.Background(), engine, req, testChunks(), 1)
+	require.NoError(t, err)
+	require.Equal(t, []int{20, 40, 60, 80, 90, 100}, reported)
+}
@@ -0,0 +1,118 @@
+package whisper
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DecodingOptions tunes whisper's decoder. Zero values leave whisper-cli's
+// own defaults in place.
+type DecodingOptions struct {
+	Threads              int
+	BeamSize             int
+	BestOf               int
+	Temperature          float64
+	TemperatureIncrement float64
+	NoFallback           bool
+	EntropyThreshold     float64
+	LogprobThreshold     float64
+	MaxSegmentLength     int
+	SplitOnWord          bool
+	// ExtraArgs are appended to the whisper-cli invocation verbatim.
+	ExtraArgs []string
+}
+
+// reservedEngineArgs are managed by BundledEngine and must not be overridden
+// through ExtraArgs, otherwise voxclip would lose track of its output or
+// conflict with its own --language, --languages and progress handling.
+var reservedEngineArgs = []string{
+	"-m", "--model",
+	"-f", "--file",
+	"-of", "--output-file",
+	"-otxt", "--output-txt",
+	"-oj", "--output-json",
+	"-nt", "--no-timestamps",
+	"-l", "--language",
+	"-pp", "--print-progress",
+}
+
+func (o DecodingOptions) Validate() error {
+	var errs []error
+	if o.Threads < 0 {
+		errs = append(errs, fmt.Errorf("threads must not be negative, got %d", o.Threads))
+	}
+	if o.BeamSize < 0 {
+		errs = append(errs, fmt.Errorf("beam size must not be negative, got %d", o.BeamSize))
+	}
+	if o.BestOf < 0 {
+		errs = append(errs, fmt.Errorf("best-of must not be negative, got %d", o.BestOf))
+	}
+	if o.Temperature < 0 || o.Temperature > 1 {
+		errs = append(errs, fmt.Errorf("temperature must be between 0 and 1, got %g", o.Temperature))
+	}
+	if o.TemperatureIncrement < 0 || o.TemperatureIncrement > 1 {
+		errs = append(errs, fmt.Errorf("temperature increment must be between 0 and 1, got %g", o.TemperatureIncrement))
+	}
+	if o.NoFallback && o.TemperatureIncrement > 0 {
+		errs = append(errs, errors.New("temperature increment has no effect when fallback is disabled"))
+	}
+	if o.EntropyThreshold < 0 {
+		errs = append(errs, fmt.Errorf("entropy threshold must not be negative, got %g", o.EntropyThreshold))
+	}
+	if o.LogprobThreshold > 0 {
+		errs = append(errs, fmt.Errorf("logprob threshold must not be positive, got %g", o.LogprobThreshold))
+	}
+	if o.MaxSegmentLength < 0 {
+		errs = append(errs, fmt.Errorf("max segment length must not be negative, got %d", o.MaxSegmentLength))
+	}
+	for _, arg := range o.ExtraArgs {
+		name, _, _ := strings.Cut(arg, "=")
+		if slices.Contains(reservedEngineArgs, name) {
+			errs = append(errs, fmt.Errorf("whisper argument %s is managed by voxclip and cannot be passed through", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cliArgs renders the options as whisper-cli arguments.
+func (o DecodingOptions) cliArgs() []string {
+	var args []string
+	if o.Threads > 0 {
+		args = append(args, "-t", strconv.Itoa(o.Threads))
+	}
+	if o.BeamSize > 0 {
+		args = append(args, "-bs", strconv.Itoa(o.BeamSize))
+	}
+	if o.BestOf > 0 {
+		args = append(args, "-bo", strconv.Itoa(o.BestOf))
+	}
+	if o.Temperature > 0 {
+		args = append(args, "-tp", formatFloat(o.Temperature))
+	}
+	if o.TemperatureIncrement > 0 {
+		args = append(args, "-tpi", formatFloat(o.TemperatureIncrement))
+	}
+	if o.NoFallback {
+		args = append(args, "-nf")
+	}
+	if o.EntropyThreshold > 0 {
+		args = append(args, "-et", formatFloat(o.EntropyThreshold))
+	}
+	if o.LogprobThreshold < 0 {
+		args = append(args, "-lpt", formatFloat(o.LogprobThreshold))
+	}
+	if o.MaxSegmentLength > 0 {
+		args = append(args, "-ml", strconv.Itoa(o.MaxSegmentLength))
+	}
+	if o.SplitOnWord {
+		args = append(args, "-sow")
+	}
+	return append(args, o.ExtraArgs...)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,85 @@
+package whisper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodingOptionsZeroValueAddsNoArgs(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, DecodingOptions{}.Validate())
+	require.Empty(t, DecodingOptions{}.cliArgs())
+}
+
+func TestDecodingOptionsCLIArgs(t *testing.T) {
+	t.Parallel()
+
+	opts := DecodingOptions{
+		Threads:          4,
+		BeamSize:         5,
+		BestOf:           3,
+		Temperature:      0.1,
+		EntropyThreshold: 2.8,
+		LogprobThreshold: -0.8,
+		MaxSegmentLength: 60,
+		SplitOnWord:      true,
+		NoFallback:       true,
+		ExtraArgs:        []string{"--prompt", "voxclip"},
+	}
+	require.NoError(t, opts.Validate())
+	require.Equal(t, []string{
+		"-t", "4",
+		"-bs", "5",
+		"-bo", "3",
+		"-tp", "0.1",
+		"-nf",
+		"-et", "2.8",
+		"-lpt", "-0.8",
+		"-ml", "60",
+		"-sow",
+		"--prompt", "voxclip",
+	}, opts.cliArgs())
+}
+
+func TestDecodingOptionsTemperatureIncrement(t *testing.T) {
+	t.Parallel()
+
+	opts := DecodingOptions{TemperatureIncrement: 0.4}
+	require.NoError(t, opts.Validate())
+	require.Equal(t, []string{"-tpi", "0.4"}, opts.cliArgs())
+}
+
+func TestDecodingOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		opts        DecodingOptions
+		errContains string
+	}{
+		{name: "negative threads", opts: DecodingOptions{Threads: -1}, errContains: "threads"},
+		{name: "negative beam size", opts: DecodingOptions{BeamSize: -2}, errContains: "beam size"},
+		{name: "negative best-of", opts: DecodingOptions{BestOf: -1}, errContains: "best-of"},
+		{name: "temperature too high", opts: DecodingOptions{Temperature: 1.5}, errContains: "temperature must be"},
+		{name: "increment without fallback", opts: DecodingOptions{NoFallback: true, TemperatureIncrement: 0.2}, errContains: "fallback is disabled"},
+		{name: "negative entropy", opts: DecodingOptions{EntropyThreshold: -1}, errContains: "entropy threshold"},
+		{name: "positive logprob", opts: DecodingOptions{LogprobThreshold: 0.5}, errContains: "logprob threshold"},
+		{name: "negative max length", opts: DecodingOptions{MaxSegmentLength: -1}, errContains: "max segment length"},
+		{name: "reserved extra arg", opts: DecodingOptions{ExtraArgs: []string{"-of", "/tmp/x"}}, errContains: "managed by voxclip"},
+		{name: "reserved extra arg with value", opts: DecodingOptions{ExtraArgs: []string{"--model=/tmp/m.bin"}}, errContains: "managed by voxclip"},
+		{name: "reserved language", opts: DecodingOptions{ExtraArgs: []string{"-l=fr"}}, errContains: "-l is managed by voxclip"},
+		{name: "reserved long language", opts: DecodingOptions{ExtraArgs: []string{"--language", "fr"}}, errContains: "--language is managed by voxclip"},
+		{name: "reserved no timestamps", opts: DecodingOptions{ExtraArgs: []string{"-nt"}}, errContains: "-nt is managed by voxclip"},
+		{name: "reserved print progress", opts: DecodingOptions{ExtraArgs: []string{"-pp"}}, errContains: "-pp is managed by voxclip"},
+		{name: "reserved json output", opts: DecodingOptions{ExtraArgs: []string{"-oj"}}, errContains: "-oj is managed by voxclip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.ErrorContains(t, tt.opts.Validate(), tt.errContains)
+		})
+	}
+}
@@ -1,13 +1,58 @@
 package whisper
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type TranscriptionRequest struct {
 	AudioPath string
 	ModelPath string
 	Language  string
+	Decoding  DecodingOptions
+	// Progress, when set, receives the completion percentage (0-100) as the
+	// engine reports it. It may be called from another goroutine.
+	Progress func(percent int)
+}
+
+// Transcription is the outcome of a single engine run.
+type Transcription struct {
+	Text string
+	// Language is the language the transcript was decoded in: the requested
+	// language, or the engine's detection when the request used "auto".
+	// It is empty when auto-detection ran but the engine did not report it.
+	Language string
+	// LanguageProbability is the engine's confidence in an auto-detected
+	// Language; zero when the language was set explicitly.
+	LanguageProbability float64
+	// LanguageProbabilities are the detection scores of all languages, by
+	// code, for engines that report them. Only auto-detection fills it.
+	LanguageProbabilities map[string]float64
+	// Segments are the timed pieces of Text, relative to the start of the
+	// audio. Engines that cannot report timing leave it empty.
+	Segments []Segment
+}
+
+// Segment is a span of transcribed text with its position in the audio.
+type Segment struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
 }
 
 type Engine interface {
-	Transcribe(ctx context.Context, req TranscriptionRequest) (string, error)
+	Transcribe(ctx context.Context, req TranscriptionRequest) (Transcription, error)
+}
+
+// EngineFunc adapts a function to the Engine interface.
+type EngineFunc func(ctx context.Context, req TranscriptionRequest) (Transcription, error)
+
+func (f EngineFunc) Transcribe(ctx context.Context, req TranscriptionRequest) (Transcription, error) {
+	return f(ctx, req)
+}
+
+// ProgressReporter is implemented by engines that honor
+// TranscriptionRequest.Progress.
+type ProgressReporter interface {
+	ReportsProgress() bool
 }
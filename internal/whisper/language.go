@@ -0,0 +1,151 @@
+package whisper
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// AutoLanguage asks the engine to detect the spoken language.
+const AutoLanguage = "auto"
+
+var detectedLanguagePattern = regexp.MustCompile(`auto-detected language:\s*([a-z]{2,3}(?:-[a-z]+)?)\s*\(p\s*=\s*([0-9.]+)\)`)
+
+// parseDetectedLanguage extracts the language whisper-cli reports on stderr
+// when it runs with auto-detection, e.g.
+// "whisper_full_with_state: auto-detected language: en (p = 0.973684)".
+func parseDetectedLanguage(stderr string) (string, float64, bool) {
+	match := detectedLanguagePattern.FindStringSubmatch(strings.ToLower(stderr))
+	if match == nil {
+		return "", 0, false
+	}
+
+	probability, err := strconv.ParseFloat(match[2], 64)
+	if err != nil {
+		probability = 0
+	}
+	return match[1], probability, true
+}
+
+// NormalizeLanguages lower-cases, trims and de-duplicates an auto-detection
+// allowlist. Entries may themselves be comma-separated.
+func NormalizeLanguages(values []string) ([]string, error) {
+	var languages []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			lang := strings.ToLower(strings.TrimSpace(part))
+			if lang == "" {
+				continue
+			}
+			if lang == AutoLanguage {
+				return nil, fmt.Errorf("language allowlist must list concrete languages, not %q", AutoLanguage)
+			}
+			if !slices.Contains(languages, lang) {
+				languages = append(languages, lang)
+			}
+		}
+	}
+	return languages, nil
+}
+
+// TranscribeWithAllowedLanguages runs the engine with auto-detection and, if
+// the detected language is not in allowed, re-runs it with the most likely
+// allowed language according to the engine's per-language scores. Engines
+// that only report their top guess, such as whisper-cli, re-run with the
+// first allowed language, so allowed is also ordered by preference. An empty
+// allowlist or an explicit request language transcribes once without any
+// restriction.
+func TranscribeWithAllowedLanguages(ctx context.Context, engine Engine, req TranscriptionRequest, allowed []string, logger *zap.Logger) (Transcription, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	result, err := engine.Transcribe(ctx, req)
+	if err != nil {
+		return Transcription{}, err
+	}
+
+	if len(allowed) == 0 || !isAutoLanguage(req.Language) {
+		return result, nil
+	}
+
+	if result.Language == "" {
+		logger.Warn("engine did not report a detected language; keeping auto-detected transcript", zap.Strings("allowed_languages", allowed))
+		return result, nil
+	}
+
+	if slices.Contains(allowed, result.Language) {
+		return result, nil
+	}
+
+	fallback, scored := mostLikelyLanguage(allowed, result.LanguageProbabilities)
+	logger.Info(
+		"detected language not allowed; transcribing again",
+		zap.String("detected_language", result.Language),
+		zap.Float64("language_probability", result.LanguageProbability),
+		zap.String("language", fallback),
+		zap.Bool("scored", scored),
+	)
+
+	retry := req
+	retry.Language = fallback
+	return engine.Transcribe(ctx, retry)
+}
+
+// mostLikelyLanguage returns the allowed language with the highest score in
+// probabilities. Without a score for any allowed language it returns the
+// first one and false.
+func mostLikelyLanguage(allowed []string, probabilities map[string]float64) (string, bool) {
+	best, bestProbability := allowed[0], -1.0
+	for _, lang := range allowed {
+		if probability, ok := probabilities[lang]; ok && probability > bestProbability {
+			best, bestProbability = lang, probability
+		}
+	}
+	return best, bestProbability >= 0
+}
+
+func isAutoLanguage(lang string) bool {
+	lang = strings.TrimSpace(lang)
+	return lang == "" || lang == AutoLanguage
+}
+
+// languageNames maps the language names whisper reports, e.g. in OpenAI
+// verbose_json responses, to the codes used by --language.
+var languageNames = map[string]string{
+	"afrikaans": "af", "albanian": "sq", "amharic": "am", "arabic": "ar", "armenian": "hy",
+	"assamese": "as", "azerbaijani": "az", "bashkir": "ba", "basque": "eu", "belarusian": "be",
+	"bengali": "bn", "bosnian": "bs", "breton": "br", "bulgarian": "bg", "cantonese": "yue",
+	"catalan": "ca", "chinese": "zh", "croatian": "hr", "czech": "cs", "danish": "da",
+	"dutch": "nl", "english": "en", "estonian": "et", "faroese": "fo", "finnish": "fi",
+	"french": "fr", "galician": "gl", "georgian": "ka", "german": "de", "greek": "el",
+	"gujarati": "gu", "haitian creole": "ht", "hausa": "ha", "hawaiian": "haw", "hebrew": "he",
+	"hindi": "hi", "hungarian": "hu", "icelandic": "is", "indonesian": "id", "italian": "it",
+	"japanese": "ja", "javanese": "jw", "kannada": "kn", "kazakh": "kk", "khmer": "km",
+	"korean": "ko", "lao": "lo", "latin": "la", "latvian": "lv", "lingala": "ln",
+	"lithuanian": "lt", "luxembourgish": "lb", "macedonian": "mk", "malagasy": "mg", "malay": "ms",
+	"malayalam": "ml", "maltese": "mt", "maori": "mi", "marathi": "mr", "mongolian": "mn",
+	"myanmar": "my", "nepali": "ne", "norwegian": "no", "nynorsk": "nn", "occitan": "oc",
+	"pashto": "ps", "persian": "fa", "polish": "pl", "portuguese": "pt", "punjabi": "pa",
+	"romanian": "ro", "russian": "ru", "sanskrit": "sa", "serbian": "sr", "shona": "sn",
+	"sindhi": "sd", "sinhala": "si", "slovak": "sk", "slovenian": "sl", "somali": "so",
+	"spanish": "es", "sundanese": "su", "swahili": "sw", "swedish": "sv", "tagalog": "tl",
+	"tajik": "tg", "tamil": "ta", "tatar": "tt", "telugu": "te", "thai": "th",
+	"tibetan": "bo", "turkish": "tr", "turkmen": "tk", "ukrainian": "uk", "urdu": "ur",
+	"uzbek": "uz", "vietnamese": "vi", "welsh": "cy", "yiddish": "yi", "yoruba": "yo",
+}
+
+// languageCode turns a reported language into a code. Values that already
+// look like codes and unknown names are passed through lower-cased.
+func languageCode(reported string) string {
+	lang := strings.ToLower(strings.TrimSpace(reported))
+	if code, ok := languageNames[lang]; ok {
+		return code
+	}
+	return lang
+}
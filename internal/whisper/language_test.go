@@ -0,0 +1,158 @@
+package whisper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEngine struct {
+	results  map[string]Transcription
+	err      error
+	requests []TranscriptionRequest
+}
+
+func (f *fakeEngine) Transcribe(_ context.Context, req TranscriptionRequest) (Transcription, error) {
+	f.requests = append(f.requests, req)
+	if f.err != nil {
+		return Transcription{}, f.err
+	}
+	return f.results[req.Language], nil
+}
+
+func TestParseDetectedLanguage(t *testing.T) {
+	t.Parallel()
+
+	stderr := "whisper_init_from_file_with_params_no_state: loading model\n" +
+		"whisper_full_with_state: auto-detected language: cy (p = 0.412345)\n"
+
+	lang, probability, ok := parseDetectedLanguage(stderr)
+	require.True(t, ok)
+	require.Equal(t, "cy", lang)
+	require.InDelta(t, 0.412345, probability, 1e-9)
+}
+
+func TestParseDetectedLanguageMissing(t *testing.T) {
+	t.Parallel()
+
+	_, _, ok := parseDetectedLanguage("whisper_full_with_state: processing 16000 samples")
+	require.False(t, ok)
+}
+
+func TestNormalizeLanguages(t *testing.T) {
+	t.Parallel()
+
+	languages, err := NormalizeLanguages([]string{" EN", "de,en", "", "fr "})
+	require.NoError(t, err)
+	require.Equal(t, []string{"en", "de", "fr"}, languages)
+}
+
+func TestNormalizeLanguagesRejectsAuto(t *testing.T) {
+	t.Parallel()
+
+	_, err := NormalizeLanguages([]string{"en", "auto"})
+	require.Error(t, err)
+}
+
+func TestTranscribeWithAllowedLanguagesKeepsAllowedDetection(t *testing.T) {
+	t.Parallel()
+
+	engine := &fakeEngine{results: map[string]Transcription{
+		"auto": {Text: "hallo welt", Language: "de", LanguageProbability: 0.9},
+	}}
+
+	result, err := TranscribeWithAllowedLanguages(context.Background(), engine, TranscriptionRequest{Language: "auto"}, []string{"en", "de"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "hallo welt", result.Text)
+	require.Equal(t, "de", result.Language)
+	require.Len(t, engine.requests, 1)
+}
+
+func TestTranscribeWithAllowedLanguagesRetriesWithPreferredLanguage(t *testing.T) {
+	t.Parallel()
+
+	engine := &fakeEngine{results: map[string]Transcription{
+		"auto": {Text: "helo byd", Language: "cy", LanguageProbability: 0.4},
+		"en":   {Text: "hello world", Language: "en"},
+	}}
+
+	result, err := TranscribeWithAllowedLanguages(context.Background(), engine, TranscriptionRequest{Language: "auto"}, []string{"en", "de"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "hello world", result.Text)
+	require.Equal(t, "en", result.Language)
+	require.Len(t, engine.requests, 2)
+	require.Equal(t, "en", engine.requests[1].Language)
+}
+
+func TestTranscribeWithAllowedLanguagesRetriesWithMostLikelyAllowedLanguage(t *testing.T) {
+	t.Parallel()
+
+	engine := &fakeEngine{results: map[string]Transcription{
+		"auto": {
+			Text:                  "hallo wereld",
+			Language:              "nl",
+			LanguageProbability:   0.55,
+			LanguageProbabilities: map[string]float64{"nl": 0.55, "de": 0.35, "en": 0.05},
+		},
+		"de": {Text: "hallo Welt", Language: "de"},
+	}}
+
+	result, err := TranscribeWithAllowedLanguages(context.Background(), engine, TranscriptionRequest{Language: "auto"}, []string{"en", "de"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "hallo Welt", result.Text)
+	require.Len(t, engine.requests, 2)
+	require.Equal(t, "de", engine.requests[1].Language)
+}
+
+func TestMostLikelyLanguage(t *testing.T) {
+	t.Parallel()
+
+	lang, scored := mostLikelyLanguage([]string{"en", "de", "fr"}, map[string]float64{"nl": 0.6, "fr": 0.1, "de": 0.2})
+	require.Equal(t, "de", lang)
+	require.True(t, scored)
+
+	lang, scored = mostLikelyLanguage([]string{"en", "de"}, map[string]float64{"nl": 0.6})
+	require.Equal(t, "en", lang, "falls back to the first allowed language")
+	require.False(t, scored)
+
+	lang, scored = mostLikelyLanguage([]string{"en", "de"}, nil)
+	require.Equal(t, "en", lang)
+	require.False(t, scored)
+}
+
+func TestTranscribeWithAllowedLanguagesIgnoresAllowlistForExplicitLanguage(t *testing.T) {
+	t.Parallel()
+
+	engine := &fakeEngine{results: map[string]Transcription{
+		"fr": {Text: "bonjour", Language: "fr"},
+	}}
+
+	result, err := TranscribeWithAllowedLanguages(context.Background(), engine, TranscriptionRequest{Language: "fr"}, []string{"en"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "bonjour", result.Text)
+	require.Len(t, engine.requests, 1)
+}
+
+func TestTranscribeWithAllowedLanguagesKeepsResultWhenDetectionUnknown(t *testing.T) {
+	t.Parallel()
+
+	engine := &fakeEngine{results: map[string]Transcription{
+		"auto": {Text: "hello"},
+	}}
+
+	result, err := TranscribeWithAllowedLanguages(context.Background(), engine, TranscriptionRequest{Language: "auto"}, []string{"de"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "hello", result.Text)
+	require.Len(t, engine.requests, 1)
+}
+
+func TestTranscribeWithAllowedLanguagesReturnsEngineError(t *testing.T) {
+	t.Parallel()
+
+	engine := &fakeEngine{err: errors.New("boom")}
+
+	_, err := TranscribeWithAllowedLanguages(context.Background(), engine, TranscriptionRequest{Language: "auto"}, []string{"en"}, nil)
+	require.EqualError(t, err, "boom")
+}
@@ -0,0 +1,43 @@
+package whisper
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ggmlMagic is the little-endian file magic of whisper.cpp ggml models.
+const ggmlMagic = 0x67676d6c
+
+// Preflighter is implemented by engines that can check they are able to run
+// before any audio is recorded: binaries are present, a server is reachable,
+// or the model is in a format the engine loads. modelPath is empty for
+// engines that do not use a local model.
+type Preflighter interface {
+	Preflight(ctx context.Context, modelPath string) error
+}
+
+// checkGGMLModel verifies that path is a whisper.cpp ggml model rather than,
+// say, a GGUF, safetensors or CTranslate2 file for another runtime.
+func checkGGMLModel(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open model: %w", err)
+	}
+	defer f.Close()
+
+	var magic uint32
+	if err := binary.Read(f, binary.LittleEndian, &magic); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("model %s is too small to be a whisper.cpp ggml model", path)
+		}
+		return fmt.Errorf("read model: %w", err)
+	}
+	if magic != ggmlMagic {
+		return fmt.Errorf("model %s is not a whisper.cpp ggml model", path)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package whisper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeModelFile(t *testing.T, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "model.bin")
+	require.NoError(t, os.WriteFile(path, content, 0o644))
+	return path
+}
+
+func TestCheckGGMLModel(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, checkGGMLModel(writeModelFile(t, []byte("lmgg\x00\x00\x00\x00"))))
+	require.ErrorContains(t, checkGGMLModel(writeModelFile(t, []byte("GGUF\x03\x00\x00\x00"))), "not a whisper.cpp ggml model")
+	require.ErrorContains(t, checkGGMLModel(writeModelFile(t, []byte("lm"))), "too small")
+	require.ErrorContains(t, checkGGMLModel(filepath.Join(t.TempDir(), "missing.bin")), "open model")
+}
@@ -0,0 +1,62 @@
+package whisper
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+var progressPattern = regexp.MustCompile(`progress\s*=\s*(\d{1,3})%`)
+
+// parseProgress extracts the percentage from a whisper-cli --print-progress
+// line, e.g. "whisper_print_progress_callback: progress =  45%".
+func parseProgress(line []byte) (int, bool) {
+	match := progressPattern.FindSubmatch(line)
+	if match == nil {
+		return 0, false
+	}
+	percent, err := strconv.Atoi(string(match[1]))
+	if err != nil || percent > 100 {
+		return 0, false
+	}
+	return percent, true
+}
+
+// progressWriter scans whisper-cli stderr line by line and reports every
+// progress percentage it finds.
+type progressWriter struct {
+	mu       sync.Mutex
+	pending  []byte
+	report   func(percent int)
+	reported int
+}
+
+func newProgressWriter(report func(percent int)) *progressWriter {
+	return &progressWriter{report: report, reported: -1}
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, p...)
+	for {
+		idx := bytes.IndexAny(w.pending, "\r\n")
+		if idx < 0 {
+			break
+		}
+		w.scan(w.pending[:idx])
+		w.pending = w.pending[idx+1:]
+	}
+	return len(p), nil
+}
+
+func (w *progressWriter) scan(line []byte) {
+	percent, ok := parseProgress(line)
+	if !ok || percent == w.reported {
+		return
+	}
+	w.reported = percent
+	w.report(percent)
+}
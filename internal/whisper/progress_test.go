@@ -0,0 +1,40 @@
+package whisper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProgress(t *testing.T) {
+	t.Parallel()
+
+	percent, ok := parseProgress([]byte("whisper_print_progress_callback: progress =  45%"))
+	require.True(t, ok)
+	require.Equal(t, 45, percent)
+
+	_, ok = parseProgress([]byte("whisper_full_with_state: auto-detected language: en (p = 0.97)"))
+	require.False(t, ok)
+
+	_, ok = parseProgress([]byte("progress = 450%"))
+	require.False(t, ok)
+}
+
+func TestProgressWriterReportsAcrossSplitWrites(t *testing.T) {
+	t.Parallel()
+
+	var reported []int
+	w := newProgressWriter(func(percent int) { reported = append(reported, percent) })
+
+	for _, chunk := range []string{
+		"whisper_print_progress_callback: progr",
+		"ess =   5%\nwhisper_print_progress_callback: progress =   5%\n",
+		"some other log line\r\nwhisper_print_progress_callback: progress = 100%\n",
+	} {
+		n, err := w.Write([]byte(chunk))
+		require.NoError(t, err)
+		require.Equal(t, len(chunk), n)
+	}
+
+	require.Equal(t, []int{5, 100}, reported)
+}
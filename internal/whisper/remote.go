@@ -0,0 +1,312 @@
+package whisper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultRemoteModel is the model name OpenAI's transcription API expects.
+	DefaultRemoteModel   = "whisper-1"
+	DefaultRemoteTimeout = 2 * time.Minute
+	DefaultRemoteRetries = 2
+
+	defaultRemoteRetryDelay = time.Second
+	remotePreflightTimeout  = 5 * time.Second
+)
+
+// RemoteOptions configures a RemoteEngine.
+type RemoteOptions struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+	Timeout time.Duration
+	Retries int
+}
+
+// RemoteEngine uploads audio to an OpenAI-compatible
+// POST {BaseURL}/audio/transcriptions endpoint. Network errors, 429 and 5xx
+// responses are retried; each attempt is bounded by Timeout.
+type RemoteEngine struct {
+	BaseURL    string
+	APIKey     string
+	Model      string
+	Timeout    time.Duration
+	Retries    int
+	RetryDelay time.Duration
+	Client     *http.Client
+	Logger     *zap.Logger
+}
+
+func NewRemoteEngine(logger *zap.Logger, opts RemoteOptions) (*RemoteEngine, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	baseURL := strings.TrimRight(strings.TrimSpace(opts.BaseURL), "/")
+	if baseURL == "" {
+		return nil, errors.New("remote engine requires a base URL; set --remote-url, e.g. https://gpu-box.example/v1")
+	}
+	parsed, err := url.Parse(baseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid remote URL %q: expected http(s)://host[/path]", opts.BaseURL)
+	}
+	if opts.Timeout < 0 {
+		return nil, fmt.Errorf("remote timeout must not be negative, got %s", opts.Timeout)
+	}
+	if opts.Retries < 0 {
+		return nil, fmt.Errorf("remote retries must not be negative, got %d", opts.Retries)
+	}
+
+	model := strings.TrimSpace(opts.Model)
+	if model == "" {
+		model = DefaultRemoteModel
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultRemoteTimeout
+	}
+
+	return &RemoteEngine{
+		BaseURL:    baseURL,
+		APIKey:     strings.TrimSpace(opts.APIKey),
+		Model:      model,
+		Timeout:    timeout,
+		Retries:    opts.Retries,
+		RetryDelay: defaultRemoteRetryDelay,
+		Logger:     logger,
+	}, nil
+}
+
+func (r *RemoteEngine) Transcribe(ctx context.Context, req TranscriptionRequest) (Transcription, error) {
+	if strings.TrimSpace(req.AudioPath) == "" {
+		return Transcription{}, errors.New("audio path is required")
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= r.Retries; attempt++ {
+		if attempt > 0 {
+			delay := r.RetryDelay * time.Duration(attempt)
+			r.Logger.Warn("remote transcription failed; retrying", zap.Int("attempt", attempt+1), zap.Duration("delay", delay), zap.Error(lastErr))
+			select {
+			case <-ctx.Done():
+				return Transcription{}, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		result, err := r.transcribeOnce(ctx, req)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		if !isRetryableRemoteError(err) || ctx.Err() != nil {
+			return Transcription{}, err
+		}
+	}
+	return Transcription{}, fmt.Errorf("remote transcription failed after %d attempts: %w", r.Retries+1, lastErr)
+}
+
+// Preflight checks that the endpoint is reachable and accepts the API key by
+// listing models. Servers without a models endpoint still count as reachable.
+func (r *RemoteEngine) Preflight(ctx context.Context, _ string) error {
+	checkCtx, cancel := context.WithTimeout(ctx, remotePreflightTimeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(checkCtx, http.MethodGet, r.BaseURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("build remote request: %w", err)
+	}
+	if r.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+r.APIKey)
+	}
+
+	resp, err := r.client().Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("remote endpoint %s is not reachable: %w", r.BaseURL, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("remote endpoint %s rejected the API key: %s", r.BaseURL, resp.Status)
+	}
+	return nil
+}
+
+type remoteStatusError struct {
+	status  string
+	code    int
+	message string
+}
+
+func (e *remoteStatusError) Error() string {
+	return fmt.Sprintf("remote transcription failed: %s (%s)", e.status, e.message)
+}
+
+func (e *remoteStatusError) retryable() bool {
+	return e.code == http.StatusTooManyRequests || e.code >= http.StatusInternalServerError
+}
+
+// remoteTransportError marks failures to reach the endpoint at all, as
+// opposed to local problems such as an unreadable audio file.
+type remoteTransportError struct {
+	err error
+}
+
+func (e *remoteTransportError) Error() string {
+	return fmt.Sprintf("remote transcription request failed: %v", e.err)
+}
+
+func (e *remoteTransportError) Unwrap() error {
+	return e.err
+}
+
+func isRetryableRemoteError(err error) bool {
+	var status *remoteStatusError
+	if errors.As(err, &status) {
+		return status.retryable()
+	}
+	var transport *remoteTransportError
+	return errors.As(err, &transport)
+}
+
+type remoteResponse struct {
+	Text     string           `json:"text"`
+	Language string           `json:"language"`
+	Segments []verboseSegment `json:"segments"`
+	Error    json.RawMessage  `json:"error"`
+}
+
+func (r *RemoteEngine) transcribeOnce(ctx context.Context, req TranscriptionRequest) (Transcription, error) {
+	body, contentType, err := r.buildForm(req)
+	if err != nil {
+		return Transcription{}, err
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(attemptCtx, http.MethodPost, r.BaseURL+"/audio/transcriptions", body)
+	if err != nil {
+		return Transcription{}, fmt.Errorf("build remote request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set("Accept", "application/json")
+	if r.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+r.APIKey)
+	}
+
+	r.Logger.Debug("uploading audio to remote engine", zap.String("url", r.BaseURL), zap.String("model", r.Model), zap.String("audio", req.AudioPath))
+	resp, err := r.client().Do(httpReq)
+	if err != nil {
+		return Transcription{}, &remoteTransportError{err: err}
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Transcription{}, &remoteTransportError{err: fmt.Errorf("read response: %w", err)}
+	}
+
+	var decoded remoteResponse
+	decodeErr := json.Unmarshal(raw, &decoded)
+	if resp.StatusCode != http.StatusOK {
+		message := remoteErrorMessage(decoded.Error)
+		if message == "" {
+			message = strings.TrimSpace(string(raw))
+		}
+		return Transcription{}, &remoteStatusError{status: resp.Status, code: resp.StatusCode, message: message}
+	}
+	if decodeErr != nil {
+		return Transcription{}, fmt.Errorf("parse remote response: %w", decodeErr)
+	}
+
+	result := Transcription{Text: strings.TrimSpace(decoded.Text), Segments: convertVerboseSegments(decoded.Segments)}
+	if isAutoLanguage(req.Language) {
+		result.Language = languageCode(decoded.Language)
+	} else {
+		result.Language = strings.TrimSpace(req.Language)
+	}
+	return result, nil
+}
+
+func (r *RemoteEngine) buildForm(req TranscriptionRequest) (io.Reader, string, error) {
+	audio, err := os.Open(req.AudioPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("open audio: %w", err)
+	}
+	defer audio.Close()
+
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+
+	part, err := form.CreateFormFile("file", filepath.Base(req.AudioPath))
+	if err != nil {
+		return nil, "", fmt.Errorf("build remote form: %w", err)
+	}
+	if _, err := io.Copy(part, audio); err != nil {
+		return nil, "", fmt.Errorf("read audio: %w", err)
+	}
+
+	fields := [][2]string{
+		{"model", r.Model},
+		{"response_format", "verbose_json"},
+	}
+	if !isAutoLanguage(req.Language) {
+		fields = append(fields, [2]string{"language", strings.TrimSpace(req.Language)})
+	}
+	if req.Decoding.Temperature > 0 {
+		fields = append(fields, [2]string{"temperature", formatFloat(req.Decoding.Temperature)})
+	}
+	for _, field := range fields {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return nil, "", fmt.Errorf("build remote form: %w", err)
+		}
+	}
+
+	if err := form.Close(); err != nil {
+		return nil, "", fmt.Errorf("build remote form: %w", err)
+	}
+	return &buf, form.FormDataContentType(), nil
+}
+
+func (r *RemoteEngine) client() *http.Client {
+	if r.Client != nil {
+		return r.Client
+	}
+	return http.DefaultClient
+}
+
+// remoteErrorMessage accepts both the OpenAI error object
+// {"error": {"message": "..."}} and a plain {"error": "..."} string.
+func remoteErrorMessage(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var object struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil && object.Message != "" {
+		return strings.TrimSpace(object.Message)
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+	return ""
+}
@@ -0,0 +1,208 @@
+package whisper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestRemoteEngine(t *testing.T, handler http.HandlerFunc) *RemoteEngine {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	engine, err := NewRemoteEngine(zap.NewNop(), RemoteOptions{BaseURL: server.URL + "/v1/", APIKey: "secret", Retries: 2})
+	require.NoError(t, err)
+	engine.RetryDelay = time.Millisecond
+	return engine
+}
+
+func TestRemoteEngineUploadsAudio(t *testing.T) {
+	t.Parallel()
+
+	engine := newTestRemoteEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/v1/audio/transcriptions", r.URL.Path)
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		require.Equal(t, DefaultRemoteModel, r.FormValue("model"))
+		require.Equal(t, "verbose_json", r.FormValue("response_format"))
+		require.Equal(t, "de", r.FormValue("language"))
+		require.Equal(t, "0.2", r.FormValue("temperature"))
+		_, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		require.Equal(t, "audio.wav", header.Filename)
+
+		_, _ = w.Write([]byte(`{"text": " hallo welt ", "language": "german", "segments": [{"start": 0.0, "end": 1.24, "text": " hallo welt"}]}`))
+	})
+
+	result, err := engine.Transcribe(context.Background(), TranscriptionRequest{
+		AudioPath: writeTestAudio(t),
+		Language:  "de",
+		Decoding:  DecodingOptions{Temperature: 0.2},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "hallo welt", result.Text)
+	require.Equal(t, "de", result.Language)
+	require.Equal(t, []Segment{{Start: 0, End: 1240 * time.Millisecond, Text: "hallo welt"}}, result.Segments)
+}
+
+func TestRemoteEngineOmitsLanguageForAutoDetection(t *testing.T) {
+	t.Parallel()
+
+	engine := newTestRemoteEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		_, ok := r.MultipartForm.Value["language"]
+		require.False(t, ok)
+		_, _ = w.Write([]byte(`{"text": "bonjour", "language": "french"}`))
+	})
+
+	result, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: writeTestAudio(t), Language: "auto"})
+	require.NoError(t, err)
+	require.Equal(t, "fr", result.Language)
+}
+
+func TestRemoteEngineRetriesServerErrors(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	engine := newTestRemoteEngine(t, func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error": {"message": "GPU busy"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"text": "hello"}`))
+	})
+
+	result, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: writeTestAudio(t)})
+	require.NoError(t, err)
+	require.Equal(t, "hello", result.Text)
+	require.EqualValues(t, 3, calls.Load())
+}
+
+func TestRemoteEngineGivesUpAfterRetries(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	engine := newTestRemoteEngine(t, func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error": {"message": "rate limited"}}`))
+	})
+
+	_, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: writeTestAudio(t)})
+	require.ErrorContains(t, err, "after 3 attempts")
+	require.ErrorContains(t, err, "rate limited")
+	require.EqualValues(t, 3, calls.Load())
+}
+
+func TestRemoteEngineDoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	engine := newTestRemoteEngine(t, func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error": "invalid api key"}`))
+	})
+
+	_, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: writeTestAudio(t)})
+	require.ErrorContains(t, err, "401")
+	require.ErrorContains(t, err, "invalid api key")
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestRemoteEngineTimesOutEachAttempt(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	engine := newTestRemoteEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		_, _ = w.Write([]byte(`{"text": "late but fine"}`))
+	})
+	engine.Timeout = 50 * time.Millisecond
+
+	result, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: writeTestAudio(t)})
+	require.NoError(t, err)
+	require.Equal(t, "late but fine", result.Text)
+	require.EqualValues(t, 2, calls.Load())
+}
+
+func TestNewRemoteEngineValidatesOptions(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewRemoteEngine(nil, RemoteOptions{})
+	require.ErrorContains(t, err, "--remote-url")
+
+	_, err = NewRemoteEngine(nil, RemoteOptions{BaseURL: "gpu-box:8000"})
+	require.ErrorContains(t, err, "invalid remote URL")
+
+	_, err = NewRemoteEngine(nil, RemoteOptions{BaseURL: "http://gpu-box:8000/v1", Retries: -1})
+	require.ErrorContains(t, err, "retries")
+
+	engine, err := NewRemoteEngine(nil, RemoteOptions{BaseURL: "http://gpu-box:8000/v1/"})
+	require.NoError(t, err)
+	require.Equal(t, "http://gpu-box:8000/v1", engine.BaseURL)
+	require.Equal(t, DefaultRemoteModel, engine.Model)
+	require.Equal(t, DefaultRemoteTimeout, engine.Timeout)
+}
+
+func TestRemoteEnginePreflight(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		status      int
+		errContains string
+	}{
+		{name: "models listed", status: http.StatusOK},
+		{name: "no models endpoint", status: http.StatusNotFound},
+		{name: "key rejected", status: http.StatusUnauthorized, errContains: "rejected the API key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			engine := newTestRemoteEngine(t, func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/v1/models", r.URL.Path)
+				require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+				w.WriteHeader(tt.status)
+			})
+
+			err := engine.Preflight(context.Background(), "")
+			if tt.errContains == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.errContains)
+		})
+	}
+}
+
+func TestRemoteEnginePreflightUnreachable(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	baseURL := server.URL
+	server.Close()
+
+	engine, err := NewRemoteEngine(nil, RemoteOptions{BaseURL: baseURL})
+	require.NoError(t, err)
+	require.ErrorContains(t, engine.Preflight(context.Background(), ""), "not reachable")
+}
@@ -0,0 +1,79 @@
+package whisper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// cliJSONOutput is the subset of whisper-cli's -oj output voxclip reads.
+type cliJSONOutput struct {
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"`
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text string `json:"text"`
+	} `json:"transcription"`
+}
+
+// readCLISegments parses the segment timings whisper-cli writes with -oj.
+// Offsets are in milliseconds.
+func readCLISegments(path string) ([]Segment, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var output cliJSONOutput
+	if err := json.Unmarshal(raw, &output); err != nil {
+		return nil, fmt.Errorf("parse whisper json output: %w", err)
+	}
+
+	segments := make([]Segment, 0, len(output.Transcription))
+	for _, item := range output.Transcription {
+		text := strings.TrimSpace(item.Text)
+		if text == "" {
+			continue
+		}
+		segments = append(segments, Segment{
+			Start: time.Duration(item.Offsets.From) * time.Millisecond,
+			End:   time.Duration(item.Offsets.To) * time.Millisecond,
+			Text:  text,
+		})
+	}
+	return segments, nil
+}
+
+// verboseSegment is a segment of an OpenAI-style verbose_json response, as
+// returned by whisper-server and OpenAI-compatible APIs. Times are seconds.
+type verboseSegment struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+func convertVerboseSegments(items []verboseSegment) []Segment {
+	if len(items) == 0 {
+		return nil
+	}
+	segments := make([]Segment, 0, len(items))
+	for _, item := range items {
+		text := strings.TrimSpace(item.Text)
+		if text == "" {
+			continue
+		}
+		segments = append(segments, Segment{
+			Start: secondsToDuration(item.Start),
+			End:   secondsToDuration(item.End),
+			Text:  text,
+		})
+	}
+	return segments
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond)
+}
@@ -0,0 +1,575 @@
+package whisper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fmueller/voxclip/internal/platform"
+	"go.uber.org/zap"
+)
+
+// DefaultServerAddress is where voxclip runs its whisper-server by default.
+const DefaultServerAddress = "127.0.0.1:8178"
+
+const (
+	defaultServerStartTimeout = 2 * time.Minute
+	serverHealthTimeout       = time.Second
+	serverStopTimeout         = 3 * time.Second
+)
+
+// ServerEngine transcribes through a long-lived whisper.cpp whisper-server.
+// The server is started on demand, detached from voxclip so it outlives a
+// single run, and keeps the model loaded between transcriptions. It is
+// restarted when it crashed or when the model or startup options change.
+type ServerEngine struct {
+	Executable   string
+	Address      string
+	StateDir     string
+	StartTimeout time.Duration
+	Client       *http.Client
+	Logger       *zap.Logger
+
+	// startFn replaces launching the server process in tests.
+	startFn func(ctx context.Context, cfg serverConfig) (int, error)
+	// stopTimeout overrides serverStopTimeout in tests.
+	stopTimeout time.Duration
+}
+
+// serverConfig captures the startup options of a server process. A running
+// server is reused only when its recorded config matches the request.
+type serverConfig struct {
+	ModelPath string   `json:"model_path"`
+	Threads   int      `json:"threads,omitempty"`
+	ExtraArgs []string `json:"extra_args,omitempty"`
+}
+
+type serverState struct {
+	PID    int          `json:"pid"`
+	Config serverConfig `json:"config"`
+}
+
+func NewServerEngine(logger *zap.Logger, address, stateDir string) (*ServerEngine, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if strings.TrimSpace(address) == "" {
+		address = DefaultServerAddress
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, fmt.Errorf("invalid whisper-server address %q: %w", address, err)
+	}
+
+	executable, err := resolveServerExecutable()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ServerEngine{
+		Executable: executable,
+		Address:    address,
+		StateDir:   stateDir,
+		Logger:     logger,
+	}, nil
+}
+
+func resolveServerExecutable() (string, error) {
+	if override := strings.TrimSpace(os.Getenv("VOXCLIP_WHISPER_SERVER_PATH")); override != "" {
+		if err := ensureExecutable(override); err != nil {
+			return "", fmt.Errorf("VOXCLIP_WHISPER_SERVER_PATH is not executable: %w", err)
+		}
+		return override, nil
+	}
+
+	name := executableName("whisper-server")
+	if voxclipExe, err := currentExecutable(); err == nil {
+		for _, candidate := range bundledPathCandidates(voxclipExe, name) {
+			if err := ensureExecutable(candidate); err == nil {
+				return candidate, nil
+			}
+		}
+	}
+
+	if path, err := exec.LookPath(name); err == nil {
+		return path, nil
+	}
+
+	return "", fmt.Errorf("whisper-server not found next to voxclip or on PATH; install whisper.cpp's whisper-server or set VOXCLIP_WHISPER_SERVER_PATH")
+}
+
+func (s *ServerEngine) Transcribe(ctx context.Context, req TranscriptionRequest) (Transcription, error) {
+	if strings.TrimSpace(req.AudioPath) == "" {
+		return Transcription{}, errors.New("audio path is required")
+	}
+	if strings.TrimSpace(req.ModelPath) == "" {
+		return Transcription{}, errors.New("model path is required")
+	}
+
+	cfg := serverConfig{
+		ModelPath: req.ModelPath,
+		Threads:   req.Decoding.Threads,
+		ExtraArgs: req.Decoding.ExtraArgs,
+	}
+	if err := s.ensureRunning(ctx, cfg); err != nil {
+		return Transcription{}, err
+	}
+
+	result, err := s.postInference(ctx, req)
+	if err == nil || !isConnectionError(err) || ctx.Err() != nil {
+		return result, err
+	}
+
+	s.Logger.Warn("whisper-server connection failed; restarting", zap.String("address", s.Address), zap.Error(err))
+	if err := s.restart(ctx, cfg); err != nil {
+		return Transcription{}, err
+	}
+	return s.postInference(ctx, req)
+}
+
+// Preflight checks the model and the whisper-server binary. A server that is
+// already answering health checks is fine even if voxclip did not start it.
+func (s *ServerEngine) Preflight(ctx context.Context, modelPath string) error {
+	if err := checkGGMLModel(modelPath); err != nil {
+		return err
+	}
+	if s.healthy(ctx) {
+		return nil
+	}
+	if s.startFn == nil {
+		if err := ensureExecutable(s.Executable); err != nil {
+			return fmt.Errorf("whisper-server is not executable: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *ServerEngine) baseURL() string {
+	return "http://" + s.Address
+}
+
+func (s *ServerEngine) client() *http.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return http.DefaultClient
+}
+
+func (s *ServerEngine) ensureRunning(ctx context.Context, cfg serverConfig) error {
+	if !s.healthy(ctx) {
+		return s.restart(ctx, cfg)
+	}
+
+	state, err := s.readState()
+	if err != nil {
+		s.Logger.Warn("whisper-server at address was not started by voxclip; using it without checking its model", zap.String("address", s.Address))
+		return nil
+	}
+	if state.Config.matches(cfg) {
+		s.Logger.Debug("reusing running whisper-server", zap.String("address", s.Address), zap.Int("pid", state.PID))
+		return nil
+	}
+
+	s.Logger.Info("whisper-server settings changed; restarting", zap.String("model", cfg.ModelPath))
+	return s.restart(ctx, cfg)
+}
+
+// restart stops the server recorded in the state file, if it still runs, and
+// starts a new one. A hung server that fails its health checks would
+// otherwise keep the port and the model's memory.
+func (s *ServerEngine) restart(ctx context.Context, cfg serverConfig) error {
+	if state, err := s.readState(); err == nil && s.isServerProcess(state.PID) {
+		s.stop(ctx, state.PID)
+	}
+	_ = os.Remove(s.statePath())
+
+	start := s.startFn
+	if start == nil {
+		start = s.startProcess
+	}
+
+	s.Logger.Info("starting whisper-server", zap.String("address", s.Address), zap.String("model", cfg.ModelPath))
+	pid, err := start(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := s.writeState(serverState{PID: pid, Config: cfg}); err != nil {
+		s.Logger.Warn("failed to record whisper-server state; it will be restarted next run", zap.Error(err))
+	}
+	return nil
+}
+
+func (s *ServerEngine) startProcess(ctx context.Context, cfg serverConfig) (int, error) {
+	if err := ensureExecutable(s.Executable); err != nil {
+		return 0, fmt.Errorf("whisper-server missing or not executable: %w", err)
+	}
+
+	host, port, err := net.SplitHostPort(s.Address)
+	if err != nil {
+		return 0, fmt.Errorf("invalid whisper-server address %q: %w", s.Address, err)
+	}
+
+	if err := os.MkdirAll(s.StateDir, 0o755); err != nil {
+		return 0, fmt.Errorf("create whisper-server state directory: %w", err)
+	}
+	logFile, err := os.OpenFile(s.logPath(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("open whisper-server log: %w", err)
+	}
+	defer logFile.Close()
+
+	args := []string{"-m", cfg.ModelPath, "--host", host, "--port", port}
+	if cfg.Threads > 0 {
+		args = append(args, "-t", strconv.Itoa(cfg.Threads))
+	}
+	args = append(args, cfg.ExtraArgs...)
+
+	cmd := exec.Command(s.Executable, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	platform.DetachProcess(cmd)
+
+	s.Logger.Debug("running whisper-server", zap.String("engine", s.Executable), zap.Strings("args", args))
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("start whisper-server: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	timeout := s.StartTimeout
+	if timeout <= 0 {
+		timeout = defaultServerStartTimeout
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-exited:
+			return 0, fmt.Errorf("whisper-server exited during startup: %v (%s)", err, s.logTail())
+		case <-deadline.C:
+			_ = cmd.Process.Kill()
+			return 0, fmt.Errorf("whisper-server did not become ready within %s (%s)", timeout, s.logTail())
+		case <-ctx.Done():
+			_ = cmd.Process.Kill()
+			return 0, ctx.Err()
+		case <-ticker.C:
+			if s.healthy(ctx) {
+				return cmd.Process.Pid, nil
+			}
+		}
+	}
+}
+
+// stop asks a server voxclip started earlier to shut down and kills it when
+// it has not exited within the stop timeout.
+func (s *ServerEngine) stop(ctx context.Context, pid int) {
+	if !platform.ProcessRunning(pid) {
+		return
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return
+	}
+
+	timeout := s.stopTimeout
+	if timeout <= 0 {
+		timeout = serverStopTimeout
+	}
+	s.Logger.Debug("stopping whisper-server", zap.Int("pid", pid))
+	if err := process.Signal(os.Interrupt); err == nil && waitForExit(ctx, pid, timeout) {
+		return
+	}
+
+	s.Logger.Warn("whisper-server did not stop; killing it", zap.Int("pid", pid))
+	_ = process.Kill()
+	if !waitForExit(ctx, pid, timeout) {
+		s.Logger.Warn("whisper-server is still running after kill", zap.Int("pid", pid))
+	}
+}
+
+// isServerProcess reports whether pid still is a whisper-server voxclip
+// started on this address. After a reboot or a crash the PID in the state
+// file may belong to an unrelated process, which must not be stopped.
+func (s *ServerEngine) isServerProcess(pid int) bool {
+	if !platform.ProcessRunning(pid) {
+		return false
+	}
+	args, err := platform.ProcessArgs(pid)
+	if err != nil {
+		s.Logger.Debug("cannot check the recorded whisper-server process; leaving it alone", zap.Int("pid", pid), zap.Error(err))
+		return false
+	}
+	_, port, err := net.SplitHostPort(s.Address)
+	if err != nil {
+		return false
+	}
+
+	executable := filepath.Base(s.Executable)
+	var isExecutable, hasPort bool
+	for i, arg := range args {
+		if filepath.Base(arg) == executable {
+			isExecutable = true
+		}
+		if arg == "--port" && i+1 < len(args) && args[i+1] == port {
+			hasPort = true
+		}
+	}
+	if !isExecutable || !hasPort {
+		s.Logger.Debug("recorded whisper-server PID belongs to another process; leaving it alone", zap.Int("pid", pid), zap.Strings("args", args))
+		return false
+	}
+	return true
+}
+
+// waitForExit polls until the process with the given PID is gone. It reports
+// false when the timeout passes or ctx ends first.
+func waitForExit(ctx context.Context, pid int, timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for platform.ProcessRunning(pid) {
+		select {
+		case <-deadline.C:
+			return false
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+func (s *ServerEngine) healthy(ctx context.Context) bool {
+	healthCtx, cancel := context.WithTimeout(ctx, serverHealthTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(healthCtx, http.MethodGet, s.baseURL()+"/health", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode == http.StatusOK
+}
+
+type inferenceResponse struct {
+	Text                        string             `json:"text"`
+	Segments                    []verboseSegment   `json:"segments"`
+	DetectedLanguage            string             `json:"detected_language"`
+	DetectedLanguageProbability float64            `json:"detected_language_probability"`
+	LanguageProbabilities       map[string]float64 `json:"language_probabilities"`
+	Error                       string             `json:"error"`
+}
+
+func (s *ServerEngine) postInference(ctx context.Context, req TranscriptionRequest) (Transcription, error) {
+	body, contentType, err := buildInferenceForm(req)
+	if err != nil {
+		return Transcription{}, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL()+"/inference", body)
+	if err != nil {
+		return Transcription{}, fmt.Errorf("build whisper-server request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+
+	s.Logger.Debug("posting audio to whisper-server", zap.String("address", s.Address), zap.String("audio", req.AudioPath))
+	resp, err := s.client().Do(httpReq)
+	if err != nil {
+		return Transcription{}, fmt.Errorf("whisper-server request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Transcription{}, fmt.Errorf("read whisper-server response: %w", err)
+	}
+
+	var decoded inferenceResponse
+	decodeErr := json.Unmarshal(raw, &decoded)
+	if resp.StatusCode != http.StatusOK || decoded.Error != "" {
+		message := strings.TrimSpace(decoded.Error)
+		if message == "" {
+			message = strings.TrimSpace(string(raw))
+		}
+		return Transcription{}, fmt.Errorf("whisper-server transcribe failed: %s (%s)", resp.Status, message)
+	}
+	if decodeErr != nil {
+		return Transcription{}, fmt.Errorf("parse whisper-server response: %w", decodeErr)
+	}
+
+	result := Transcription{Text: strings.TrimSpace(decoded.Text), Segments: convertVerboseSegments(decoded.Segments)}
+	if isAutoLanguage(req.Language) {
+		result.Language = decoded.DetectedLanguage
+		result.LanguageProbability = decoded.DetectedLanguageProbability
+		if len(decoded.LanguageProbabilities) > 0 {
+			result.LanguageProbabilities = make(map[string]float64, len(decoded.LanguageProbabilities))
+			for lang, probability := range decoded.LanguageProbabilities {
+				result.LanguageProbabilities[languageCode(lang)] = probability
+			}
+		}
+	} else {
+		result.Language = strings.TrimSpace(req.Language)
+	}
+	return result, nil
+}
+
+func buildInferenceForm(req TranscriptionRequest) (io.Reader, string, error) {
+	audio, err := os.Open(req.AudioPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("open audio: %w", err)
+	}
+	defer audio.Close()
+
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+
+	part, err := form.CreateFormFile("file", filepath.Base(req.AudioPath))
+	if err != nil {
+		return nil, "", fmt.Errorf("build whisper-server form: %w", err)
+	}
+	if _, err := io.Copy(part, audio); err != nil {
+		return nil, "", fmt.Errorf("read audio: %w", err)
+	}
+
+	lang := strings.TrimSpace(req.Language)
+	if lang == "" {
+		lang = AutoLanguage
+	}
+	fields := [][2]string{
+		{"response_format", "verbose_json"},
+		{"language", lang},
+	}
+	fields = append(fields, req.Decoding.formFields()...)
+	for _, field := range fields {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return nil, "", fmt.Errorf("build whisper-server form: %w", err)
+		}
+	}
+
+	if err := form.Close(); err != nil {
+		return nil, "", fmt.Errorf("build whisper-server form: %w", err)
+	}
+	return &buf, form.FormDataContentType(), nil
+}
+
+// formFields renders the per-request decoding options as whisper-server
+// inference form fields. Threads and ExtraArgs are startup options instead.
+func (o DecodingOptions) formFields() [][2]string {
+	var fields [][2]string
+	if o.BeamSize > 0 {
+		fields = append(fields, [2]string{"beam_size", strconv.Itoa(o.BeamSize)})
+	}
+	if o.BestOf > 0 {
+		fields = append(fields, [2]string{"best_of", strconv.Itoa(o.BestOf)})
+	}
+	if o.Temperature > 0 {
+		fields = append(fields, [2]string{"temperature", formatFloat(o.Temperature)})
+	}
+	if o.NoFallback {
+		fields = append(fields, [2]string{"temperature_inc", "0"})
+	} else if o.TemperatureIncrement > 0 {
+		fields = append(fields, [2]string{"temperature_inc", formatFloat(o.TemperatureIncrement)})
+	}
+	if o.EntropyThreshold > 0 {
+		fields = append(fields, [2]string{"entropy_thold", formatFloat(o.EntropyThreshold)})
+	}
+	if o.LogprobThreshold < 0 {
+		fields = append(fields, [2]string{"logprob_thold", formatFloat(o.LogprobThreshold)})
+	}
+	if o.MaxSegmentLength > 0 {
+		fields = append(fields, [2]string{"max_len", strconv.Itoa(o.MaxSegmentLength)})
+	}
+	if o.SplitOnWord {
+		fields = append(fields, [2]string{"split_on_word", "true"})
+	}
+	return fields
+}
+
+func (c serverConfig) matches(other serverConfig) bool {
+	return c.ModelPath == other.ModelPath && c.Threads == other.Threads && slices.Equal(c.ExtraArgs, other.ExtraArgs)
+}
+
+func (s *ServerEngine) stateFileBase() string {
+	_, port, err := net.SplitHostPort(s.Address)
+	if err != nil || port == "" {
+		port = "default"
+	}
+	return filepath.Join(s.StateDir, "whisper-server-"+port)
+}
+
+func (s *ServerEngine) statePath() string {
+	return s.stateFileBase() + ".json"
+}
+
+func (s *ServerEngine) logPath() string {
+	return s.stateFileBase() + ".log"
+}
+
+func (s *ServerEngine) readState() (serverState, error) {
+	raw, err := os.ReadFile(s.statePath())
+	if err != nil {
+		return serverState{}, err
+	}
+	var state serverState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return serverState{}, fmt.Errorf("parse whisper-server state: %w", err)
+	}
+	return state, nil
+}
+
+func (s *ServerEngine) writeState(state serverState) error {
+	if err := os.MkdirAll(s.StateDir, 0o755); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.statePath(), raw, 0o644)
+}
+
+func (s *ServerEngine) logTail() string {
+	raw, err := os.ReadFile(s.logPath())
+	if err != nil {
+		return "no server log available"
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) > 5 {
+		lines = lines[len(lines)-5:]
+	}
+	return strings.Join(lines, "; ")
+}
+
+func isConnectionError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
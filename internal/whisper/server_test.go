@@ -0,0 +1,319 @@
+package whisper
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeWhisperServer struct {
+	*httptest.Server
+
+	healthy      atomic.Bool
+	dropRequests atomic.Int32
+
+	mu       sync.Mutex
+	forms    []map[string]string
+	response map[string]any
+	status   int
+}
+
+func newFakeWhisperServer(t *testing.T) *fakeWhisperServer {
+	t.Helper()
+
+	fake := &fakeWhisperServer{
+		response: map[string]any{"text": " hello world\n"},
+		status:   http.StatusOK,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
+		if !fake.healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"status":"loading model"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	})
+	mux.HandleFunc("/inference", func(w http.ResponseWriter, r *http.Request) {
+		if fake.dropRequests.Load() > 0 {
+			fake.dropRequests.Add(-1)
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			_ = conn.Close()
+			return
+		}
+
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		form := map[string]string{}
+		for key, values := range r.MultipartForm.Value {
+			form[key] = values[0]
+		}
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		_ = file.Close()
+		form["file"] = header.Filename
+
+		fake.mu.Lock()
+		fake.forms = append(fake.forms, form)
+		status, response := fake.status, fake.response
+		fake.mu.Unlock()
+
+		w.WriteHeader(status)
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	})
+	fake.Server = httptest.NewServer(mux)
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func (f *fakeWhisperServer) address() string {
+	return strings.TrimPrefix(f.URL, "http://")
+}
+
+func (f *fakeWhisperServer) lastForm() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.forms[len(f.forms)-1]
+}
+
+func newTestServerEngine(t *testing.T, fake *fakeWhisperServer, starts *int) *ServerEngine {
+	t.Helper()
+	return &ServerEngine{
+		Address:  fake.address(),
+		StateDir: t.TempDir(),
+		Logger:   zap.NewNop(),
+		startFn: func(_ context.Context, _ serverConfig) (int, error) {
+			*starts++
+			fake.healthy.Store(true)
+			return 0, nil
+		},
+	}
+}
+
+func writeTestAudio(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audio.wav")
+	require.NoError(t, os.WriteFile(path, []byte("RIFF"), 0o644))
+	return path
+}
+
+func TestServerEngineStartsServerOnDemand(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeWhisperServer(t)
+	starts := 0
+	engine := newTestServerEngine(t, fake, &starts)
+
+	result, err := engine.Transcribe(context.Background(), TranscriptionRequest{
+		AudioPath: writeTestAudio(t),
+		ModelPath: "/models/ggml-small.bin",
+		Language:  "en",
+		Decoding:  DecodingOptions{Threads: 4, BeamSize: 5, NoFallback: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "hello world", result.Text)
+	require.Equal(t, "en", result.Language)
+	require.Equal(t, 1, starts)
+
+	form := fake.lastForm()
+	require.Equal(t, "audio.wav", form["file"])
+	require.Equal(t, "en", form["language"])
+	require.Equal(t, "verbose_json", form["response_format"])
+	require.Equal(t, "5", form["beam_size"])
+	require.Equal(t, "0", form["temperature_inc"])
+
+	state, err := engine.readState()
+	require.NoError(t, err)
+	require.Equal(t, serverConfig{ModelPath: "/models/ggml-small.bin", Threads: 4}, state.Config)
+}
+
+func TestServerEngineReusesMatchingServer(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeWhisperServer(t)
+	fake.healthy.Store(true)
+	starts := 0
+	engine := newTestServerEngine(t, fake, &starts)
+	require.NoError(t, engine.writeState(serverState{Config: serverConfig{ModelPath: "/models/ggml-tiny.bin"}}))
+
+	_, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: writeTestAudio(t), ModelPath: "/models/ggml-tiny.bin"})
+	require.NoError(t, err)
+	require.Zero(t, starts)
+}
+
+func TestServerEngineRestartsWhenModelChanges(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeWhisperServer(t)
+	fake.healthy.Store(true)
+	starts := 0
+	engine := newTestServerEngine(t, fake, &starts)
+	require.NoError(t, engine.writeState(serverState{Config: serverConfig{ModelPath: "/models/ggml-tiny.bin"}}))
+
+	_, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: writeTestAudio(t), ModelPath: "/models/ggml-small.bin"})
+	require.NoError(t, err)
+	require.Equal(t, 1, starts)
+
+	state, err := engine.readState()
+	require.NoError(t, err)
+	require.Equal(t, "/models/ggml-small.bin", state.Config.ModelPath)
+}
+
+func TestServerEngineRestartsAfterCrash(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeWhisperServer(t)
+	fake.healthy.Store(true)
+	fake.dropRequests.Store(1)
+	starts := 0
+	engine := newTestServerEngine(t, fake, &starts)
+	require.NoError(t, engine.writeState(serverState{Config: serverConfig{ModelPath: "/models/ggml-tiny.bin"}}))
+
+	result, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: writeTestAudio(t), ModelPath: "/models/ggml-tiny.bin"})
+	require.NoError(t, err)
+	require.Equal(t, "hello world", result.Text)
+	require.Equal(t, 1, starts)
+}
+
+// startHungProcess runs a script named name that ignores the interrupt, like
+// a hung whisper-server. The returned channel is closed once it exited.
+func startHungProcess(t *testing.T, name string, args ...string) (*exec.Cmd, <-chan struct{}) {
+	t.Helper()
+
+	script := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\ntrap '' INT\nwhile :; do sleep 1; done\n"), 0o755))
+	cmd := exec.Command(script, args...)
+	require.NoError(t, cmd.Start())
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+	t.Cleanup(func() { _ = cmd.Process.Kill() })
+	return cmd, exited
+}
+
+func TestServerEngineKillsUnhealthyServerBeforeRestart(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeWhisperServer(t)
+	starts := 0
+	engine := newTestServerEngine(t, fake, &starts)
+	engine.stopTimeout = 300 * time.Millisecond
+	_, port, err := net.SplitHostPort(engine.Address)
+	require.NoError(t, err)
+
+	// A hung server: it ignores the interrupt and fails every health check.
+	hung, exited := startHungProcess(t, "whisper-server", "-m", "/models/ggml-tiny.bin", "--host", "127.0.0.1", "--port", port)
+	engine.Executable = hung.Path
+	startFn := engine.startFn
+	engine.startFn = func(ctx context.Context, cfg serverConfig) (int, error) {
+		select {
+		case <-exited:
+		default:
+			t.Error("new server started while the stale one is still running")
+		}
+		return startFn(ctx, cfg)
+	}
+	require.NoError(t, engine.writeState(serverState{PID: hung.Process.Pid, Config: serverConfig{ModelPath: "/models/ggml-tiny.bin"}}))
+
+	result, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: writeTestAudio(t), ModelPath: "/models/ggml-tiny.bin"})
+	require.NoError(t, err)
+	require.Equal(t, "hello world", result.Text)
+	require.Equal(t, 1, starts)
+}
+
+func TestServerEngineLeavesUnrelatedProcessAlone(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeWhisperServer(t)
+	starts := 0
+	engine := newTestServerEngine(t, fake, &starts)
+	engine.Executable = "/usr/local/bin/whisper-server"
+	engine.stopTimeout = 300 * time.Millisecond
+	_, port, err := net.SplitHostPort(engine.Address)
+	require.NoError(t, err)
+
+	// The recorded PID was reused by another program after a reboot.
+	other, exited := startHungProcess(t, "editor", "--port", port)
+	require.NoError(t, engine.writeState(serverState{PID: other.Process.Pid, Config: serverConfig{ModelPath: "/models/ggml-tiny.bin"}}))
+
+	result, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: writeTestAudio(t), ModelPath: "/models/ggml-tiny.bin"})
+	require.NoError(t, err)
+	require.Equal(t, "hello world", result.Text)
+	require.Equal(t, 1, starts)
+
+	select {
+	case <-exited:
+		t.Fatal("an unrelated process was stopped")
+	case <-time.After(100 * time.Millisecond):
+	}
+	require.NoError(t, other.Process.Signal(syscall.Signal(0)))
+}
+
+func TestServerEngineReportsDetectedLanguage(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeWhisperServer(t)
+	fake.response = map[string]any{
+		"text":                          "hallo",
+		"language":                      "german",
+		"detected_language":             "de",
+		"detected_language_probability": 0.87,
+		"language_probabilities":        map[string]any{"de": 0.87, "nl": 0.1},
+	}
+	starts := 0
+	engine := newTestServerEngine(t, fake, &starts)
+
+	result, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: writeTestAudio(t), ModelPath: "/models/ggml-tiny.bin", Language: "auto"})
+	require.NoError(t, err)
+	require.Equal(t, "de", result.Language)
+	require.InDelta(t, 0.87, result.LanguageProbability, 1e-9)
+	require.Equal(t, map[string]float64{"de": 0.87, "nl": 0.1}, result.LanguageProbabilities)
+	require.Equal(t, "auto", fake.lastForm()["language"])
+}
+
+func TestServerEngineReturnsServerError(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeWhisperServer(t)
+	fake.status = http.StatusInternalServerError
+	fake.response = map[string]any{"error": "failed to read audio data"}
+	starts := 0
+	engine := newTestServerEngine(t, fake, &starts)
+
+	_, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: writeTestAudio(t), ModelPath: "/models/ggml-tiny.bin"})
+	require.ErrorContains(t, err, "failed to read audio data")
+}
+
+func TestServerEngineStartProcessReportsEarlyExit(t *testing.T) {
+	t.Parallel()
+
+	stub := filepath.Join(t.TempDir(), "whisper-server")
+	require.NoError(t, os.WriteFile(stub, []byte("#!/bin/sh\necho 'error: failed to load model' >&2\nexit 1\n"), 0o755))
+
+	engine := &ServerEngine{
+		Executable: stub,
+		Address:    "127.0.0.1:1",
+		StateDir:   t.TempDir(),
+		Logger:     zap.NewNop(),
+	}
+
+	_, err := engine.Transcribe(context.Background(), TranscriptionRequest{AudioPath: writeTestAudio(t), ModelPath: "/models/missing.bin"})
+	require.ErrorContains(t, err, "exited during startup")
+	require.ErrorContains(t, err, "failed to load model")
+}